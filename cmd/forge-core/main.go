@@ -14,6 +14,7 @@ import (
 
 func main() {
 	plugins := []pluginv1.PluginModule{
+		module.AsyncStatus,
 		module.Command,
 		module.Dnf,
 		module.DnfInfo,
@@ -0,0 +1,284 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/trippsoft/forge/internal/cli"
+	"github.com/trippsoft/forge/pkg/info"
+	"github.com/trippsoft/forge/pkg/inventory"
+)
+
+var (
+	infoTTL      time.Duration
+	infoCacheDir string
+	refreshInfo  bool
+)
+
+// infoTTLEnvVar is the environment variable that overrides the default TTL of the host info cache.
+const infoTTLEnvVar = "FORGE_INFO_TTL"
+
+// newInfoCmd creates the info command, which inspects, warms and clears the host info cache.
+func newInfoCmd() *cobra.Command {
+	infoCmd := &cobra.Command{
+		Use:   "info",
+		Short: "Manage the host info cache",
+		Long: "Inspects, warms and clears the cache of discovered host info, which lets runs skip discovery for " +
+			"hosts whose info was discovered within the TTL.",
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the cached host info of the inventory's hosts",
+		Long:  "Shows when the info of each host in the inventory was cached and whether it has expired.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.InitUI(debug)
+			cache, hosts, err := infoCacheHosts()
+			if err != nil {
+				os.Exit(1)
+			}
+
+			for _, host := range hosts {
+				entry, err := cache.Entry(host.Name(), host.Transport())
+				switch {
+				case err != nil:
+					cli.UI.PrintError(fmt.Sprintf("%s: %s\n", host.Name(), err.Error()))
+				case entry == nil:
+					cli.UI.Print(fmt.Sprintf("%s: not cached\n", host.Name()))
+				default:
+					status := "valid"
+					if entry.Expired {
+						status = "expired"
+					}
+
+					cli.UI.Print(fmt.Sprintf(
+						"%s: %s (%s), cached at %s\n",
+						host.Name(),
+						entry.HostInfo.Os.FriendlyName,
+						status,
+						entry.DiscoveredAt.Format(time.RFC3339),
+					))
+				}
+			}
+		},
+	}
+
+	warmCmd := &cobra.Command{
+		Use:   "warm",
+		Short: "Discover and cache the host info of the inventory's hosts",
+		Long:  "Discovers the info of each host in the inventory and stores it in the cache.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.InitUI(debug)
+			cache, hosts, err := infoCacheHosts()
+			if err != nil {
+				os.Exit(1)
+			}
+
+			if !refreshInfo {
+				hosts = slices.DeleteFunc(hosts, func(host *inventory.Host) bool {
+					_, ok := cache.Load(host.Name(), host.Transport())
+					return ok
+				})
+			}
+
+			err = warmInfoCache(cache.WithRefresh(true), hosts)
+			if err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Clear the host info cache",
+		Long: "Removes the cached info of the hosts in the inventory, or of every host if no inventory is " +
+			"specified.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cli.InitUI(debug)
+			if len(inventoryPaths) == 0 {
+				cache, err := newInfoCache()
+				if err == nil {
+					err = cache.Clear()
+				}
+
+				if err != nil {
+					cli.UI.PrintError(fmt.Sprintf("Error clearing host info cache: %s\n", err.Error()))
+					os.Exit(1)
+				}
+
+				cli.UI.Print(fmt.Sprintf("Cleared host info cache %s\n", cache.Path()))
+				return
+			}
+
+			cache, hosts, err := infoCacheHosts()
+			if err != nil {
+				os.Exit(1)
+			}
+
+			for _, host := range hosts {
+				e := cache.Remove(host.Name(), host.Transport())
+				if e != nil {
+					cli.UI.PrintError(fmt.Sprintf("%s\n", e.Error()))
+					err = errors.Join(err, e)
+					continue
+				}
+
+				cli.UI.Print(fmt.Sprintf("%s: cleared\n", host.Name()))
+			}
+
+			if err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	infoCmd.AddCommand(showCmd)
+	infoCmd.AddCommand(warmCmd)
+	infoCmd.AddCommand(clearCmd)
+
+	infoCmd.PersistentFlags().StringSliceVarP(
+		&inventoryPaths,
+		"inventory",
+		"i",
+		[]string{},
+		"Path to the HCL inventory file(s)",
+	)
+	infoCmd.PersistentFlags().StringVarP(&limit, "limit", "l", "", "Limit the hosts to those matching a pattern")
+	infoCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable debug mode")
+	addInfoCacheFlags(infoCmd)
+
+	warmCmd.Flags().BoolVar(&refreshInfo, "refresh-info", false, "Discover the info of hosts that are already cached")
+	warmCmd.Flags().IntVarP(
+		&forks,
+		"forks",
+		"f",
+		defaultForks(),
+		fmt.Sprintf("Maximum number of hosts to discover concurrently, 0 for no limit (env: %s)", forksEnvVar),
+	)
+
+	return infoCmd
+}
+
+// addInfoCacheFlags adds the flags that configure the host info cache to the command.
+func addInfoCacheFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().DurationVar(
+		&infoTTL,
+		"info-ttl",
+		defaultInfoTTL(),
+		fmt.Sprintf("Duration for which cached host info is used, 0 to always discover it (env: %s)", infoTTLEnvVar),
+	)
+	cmd.PersistentFlags().StringVar(
+		&infoCacheDir,
+		"info-cache-dir",
+		"",
+		"Directory of the host info cache (default: forge/info in the user cache directory)",
+	)
+}
+
+// defaultInfoTTL returns the default TTL of the host info cache, which can be overridden with the FORGE_INFO_TTL
+// environment variable.
+func defaultInfoTTL() time.Duration {
+	value, exists := os.LookupEnv(infoTTLEnvVar)
+	if !exists {
+		return info.DefaultCacheTTL
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return info.DefaultCacheTTL
+	}
+
+	return ttl
+}
+
+// newInfoCache creates the host info cache configured by the flags.
+func newInfoCache() (*info.Cache, error) {
+	path := infoCacheDir
+	if path == "" {
+		var err error
+		path, err = info.DefaultCachePath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return info.NewCache(path, infoTTL).WithRefresh(refreshInfo), nil
+}
+
+// infoCacheHosts returns the host info cache and the hosts of the inventory, narrowed by the limit and sorted by name.
+func infoCacheHosts() (*info.Cache, []*inventory.Host, error) {
+	cache, err := newInfoCache()
+	if err != nil {
+		cli.UI.PrintError(fmt.Sprintf("Error locating host info cache: %s\n", err.Error()))
+		return nil, nil, err
+	}
+
+	i, err := parseInventory()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var hosts []*inventory.Host
+	if limit == "" {
+		for _, host := range i.Hosts() {
+			hosts = append(hosts, host)
+		}
+	} else {
+		hosts, err = i.ResolvePattern(limit)
+		if err != nil {
+			cli.UI.PrintError(fmt.Sprintf("Error resolving limit: %s\n", err.Error()))
+			return nil, nil, err
+		}
+	}
+
+	slices.SortFunc(hosts, func(a, b *inventory.Host) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return cache, hosts, nil
+}
+
+// warmInfoCache discovers the info of the hosts and stores it in the cache.
+func warmInfoCache(cache *info.Cache, hosts []*inventory.Host) error {
+	var slots chan struct{}
+	if forks > 0 {
+		slots = make(chan struct{}, forks)
+	}
+
+	var mutex sync.Mutex
+	var err error
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		wg.Go(func() {
+			if slots != nil {
+				slots <- struct{}{}
+				defer func() { <-slots }()
+			}
+
+			r := cache.Populate(context.Background(), host.Name(), host.Info(), host.Transport())
+			host.Transport().Close()
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			cli.UI.PrintHostResult(host.Name(), r)
+			if r.Error != nil {
+				err = errors.Join(err, r.Error)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	return err
+}
@@ -0,0 +1,137 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/trippsoft/forge/pkg/hclutil"
+	"github.com/trippsoft/forge/pkg/inventory"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+var (
+	inventoryFormat string
+	inventoryHost   string
+	inventoryGraph  string
+	listTargets     bool
+)
+
+const (
+	inventoryFormatJSON = "json"
+	inventoryFormatYAML = "yaml"
+	inventoryFormatHCL  = "hcl"
+
+	inventoryGraphTree = "tree"
+	inventoryGraphDOT  = "dot"
+)
+
+// isStructuredInventoryOutput indicates whether the inventory command writes structured output instead of printing
+// the targets and vars of the inventory.
+func isStructuredInventoryOutput() bool {
+	return inventoryFormat != "" || inventoryHost != "" || inventoryGraph != "" || listTargets
+}
+
+// validateInventoryOutputFlags validates the values of the flags that select the output of the inventory command.
+func validateInventoryOutputFlags() error {
+	switch inventoryFormat {
+	case "", inventoryFormatJSON, inventoryFormatYAML, inventoryFormatHCL:
+	default:
+		return fmt.Errorf(
+			"invalid format %q, must be one of %q, %q or %q",
+			inventoryFormat,
+			inventoryFormatJSON,
+			inventoryFormatYAML,
+			inventoryFormatHCL,
+		)
+	}
+
+	switch inventoryGraph {
+	case "", inventoryGraphTree, inventoryGraphDOT:
+	default:
+		return fmt.Errorf(
+			"invalid graph %q, must be one of %q or %q",
+			inventoryGraph,
+			inventoryGraphTree,
+			inventoryGraphDOT,
+		)
+	}
+
+	return nil
+}
+
+// formatInventory returns the structured output of the inventory command selected by its flags.
+func formatInventory(i *inventory.Inventory) (string, error) {
+	switch inventoryGraph {
+	case inventoryGraphTree:
+		return i.Tree(), nil
+	case inventoryGraphDOT:
+		return i.DOT(), nil
+	}
+
+	if listTargets {
+		names := i.TargetNames()
+		if inventoryFormat == "" {
+			return strings.Join(names, "\n") + "\n", nil
+		}
+
+		if len(names) == 0 {
+			return formatInventoryValue(cty.ListValEmpty(cty.String))
+		}
+
+		values := make([]cty.Value, 0, len(names))
+		for _, name := range names {
+			values = append(values, cty.StringVal(name))
+		}
+
+		return formatInventoryValue(cty.ListVal(values))
+	}
+
+	if inventoryHost != "" {
+		value, exists := i.HostValue(inventoryHost)
+		if !exists {
+			return "", fmt.Errorf("the host %q does not exist in the inventory", inventoryHost)
+		}
+
+		return formatInventoryValue(value)
+	}
+
+	return formatInventoryValue(i.Value())
+}
+
+// formatInventoryValue formats a value in the format selected by --format, which defaults to HCL.
+func formatInventoryValue(value cty.Value) (string, error) {
+	switch inventoryFormat {
+	case inventoryFormatJSON:
+		content, err := ctyjson.Marshal(value, value.Type())
+		if err != nil {
+			return "", fmt.Errorf("failed to format inventory as JSON: %w", err)
+		}
+
+		indented := &bytes.Buffer{}
+		err = json.Indent(indented, content, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to format inventory as JSON: %w", err)
+		}
+
+		indented.WriteRune('\n')
+		return indented.String(), nil
+
+	case inventoryFormatYAML:
+		content, err := ctyyaml.Standard.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to format inventory as YAML: %w", err)
+		}
+
+		return string(content), nil
+
+	default:
+		return hclutil.FormatCtyValueToIndentedString(value, 0, 4) + "\n", nil
+	}
+}
@@ -5,38 +5,86 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/spf13/cobra"
 	"github.com/trippsoft/forge/internal/cli"
 	"github.com/trippsoft/forge/internal/version"
 	"github.com/trippsoft/forge/pkg/inventory"
 	"github.com/trippsoft/forge/pkg/module"
+	"github.com/trippsoft/forge/pkg/secret"
+	"github.com/trippsoft/forge/pkg/ui"
 	"github.com/trippsoft/forge/pkg/workflow"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
 var (
 	inventoryPaths []string
 	workflowPath   string
 	debug          bool
+	forks          int
+	varValues      []string
+	varFiles       []string
+	tags           []string
+	skipTags       []string
+	listTags       bool
+	limit          string
+	timeout        time.Duration
+	outputsJSON    string
 )
 
+// forksEnvVar is the environment variable that overrides the default number of forks.
+const forksEnvVar = "FORGE_FORKS"
+
 func main() {
 	inventoryCmd := &cobra.Command{
 		Use:   "inventory",
 		Short: "Parse and display HCL inventory",
-		Long:  "Parses HCL inventory files and displays the inventory of managed hosts.",
+		Long: "Parses HCL inventory files and displays the inventory of managed hosts. With --format, --host, " +
+			"--graph or --list-targets, only the selected output is written to stdout.",
 		Run: func(cmd *cobra.Command, args []string) {
-			cli.InitUI(debug)
+			if !isStructuredInventoryOutput() {
+				cli.InitUI(debug)
+				i, err := parseInventory()
+				if err != nil {
+					os.Exit(1)
+				}
+
+				cli.UI.PrintInventoryTargets(i)
+				cli.UI.PrintInventoryVars(i)
+				return
+			}
+
+			cli.InitStructuredUI(debug)
+			err := validateInventoryOutputFlags()
+			if err != nil {
+				cli.UI.PrintError(fmt.Sprintf("Error: %s\n", err.Error()))
+				os.Exit(1)
+			}
+
 			i, err := parseInventory()
 			if err != nil {
 				os.Exit(1)
 			}
 
-			cli.UI.PrintInventoryTargets(i)
-			cli.UI.PrintInventoryVars(i)
+			output, err := formatInventory(i)
+			if err != nil {
+				cli.UI.PrintError(fmt.Sprintf("Error: %s\n", err.Error()))
+				os.Exit(1)
+			}
+
+			fmt.Fprint(os.Stdout, secret.SecretFilter.Filter(output))
 		},
 	}
 	runCmd := &cobra.Command{
@@ -60,15 +108,63 @@ func main() {
 				os.Exit(1)
 			}
 
+			if listTags {
+				printWorkflowTags(w)
+				return
+			}
+
 			workflowContext, err := workflow.NewWorkflowContext(cli.UI, i, debug)
 			if err != nil {
 				cli.UI.PrintError(fmt.Sprintf("Error creating workflow context: %s\n", err.Error()))
 				os.Exit(1)
 			}
 
-			_, err = w.Run(workflowContext)
+			variables, diags := w.ResolveVariables(os.Environ(), varFiles, varValues)
+			cli.UI.PrintHCLDiagnostics(diags)
+			if diags.HasErrors() {
+				cli.UI.PrintError("Error resolving workflow variables. Closing...\n")
+				os.Exit(1)
+			}
+
+			runCtx, stopCtx, release := runContexts()
+			defer release()
 
+			infoCache, err := newInfoCache()
 			if err != nil {
+				cli.UI.PrintError(fmt.Sprintf("Error locating host info cache, discovering all hosts: %s\n", err.Error()))
+			}
+
+			workflowContext.
+				WithForks(forks).
+				WithInfoCache(infoCache).
+				WithVariables(variables).
+				WithContext(runCtx).
+				WithStopContext(stopCtx)
+
+			stepOutputs, err := w.Run(workflowContext)
+
+			if errors.Is(err, workflow.ErrStopped) {
+				cli.UI.PrintError(fmt.Sprintf("Workflow stopped: %s\n", context.Cause(stopCtx).Error()))
+			}
+
+			outputs, outputDiags := w.EvaluateOutputs(workflowContext, stepOutputs)
+			cli.UI.PrintHCLDiagnostics(outputDiags)
+			if outputDiags.HasErrors() {
+				cli.UI.PrintError("Error evaluating workflow outputs.\n")
+			}
+
+			printOutputs(w, outputs)
+
+			if outputsJSON != "" {
+				e := writeRunResult(w, stepOutputs, outputs, outputDiags, err)
+				if e != nil {
+					cli.UI.PrintError(fmt.Sprintf("Error writing run result: %s\n", e.Error()))
+					err = errors.Join(err, e)
+				}
+			}
+
+			if err != nil || outputDiags.HasErrors() {
+				release()
 				os.Exit(1)
 			}
 		},
@@ -90,14 +186,49 @@ func main() {
 
 	rootCmd.AddCommand(inventoryCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(newInfoCmd())
 	rootCmd.AddCommand(versionCmd)
 
 	inventoryCmd.Flags().StringSliceVarP(&inventoryPaths, "inventory", "i", []string{}, "Path to the HCL inventory file(s)")
 	inventoryCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug mode")
+	inventoryCmd.Flags().StringVar(&inventoryFormat, "format", "", "Write the inventory as json, yaml or hcl")
+	inventoryCmd.Flags().StringVar(&inventoryHost, "host", "", "Only write the resolved details of this host")
+	inventoryCmd.Flags().StringVar(&inventoryGraph, "graph", "", "Write the group hierarchy as a tree or as dot")
+	inventoryCmd.Flags().Lookup("graph").NoOptDefVal = inventoryGraphTree
+	inventoryCmd.Flags().BoolVar(&listTargets, "list-targets", false, "Only write the names of the inventory targets")
+	inventoryCmd.MarkFlagsMutuallyExclusive("host", "graph", "list-targets")
+	inventoryCmd.MarkFlagsMutuallyExclusive("format", "graph")
 
 	runCmd.Flags().StringSliceVarP(&inventoryPaths, "inventory", "i", []string{}, "Path to the HCL inventory file(s)")
 	runCmd.Flags().StringVarP(&workflowPath, "workflow", "w", "", "Path to the HCL workflow file")
 	runCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug mode")
+	runCmd.Flags().IntVarP(
+		&forks,
+		"forks",
+		"f",
+		defaultForks(),
+		fmt.Sprintf("Maximum number of hosts to run concurrently, 0 for no limit (env: %s)", forksEnvVar),
+	)
+	runCmd.Flags().StringArrayVar(
+		&varValues,
+		"var",
+		[]string{},
+		fmt.Sprintf("Set a workflow variable in the form name=value (env: %s<name>)", workflow.VariableEnvPrefix),
+	)
+	runCmd.Flags().StringArrayVar(&varFiles, "var-file", []string{}, "Path to an HCL or JSON file of workflow variables")
+	runCmd.Flags().StringSliceVar(&tags, "tags", []string{}, "Only run steps with any of these tags")
+	runCmd.Flags().StringSliceVar(&skipTags, "skip-tags", []string{}, "Skip steps with any of these tags")
+	runCmd.Flags().StringVarP(&limit, "limit", "l", "", "Limit the targets of every process to hosts matching a pattern")
+	runCmd.Flags().BoolVar(&listTags, "list-tags", false, "List the tags of the steps that would run and exit")
+	runCmd.Flags().DurationVar(&timeout, "timeout", 0, "Maximum duration of the whole run, 0 for no limit")
+	runCmd.Flags().BoolVar(&refreshInfo, "refresh-info", false, "Discover host info even if it is cached")
+	runCmd.Flags().StringVar(
+		&outputsJSON,
+		"outputs-json",
+		"",
+		"Path to a JSON file to write the workflow outputs and the status of each step on each host to",
+	)
+	addInfoCacheFlags(runCmd)
 
 	err := rootCmd.Execute()
 	if err != nil {
@@ -105,6 +236,78 @@ func main() {
 	}
 }
 
+// defaultForks returns the default number of forks, which can be overridden with the FORGE_FORKS environment variable.
+func defaultForks() int {
+	value, exists := os.LookupEnv(forksEnvVar)
+	if !exists {
+		return workflow.DefaultForks
+	}
+
+	forks, err := strconv.Atoi(value)
+	if err != nil {
+		return workflow.DefaultForks
+	}
+
+	return forks
+}
+
+// runContexts returns the context of the workflow run, the context that stops it gracefully and a function that
+// releases their resources.
+//
+// The first SIGINT or SIGTERM stops the workflow gracefully, letting running modules finish. A second signal cancels
+// the run context, which cancels running modules and closes their plugin sessions. If a timeout is set, the run
+// context is cancelled when it elapses.
+func runContexts() (context.Context, context.Context, func()) {
+	runCtx, cancel := context.WithCancelCause(context.Background())
+	cancelTimeout := context.CancelFunc(func() {})
+	if timeout > 0 {
+		runCtx, cancelTimeout = context.WithTimeoutCause(
+			runCtx,
+			timeout,
+			fmt.Errorf("the run exceeded the timeout of %s", timeout),
+		)
+	}
+
+	stopCtx, stop := context.WithCancelCause(runCtx)
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			cli.UI.PrintError(fmt.Sprintf(
+				"\nReceived %s, waiting for running modules to finish. Send it again to cancel them.\n",
+				sig,
+			))
+			stop(fmt.Errorf("received %s", sig))
+		case <-done:
+			return
+		}
+
+		select {
+		case sig := <-signals:
+			cli.UI.PrintError(fmt.Sprintf("\nReceived %s again, cancelling running modules.\n", sig))
+			cancel(fmt.Errorf("received %s twice", sig))
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			stop(nil)
+			cancelTimeout()
+			cancel(nil)
+		})
+	}
+
+	return runCtx, stopCtx, release
+}
+
 func parseInventory() (*inventory.Inventory, error) {
 	cli.UI.Print("\nDiscovering inventory files...\n\n")
 
@@ -151,7 +354,22 @@ func parseWorkflow(inventory *inventory.Inventory, moduleRegistry *module.Regist
 		return nil, err
 	}
 
-	parser := workflow.NewParser(inventory, moduleRegistry)
+	parser := workflow.NewParser(inventory, moduleRegistry).WithTagFilter(workflow.NewTagFilter(tags, skipTags))
+	if limit != "" {
+		hosts, err := inventory.ResolvePattern(limit)
+		if err != nil {
+			cli.UI.PrintError(fmt.Sprintf("Error resolving limit: %s\n", err.Error()))
+			return nil, err
+		}
+
+		if len(hosts) == 0 {
+			cli.UI.PrintError(fmt.Sprintf("No hosts matched the limit %q. Closing...\n", limit))
+			return nil, fmt.Errorf("no hosts matched the limit %q", limit)
+		}
+
+		parser.WithLimit(hosts)
+	}
+
 	w, diags := parser.ParseWorkflowFile(workflowPath, content)
 
 	cli.UI.PrintHCLDiagnostics(diags)
@@ -163,3 +381,51 @@ func parseWorkflow(inventory *inventory.Inventory, moduleRegistry *module.Regist
 	cli.UI.Print("Successfully parsed workflow file.\n")
 	return w, nil
 }
+
+// printWorkflowTags prints the tags of the steps that would run in each process of the workflow.
+func printWorkflowTags(w *workflow.Workflow) {
+	for _, process := range w.Processes() {
+		cli.UI.Print(fmt.Sprintf("\nPROCESS - %s\n", process.Name()))
+		cli.UI.Print(fmt.Sprintf("  TAGS: [%s]\n", strings.Join(process.Tags(), ", ")))
+	}
+}
+
+// printOutputs prints the values of the workflow outputs, hiding the values of sensitive outputs.
+func printOutputs(w *workflow.Workflow, outputs map[string]cty.Value) {
+	if len(outputs) == 0 {
+		return
+	}
+
+	cli.UI.PrintHeader(ui.HeaderLevel1, "", "OUTPUTS")
+	for _, output := range w.Outputs() {
+		value, exists := outputs[output.Name()]
+		if !exists {
+			continue
+		}
+
+		if output.Sensitive() {
+			cli.UI.Print(fmt.Sprintf("%s = (sensitive)\n", output.Name()))
+			continue
+		}
+
+		content, err := ctyjson.Marshal(value, value.Type())
+		if err != nil {
+			cli.UI.PrintError(fmt.Sprintf("Error printing output %q: %s\n", output.Name(), err.Error()))
+			continue
+		}
+
+		cli.UI.Print(fmt.Sprintf("%s = %s\n", output.Name(), content))
+	}
+}
+
+// writeRunResult writes the machine-readable result of the run to the file specified by --outputs-json.
+func writeRunResult(
+	w *workflow.Workflow,
+	stepOutputs []map[string]map[string]cty.Value,
+	outputs map[string]cty.Value,
+	outputDiags hcl.Diagnostics,
+	err error,
+) error {
+
+	return w.NewRunResult(stepOutputs, outputs, outputDiags, err).WriteFile(outputsJSON)
+}
@@ -39,6 +39,18 @@ type CLI struct {
 	debug bool
 }
 
+// InitStructuredUI initializes the UI for a command that writes structured output to stdout.
+//
+// Messages are printed to stderr without color, so that stdout only contains the structured output.
+func InitStructuredUI(debug bool) {
+	UI = &CLI{
+		color:  false,
+		stdout: os.Stderr,
+		stderr: os.Stderr,
+		debug:  debug,
+	}
+}
+
 // Print implements ui.UI.
 func (c *CLI) Print(text string) {
 	c.printText(c.stdout, text)
@@ -286,6 +298,16 @@ func (c *CLI) PrintIterationResult(hostname string, iterationLabel string, r *re
 	c.printResult(label, r)
 }
 
+// PrintAttemptResult implements ui.UI.
+func (c *CLI) PrintAttemptResult(hostname, iterationLabel string, attempt, attempts int, r *result.Result) {
+	label := hostname
+	if iterationLabel != "" {
+		label = fmt.Sprintf("%s -> %s", hostname, iterationLabel)
+	}
+
+	c.printResult(fmt.Sprintf("%s (attempt %d of %d)", label, attempt, attempts), r)
+}
+
 func (c *CLI) printText(writer io.Writer, text string) {
 	if writer == nil {
 		return
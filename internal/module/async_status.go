@@ -0,0 +1,107 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+import (
+	"fmt"
+
+	"github.com/trippsoft/forge/pkg/hclspec"
+	"github.com/trippsoft/forge/pkg/info"
+	"github.com/trippsoft/forge/pkg/plugin"
+	pluginv1 "github.com/trippsoft/forge/pkg/plugin/v1"
+	"github.com/trippsoft/forge/pkg/result"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/json"
+)
+
+var (
+	asyncStatusInputSpec = hclspec.NewSpec(hclspec.Object(
+		hclspec.RequiredField("job_file", hclspec.String),
+		hclspec.OptionalField("cleanup", hclspec.Bool).WithDefaultValue(cty.True),
+	))
+
+	AsyncStatus pluginv1.PluginModule = &AsyncStatusModule{}
+)
+
+// AsyncStatusModule is a module that collects the status of an async job started by a step with the async attribute.
+//
+// Until the job finishes, the module reports that it has not finished. Once it finishes, the module reports the result
+// of the job, with the output of the job in the output attribute, and removes the job file unless cleanup is false.
+type AsyncStatusModule struct{}
+
+// Name implements [pluginv1.PluginModule].
+func (m *AsyncStatusModule) Name() string {
+	return "async_status"
+}
+
+// Type implements [pluginv1.PluginModule].
+func (m *AsyncStatusModule) Type() plugin.ModuleType {
+	return plugin.ModuleType_REMOTE
+}
+
+// InputSpec implements [pluginv1.PluginModule].
+func (m *AsyncStatusModule) InputSpec() *hclspec.Spec {
+	return asyncStatusInputSpec
+}
+
+// RunModule implements [pluginv1.PluginModule].
+func (m *AsyncStatusModule) RunModule(
+	hostInfo *info.HostInfo,
+	input map[string]cty.Value,
+	whatIf bool,
+) *result.ModuleResult {
+	jobFile := input["job_file"].AsString()
+	cleanup := input["cleanup"].True()
+
+	status, err := pluginv1.ReadAsyncJobStatus(jobFile, cleanup)
+	if err != nil {
+		return pluginv1.NewFailure(err, "")
+	}
+
+	output := map[string]cty.Value{
+		"job_id":   cty.StringVal(status.Id),
+		"job_file": cty.StringVal(status.JobFile),
+		"finished": cty.BoolVal(status.Finished),
+		"output":   cty.EmptyObjectVal,
+	}
+
+	if !status.Finished {
+		notChanged, err := pluginv1.NewNotChanged(cty.ObjectVal(output))
+		if err != nil {
+			return pluginv1.NewFailure(fmt.Errorf("failed to create module success result: %w", err), "")
+		}
+
+		return notChanged
+	}
+
+	success, ok := status.Result.GetResult().(*result.ModuleResult_Success)
+	if !ok {
+		return status.Result
+	}
+
+	jobOutput, err := json.Unmarshal(success.Success.Output, cty.DynamicPseudoType)
+	if err != nil {
+		return pluginv1.NewFailure(fmt.Errorf("failed to parse the output of async job %q: %w", status.Id, err), "")
+	}
+
+	if !jobOutput.IsNull() {
+		output["output"] = jobOutput
+	}
+
+	var r *result.ModuleResult
+	if success.Success.Changed {
+		r, err = pluginv1.NewChanged(cty.ObjectVal(output))
+	} else {
+		r, err = pluginv1.NewNotChanged(cty.ObjectVal(output))
+	}
+
+	if err != nil {
+		return pluginv1.NewFailure(fmt.Errorf("failed to create module success result: %w", err), "")
+	}
+
+	r.Warnings = status.Result.Warnings
+	r.Messages = status.Result.Messages
+
+	return r
+}
@@ -0,0 +1,225 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package info
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/trippsoft/forge/pkg/result"
+	"github.com/trippsoft/forge/pkg/transport"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultCacheTTL is the default duration for which cached host info is used instead of discovering it again.
+	DefaultCacheTTL = 24 * time.Hour
+
+	// cacheFileExtension is the extension of the files that hold cached host info.
+	cacheFileExtension = ".pb"
+)
+
+// Cache is an on-disk cache of host info on the controller.
+//
+// Entries are keyed by the name of the host and the identity of its transport, so a host that is reached through a
+// different transport is discovered again. Each entry holds a serialized HostInfo message, and the modification time
+// of its file is the time the host info was discovered.
+type Cache struct {
+	path    string
+	ttl     time.Duration
+	refresh bool
+}
+
+// NewCache creates a Cache that stores host info in the directory at path and uses it for the TTL.
+//
+// A TTL less than or equal to 0 disables loading host info from the cache.
+func NewCache(path string, ttl time.Duration) *Cache {
+	return &Cache{
+		path: path,
+		ttl:  ttl,
+	}
+}
+
+// DefaultCachePath returns the default directory of the host info cache within the user's cache directory.
+func DefaultCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "forge", "info"), nil
+}
+
+// WithRefresh sets whether host info is always discovered again, replacing the cached host info.
+func (c *Cache) WithRefresh(refresh bool) *Cache {
+	c.refresh = refresh
+	return c
+}
+
+// Path returns the directory of the cache.
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// TTL returns the duration for which cached host info is used.
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// CacheEntry is the host info cached for a host.
+type CacheEntry struct {
+	HostInfo     *HostInfo // HostInfo is the cached host info.
+	DiscoveredAt time.Time // DiscoveredAt is the time the host info was discovered.
+	Expired      bool      // Expired indicates whether the entry is older than the TTL of the cache.
+}
+
+// Entry returns the host info cached for the host, or nil if there is none.
+func (c *Cache) Entry(name string, t transport.Transport) (*CacheEntry, error) {
+	path := c.entryPath(name, t)
+	stat, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached host info of %q: %w", name, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached host info of %q: %w", name, err)
+	}
+
+	hostInfo := NewHostInfo()
+	err = proto.UnmarshalOptions{Merge: true}.Unmarshal(content, hostInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cached host info of %q: %w", name, err)
+	}
+
+	return &CacheEntry{
+		HostInfo:     hostInfo,
+		DiscoveredAt: stat.ModTime(),
+		Expired:      c.ttl <= 0 || time.Since(stat.ModTime()) > c.ttl,
+	}, nil
+}
+
+// Load returns the host info cached for the host, if it has not expired and the cache is not being refreshed.
+func (c *Cache) Load(name string, t transport.Transport) (*CacheEntry, bool) {
+	if c.refresh {
+		return nil, false
+	}
+
+	entry, err := c.Entry(name, t)
+	if err != nil || entry == nil || entry.Expired {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// Store caches the host info of the host.
+func (c *Cache) Store(name string, t transport.Transport, hostInfo *HostInfo) error {
+	content, err := proto.Marshal(hostInfo)
+	if err != nil {
+		return fmt.Errorf("failed to serialize host info of %q: %w", name, err)
+	}
+
+	err = os.MkdirAll(c.path, 0700)
+	if err != nil {
+		return fmt.Errorf("failed to create host info cache %q: %w", c.path, err)
+	}
+
+	path := c.entryPath(name, t)
+	tempFile := path + ".tmp"
+	err = os.WriteFile(tempFile, content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write cached host info of %q: %w", name, err)
+	}
+
+	err = os.Rename(tempFile, path)
+	if err != nil {
+		return fmt.Errorf("failed to write cached host info of %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// Remove removes the host info cached for the host, if any.
+func (c *Cache) Remove(name string, t transport.Transport) error {
+	err := os.Remove(c.entryPath(name, t))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove cached host info of %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// Clear removes the host info cached for every host.
+func (c *Cache) Clear() error {
+	entries, err := os.ReadDir(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read host info cache %q: %w", c.path, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheFileExtension) {
+			continue
+		}
+
+		e := os.Remove(filepath.Join(c.path, entry.Name()))
+		if e != nil && !errors.Is(e, fs.ErrNotExist) {
+			err = errors.Join(err, fmt.Errorf("failed to remove cached host info %q: %w", entry.Name(), e))
+		}
+	}
+
+	return err
+}
+
+// Populate populates the host info from the cache, or discovers it using the transport and caches it.
+//
+// Failing to cache discovered host info is reported as a warning of the result.
+func (c *Cache) Populate(ctx context.Context, name string, hostInfo *HostInfo, t transport.Transport) *result.Result {
+	entry, ok := c.Load(name, t)
+	if ok {
+		hostInfo.CopyFrom(entry.HostInfo)
+
+		r := result.NewNotChanged(cty.EmptyObjectVal)
+		r.Messages = []string{
+			fmt.Sprintf("Loaded host info cached at %s", entry.DiscoveredAt.Format(time.RFC3339)),
+		}
+
+		return r
+	}
+
+	r := hostInfo.Populate(ctx, t)
+	if r.Failed {
+		return r
+	}
+
+	err := c.Store(name, t, hostInfo)
+	if err != nil {
+		r.Warnings = append(r.Warnings, err.Error())
+	}
+
+	return r
+}
+
+// entryPath returns the path of the file that holds the host info cached for the host.
+func (c *Cache) entryPath(name string, t transport.Transport) string {
+	hash := sha256.Sum256([]byte(name + "\x00" + t.Identity()))
+	return filepath.Join(c.path, hex.EncodeToString(hash[:])+cacheFileExtension)
+}
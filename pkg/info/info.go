@@ -20,9 +20,11 @@ const (
 )
 
 // Populate retrieves and populates the HostInfo using the provided transport.
-func (i *HostInfo) Populate(t transport.Transport) *result.Result {
+//
+// If the context is done before discovery completes, the plugin session is closed.
+func (i *HostInfo) Populate(ctx context.Context, t transport.Transport) *result.Result {
 	session, err := t.StartPluginSession(
-		context.Background(),
+		ctx,
 		plugin.SharedPluginBasePath,
 		"forge",
 		"discover",
@@ -34,6 +36,11 @@ func (i *HostInfo) Populate(t transport.Transport) *result.Result {
 	}
 	defer session.Close()
 
+	stop := context.AfterFunc(ctx, func() {
+		session.Close()
+	})
+	defer stop()
+
 	request := &DiscoverRequest{}
 	err = plugin.Write(session.Stdin(), request)
 	if err != nil {
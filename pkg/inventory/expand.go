@@ -0,0 +1,359 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/hclfunction"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxExpandedHosts is the maximum number of hosts that a host block can expand into.
+const maxExpandedHosts = 10000
+
+var hostRangePattern = regexp.MustCompile(`\[([^\[\]:]+):([^\[\]:]+)\]`)
+
+// hostInstance is a host generated by a host block.
+type hostInstance struct {
+	name string
+	each cty.Value // each is the each object of a host block with for_each, or cty.NilVal.
+}
+
+// expandHostBlock returns the hosts generated by a host block.
+//
+// If the block has a for_each attribute, one host is generated for each element of its value. Each host is named by
+// the name attribute of the block, which has each.key and each.value available, or after each.key without one.
+// Ranges within the resulting names, such as "web[01:40]" or "db-[a:f]", are then expanded into one host per value.
+func expandHostBlock(block *hcl.Block) ([]*hostInstance, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	content, _, moreDiags := block.Body.PartialContent(hostForEachSchema)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	instances := []*hostInstance{}
+	forEach, exists := content.Attributes["for_each"]
+	if !exists {
+		if attr, exists := content.Attributes["name"]; exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unexpected name attribute",
+				Detail:   `The "name" attribute is only supported in host blocks with a "for_each" attribute.`,
+				Subject:  &attr.Range,
+			})
+			return nil, diags
+		}
+
+		instances = append(instances, &hostInstance{
+			name: block.Labels[0],
+			each: cty.NilVal,
+		})
+	} else {
+		workingDir, err := os.Getwd()
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to get working directory",
+				Detail:   fmt.Sprintf("Failed to get working directory: %s", err.Error()),
+				Subject:  nil,
+			})
+			return nil, diags
+		}
+
+		evalCtx := &hcl.EvalContext{
+			Functions: hclfunction.HCLFunctions(workingDir),
+		}
+
+		eachValues, moreDiags := evaluateHostForEach(forEach, evalCtx)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil, diags
+		}
+
+		for _, each := range eachValues {
+			name, moreDiags := evaluateHostName(content.Attributes["name"], each, evalCtx)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			instances = append(instances, &hostInstance{
+				name: name,
+				each: each,
+			})
+		}
+	}
+
+	expanded := []*hostInstance{}
+	for _, instance := range instances {
+		names, err := expandHostRanges(instance.name)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid host range",
+				Detail:   fmt.Sprintf("The host name %q is invalid: %s.", instance.name, err.Error()),
+				Subject:  &block.DefRange,
+			})
+			continue
+		}
+
+		if len(expanded)+len(names) > maxExpandedHosts {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid host range",
+				Detail:   fmt.Sprintf("The host block expands into more than %d hosts.", maxExpandedHosts),
+				Subject:  &block.DefRange,
+			})
+			break
+		}
+
+		for _, name := range names {
+			expanded = append(expanded, &hostInstance{
+				name: name,
+				each: instance.each,
+			})
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return expanded, diags
+}
+
+// evaluateHostForEach evaluates the for_each attribute of a host block and returns the each object for every element.
+//
+// A map or object yields its keys and values, sorted by key. A list, set or tuple of strings yields each element as
+// both the key and the value, in order.
+func evaluateHostForEach(attr *hcl.Attribute, evalCtx *hcl.EvalContext) ([]cty.Value, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	value, moreDiags := attr.Expr.Value(evalCtx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	if value.IsNull() || !value.IsWhollyKnown() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid for_each value",
+			Detail:   `The "for_each" attribute of a host block must not be null or unknown.`,
+			Subject:  &attr.Range,
+		})
+		return nil, diags
+	}
+
+	valueType := value.Type()
+	eachValues := []cty.Value{}
+	switch {
+	case valueType.IsMapType() || valueType.IsObjectType():
+		it := value.ElementIterator()
+		for it.Next() {
+			key, elem := it.Element()
+			eachValues = append(eachValues, cty.ObjectVal(map[string]cty.Value{
+				"key":   key,
+				"value": elem,
+			}))
+		}
+
+	case valueType.IsListType() || valueType.IsSetType() || valueType.IsTupleType():
+		for _, elem := range value.AsValueSlice() {
+			if !elem.Type().Equals(cty.String) {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid for_each value",
+					Detail: fmt.Sprintf(
+						`Each element in the "for_each" list of a host block must be a string, but got %q.`,
+						elem.Type().FriendlyName(),
+					),
+					Subject: &attr.Range,
+				})
+				return nil, diags
+			}
+
+			eachValues = append(eachValues, cty.ObjectVal(map[string]cty.Value{
+				"key":   elem,
+				"value": elem,
+			}))
+		}
+
+	default:
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid for_each value",
+			Detail: fmt.Sprintf(
+				`The "for_each" attribute of a host block must be a map or a list of strings, but got %q.`,
+				valueType.FriendlyName(),
+			),
+			Subject: &attr.Range,
+		})
+		return nil, diags
+	}
+
+	return eachValues, diags
+}
+
+// evaluateHostName evaluates the name attribute of a host block with for_each for one of its elements.
+//
+// If the block has no name attribute, the host is named after each.key.
+func evaluateHostName(attr *hcl.Attribute, each cty.Value, evalCtx *hcl.EvalContext) (string, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	if attr == nil {
+		return each.GetAttr("key").AsString(), diags
+	}
+
+	value, moreDiags := withEach(attr, each).Expr.Value(evalCtx)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return "", diags
+	}
+
+	if value.IsNull() || !value.IsKnown() || !value.Type().Equals(cty.String) {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid host name",
+			Detail: fmt.Sprintf(
+				`The "name" attribute of a host block must be a string, but got %q.`,
+				value.Type().FriendlyName(),
+			),
+			Subject: &attr.Range,
+		})
+		return "", diags
+	}
+
+	return value.AsString(), diags
+}
+
+// expandHostRanges expands the ranges within a host name into the host names they represent.
+//
+// A range is either two numbers, such as "[01:40]", or two letters, such as "[a:f]". Numbers are padded with zeros
+// to the width of the start of the range if it has a leading zero. A name with multiple ranges is expanded into
+// every combination of their values, which must not be more than maxExpandedHosts.
+func expandHostRanges(name string) ([]string, error) {
+	match := hostRangePattern.FindStringSubmatchIndex(name)
+	if match == nil {
+		return []string{name}, nil
+	}
+
+	prefix := name[:match[0]]
+	start := name[match[2]:match[3]]
+	end := name[match[4]:match[5]]
+
+	values, err := hostRangeValues(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	suffixes, err := expandHostRanges(name[match[1]:])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values)*len(suffixes) > maxExpandedHosts {
+		return nil, fmt.Errorf("it expands into more than %d hosts", maxExpandedHosts)
+	}
+
+	names := make([]string, 0, len(values)*len(suffixes))
+	for _, value := range values {
+		for _, suffix := range suffixes {
+			names = append(names, prefix+value+suffix)
+		}
+	}
+
+	return names, nil
+}
+
+// hostRangeValues returns the values of a host range from its start and end.
+func hostRangeValues(start string, end string) ([]string, error) {
+	startNumber, startErr := strconv.ParseUint(start, 10, 32)
+	endNumber, endErr := strconv.ParseUint(end, 10, 32)
+	if startErr == nil && endErr == nil {
+		if startNumber > endNumber {
+			return nil, fmt.Errorf("the range [%s:%s] starts after it ends", start, end)
+		}
+
+		if endNumber-startNumber >= maxExpandedHosts {
+			return nil, fmt.Errorf("the range [%s:%s] expands into more than %d hosts", start, end, maxExpandedHosts)
+		}
+
+		width := 0
+		if len(start) > 1 && start[0] == '0' {
+			width = len(start)
+		}
+
+		values := make([]string, 0, endNumber-startNumber+1)
+		for i := startNumber; i <= endNumber; i++ {
+			values = append(values, fmt.Sprintf("%0*d", width, i))
+		}
+
+		return values, nil
+	}
+
+	if isHostRangeLetter(start) && isHostRangeLetter(end) && isLowerLetter(start[0]) == isLowerLetter(end[0]) {
+		if start[0] > end[0] {
+			return nil, fmt.Errorf("the range [%s:%s] starts after it ends", start, end)
+		}
+
+		values := make([]string, 0, end[0]-start[0]+1)
+		for c := start[0]; c <= end[0]; c++ {
+			values = append(values, string(c))
+		}
+
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("the range [%s:%s] must be between two numbers or two letters of the same case", start, end)
+}
+
+// isHostRangeLetter indicates whether the bound of a host range is a single ASCII letter.
+func isHostRangeLetter(s string) bool {
+	return len(s) == 1 && (isLowerLetter(s[0]) || (s[0] >= 'A' && s[0] <= 'Z'))
+}
+
+// isLowerLetter indicates whether a byte is a lowercase ASCII letter.
+func isLowerLetter(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+// eachExpr is an expression within a host block with for_each, which has each.key and each.value available.
+type eachExpr struct {
+	hcl.Expression
+	each cty.Value
+}
+
+// Value implements hcl.Expression.
+func (e *eachExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if ctx == nil {
+		ctx = &hcl.EvalContext{}
+	}
+
+	child := ctx.NewChild()
+	child.Variables = map[string]cty.Value{
+		"each": e.each,
+	}
+
+	return e.Expression.Value(child)
+}
+
+// withEach returns a copy of an attribute that has each.key and each.value available.
+func withEach(attr *hcl.Attribute, each cty.Value) *hcl.Attribute {
+	if attr == nil || each.Type().Equals(cty.NilType) {
+		return attr
+	}
+
+	copied := *attr
+	copied.Expr = &eachExpr{
+		Expression: attr.Expr,
+		each:       each,
+	}
+
+	return &copied
+}
@@ -0,0 +1,240 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package inventory
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// redacted replaces secrets in the values of the inventory.
+const redacted = "<redacted>"
+
+var escalateValueType = cty.Object(map[string]cty.Type{
+	"password": cty.String,
+})
+
+// Value returns the inventory as a cty.Value, for structured output.
+//
+// The value is an object with the hosts of the inventory, as described by HostValue, and its groups with their
+// parents and hosts.
+func (i *Inventory) Value() cty.Value {
+	hosts := make(map[string]cty.Value, len(i.hosts))
+	for name := range i.hosts {
+		hosts[name], _ = i.HostValue(name)
+	}
+
+	groups := make(map[string]cty.Value, len(i.parents))
+	for _, name := range i.groupNames() {
+		parent := cty.NullVal(cty.String)
+		if p := i.parents[name]; p != "" {
+			parent = cty.StringVal(p)
+		}
+
+		groups[name] = cty.ObjectVal(map[string]cty.Value{
+			"parent": parent,
+			"hosts":  stringListValue(hostNames(i.groups[name])),
+		})
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"hosts":  cty.ObjectVal(hosts),
+		"groups": cty.ObjectVal(groups),
+	})
+}
+
+// HostValue returns a host of the inventory as a cty.Value, for structured output.
+//
+// The value is an object with the resolved vars of the host, every group it is a member of, the type of its
+// transport and its escalation config. The escalation password is redacted.
+// It returns the value and a boolean indicating if the host exists.
+func (i *Inventory) HostValue(name string) (cty.Value, bool) {
+	host, exists := i.hosts[name]
+	if !exists {
+		return cty.NilVal, false
+	}
+
+	groups := []string{}
+	for groupName, hosts := range i.groups {
+		if slices.Contains(hosts, host) {
+			groups = append(groups, groupName)
+		}
+	}
+
+	slices.Sort(groups)
+
+	transportType := cty.NullVal(cty.String)
+	if host.transport != nil {
+		transportType = cty.StringVal(string(host.transport.Type()))
+	}
+
+	escalate := cty.NullVal(escalateValueType)
+	if host.escalateConfig != nil && host.escalateConfig.Pass() != "" {
+		escalate = cty.ObjectVal(map[string]cty.Value{
+			"password": cty.StringVal(redacted),
+		})
+	}
+
+	vars := cty.EmptyObjectVal
+	if len(host.vars) > 0 {
+		vars = cty.ObjectVal(host.vars)
+	}
+
+	value := cty.ObjectVal(map[string]cty.Value{
+		"groups":    stringListValue(groups),
+		"transport": transportType,
+		"escalate":  escalate,
+		"vars":      vars,
+	})
+
+	return value, true
+}
+
+// TargetNames returns the sorted names of all targets in the inventory.
+//
+// This includes the pseudo-group 'all' and hostnames as targets.
+func (i *Inventory) TargetNames() []string {
+	return slices.Sorted(maps.Keys(i.targets))
+}
+
+// Tree returns the hierarchy of the groups of the inventory and their hosts as a tree, rooted at 'all'.
+//
+// Each group is listed under its parent, followed by the hosts that are directly members of it.
+func (i *Inventory) Tree() string {
+	sb := &strings.Builder{}
+	sb.WriteString("all\n")
+	i.writeTree(sb, "", "")
+	return sb.String()
+}
+
+func (i *Inventory) writeTree(sb *strings.Builder, group string, indent string) {
+	children := i.childGroups(group)
+	hosts := i.directHosts(group)
+	count := len(children) + len(hosts)
+
+	n := 0
+	for _, name := range append(children, hosts...) {
+		n++
+		branch, nextIndent := "├── ", "│   "
+		if n == count {
+			branch, nextIndent = "└── ", "    "
+		}
+
+		sb.WriteString(indent)
+		sb.WriteString(branch)
+		sb.WriteString(name)
+		sb.WriteRune('\n')
+
+		if n <= len(children) {
+			i.writeTree(sb, name, indent+nextIndent)
+		}
+	}
+}
+
+// DOT returns the hierarchy of the groups of the inventory and their hosts as a Graphviz DOT graph.
+//
+// Groups are drawn as ellipses with an edge from their parent, and hosts as boxes with an edge from each group they
+// are directly a member of.
+func (i *Inventory) DOT() string {
+	sb := &strings.Builder{}
+	sb.WriteString("digraph inventory {\n")
+	sb.WriteString("    node [shape=box];\n")
+	sb.WriteString("    \"all\" [shape=ellipse];\n")
+
+	groups := i.groupNames()
+	for _, name := range groups {
+		fmt.Fprintf(sb, "    %q [shape=ellipse];\n", name)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(i.hosts)) {
+		fmt.Fprintf(sb, "    %q;\n", name)
+	}
+
+	for _, group := range append([]string{""}, groups...) {
+		parent := group
+		if parent == "" {
+			parent = "all"
+		}
+
+		for _, child := range i.childGroups(group) {
+			fmt.Fprintf(sb, "    %q -> %q;\n", parent, child)
+		}
+
+		for _, host := range i.directHosts(group) {
+			fmt.Fprintf(sb, "    %q -> %q;\n", parent, host)
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// groupNames returns the sorted names of all groups in the inventory, including groups without any hosts.
+func (i *Inventory) groupNames() []string {
+	names := slices.Collect(maps.Keys(i.parents))
+	for name := range i.groups {
+		if _, exists := i.parents[name]; !exists {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// childGroups returns the sorted names of the groups whose parent is the group, or of the top-level groups if the
+// group is empty.
+func (i *Inventory) childGroups(group string) []string {
+	children := []string{}
+	for _, name := range i.groupNames() {
+		if i.parents[name] == group {
+			children = append(children, name)
+		}
+	}
+
+	return children
+}
+
+// directHosts returns the sorted names of the hosts that are directly members of the group, or of the hosts without
+// any groups if the group is empty.
+func (i *Inventory) directHosts(group string) []string {
+	hosts := []string{}
+	for name, host := range i.hosts {
+		if (group == "" && len(host.groups) == 0) || (group != "" && slices.Contains(host.groups, group)) {
+			hosts = append(hosts, name)
+		}
+	}
+
+	slices.Sort(hosts)
+	return hosts
+}
+
+// hostNames returns the sorted names of the hosts.
+func hostNames(hosts []*Host) []string {
+	names := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		names = append(names, host.name)
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// stringListValue returns a list of strings as a cty.Value.
+func stringListValue(values []string) cty.Value {
+	if len(values) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+
+	elements := make([]cty.Value, 0, len(values))
+	for _, value := range values {
+		elements = append(elements, cty.StringVal(value))
+	}
+
+	return cty.ListVal(elements)
+}
@@ -6,6 +6,7 @@ package inventory
 import (
 	"errors"
 	"maps"
+	"sync"
 
 	"github.com/trippsoft/forge/pkg/info"
 	"github.com/trippsoft/forge/pkg/transport"
@@ -31,7 +32,8 @@ func (e *EscalateConfig) Pass() string {
 
 // Host represents a single host in the inventory.
 type Host struct {
-	name string
+	name   string
+	groups []string
 
 	transport      transport.Transport
 	escalateConfig *EscalateConfig
@@ -39,6 +41,9 @@ type Host struct {
 	info *info.HostInfo
 	vars map[string]cty.Value
 
+	runtimeVars  map[string]cty.Value
+	runtimeMutex sync.RWMutex
+
 	stepContexts    []map[string]cty.Value
 	procedureInputs []map[string]cty.Value
 }
@@ -48,6 +53,13 @@ func (h *Host) Name() string {
 	return h.name
 }
 
+// Groups returns the names of the groups the host is directly a member of.
+//
+// This does not include the groups the host is a member of through their child groups.
+func (h *Host) Groups() []string {
+	return h.groups
+}
+
 // Transport returns the transport used to connect to the host.
 func (h *Host) Transport() transport.Transport {
 	return h.transport
@@ -68,6 +80,38 @@ func (h *Host) Vars() map[string]cty.Value {
 	return h.vars
 }
 
+// RuntimeVars returns a clone of the variables set on the host while a workflow runs.
+//
+// Runtime variables take precedence over the variables of the host.
+func (h *Host) RuntimeVars() map[string]cty.Value {
+	h.runtimeMutex.RLock()
+	defer h.runtimeMutex.RUnlock()
+
+	return maps.Clone(h.runtimeVars)
+}
+
+// SetRuntimeVars sets variables on the host for the rest of the workflow run.
+//
+// Existing runtime variables with the same names are overwritten.
+func (h *Host) SetRuntimeVars(vars map[string]cty.Value) {
+	h.runtimeMutex.Lock()
+	defer h.runtimeMutex.Unlock()
+
+	if h.runtimeVars == nil {
+		h.runtimeVars = make(map[string]cty.Value, len(vars))
+	}
+
+	maps.Copy(h.runtimeVars, vars)
+}
+
+// ClearRuntimeVars clears the runtime variables of the host.
+func (h *Host) ClearRuntimeVars() {
+	h.runtimeMutex.Lock()
+	defer h.runtimeMutex.Unlock()
+
+	h.runtimeVars = nil
+}
+
 // GetCurrentContextSteps retrieves the current step context for the host.
 func (h *Host) GetCurrentContextSteps() (map[string]cty.Value, error) {
 	if len(h.stepContexts) == 0 {
@@ -127,7 +171,8 @@ func (h *Host) GetCurrentProcedureInputs() map[string]cty.Value {
 
 // HostBuilder is used to build Host instances.
 type HostBuilder struct {
-	name string
+	name   string
+	groups []string
 
 	transport      transport.Transport
 	escalateConfig *EscalateConfig
@@ -146,6 +191,12 @@ func (b *HostBuilder) WithName(name string) *HostBuilder {
 	return b
 }
 
+// WithGroups sets the names of the groups the host is directly a member of.
+func (b *HostBuilder) WithGroups(groups []string) *HostBuilder {
+	b.groups = groups
+	return b
+}
+
 // WithTransport sets the transport for the host.
 func (b *HostBuilder) WithTransport(transport transport.Transport) *HostBuilder {
 	b.transport = transport
@@ -178,8 +229,13 @@ func (b *HostBuilder) Build() (*Host, error) {
 		b.vars = make(map[string]cty.Value, 0)
 	}
 
+	if b.groups == nil {
+		b.groups = []string{}
+	}
+
 	return &Host{
 		name:           b.name,
+		groups:         b.groups,
 		transport:      b.transport,
 		escalateConfig: b.escalateConfig,
 		info:           info.NewHostInfo(),
@@ -193,6 +249,8 @@ type Inventory struct {
 
 	groups  map[string][]*Host
 	targets map[string][]*Host
+
+	parents map[string]string
 }
 
 func NewInventory(hosts map[string]*Host, groups map[string][]*Host, targets map[string][]*Host) *Inventory {
@@ -200,6 +258,7 @@ func NewInventory(hosts map[string]*Host, groups map[string][]*Host, targets map
 		hosts:   hosts,
 		groups:  groups,
 		targets: targets,
+		parents: map[string]string{},
 	}
 }
 
@@ -234,6 +293,15 @@ func (i *Inventory) Groups() map[string][]*Host {
 	return groups
 }
 
+// GroupParent retrieves the name of the parent of a group from the inventory.
+//
+// It returns the name of the parent, which is empty for a top-level group, and a boolean indicating if the group
+// exists. Unlike Group, it includes groups without any hosts.
+func (i *Inventory) GroupParent(name string) (string, bool) {
+	parent, exists := i.parents[name]
+	return parent, exists
+}
+
 // Target retrieves a target group of hosts by name from the inventory.
 //
 // It returns the hosts in the target group and a boolean indicating if the target exists.
@@ -257,3 +325,10 @@ func (i *Inventory) ClearSteps() {
 		host.ClearSteps()
 	}
 }
+
+// ClearRuntimeVars clears the runtime variables of every host in the inventory.
+func (i *Inventory) ClearRuntimeVars() {
+	for _, host := range i.hosts {
+		host.ClearRuntimeVars()
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
@@ -21,11 +22,17 @@ import (
 type InventoryFile struct {
 	Path    string // Path returns the file path of the inventory file.
 	Content []byte // Content contains the raw content of the inventory file.
+
+	HostVars  string // HostVars is the name of the host the file contains vars for, if it is a host_vars file.
+	GroupVars string // GroupVars is the name of the group the file contains vars for, if it is a group_vars file.
 }
 
 // DiscoverInventoryFiles retrieves all inventory files from the specified paths.
 //
-// It walks through each path, looking for files with the ".hcl" extension that are considered inventory files.
+// It walks through each path, looking for files with the ".hcl", ".json", ".yaml", or ".yml" extension that are
+// considered inventory files.
+// Files named host_vars/<host>.hcl or group_vars/<group>.hcl, or within a host_vars/<host> or group_vars/<group>
+// directory, are vars files that contain the vars of the host or group.
 // It returns a slice of pointers to InventoryFile structs, each containing the file path and its content.
 // If an error occurs during reading the files, it returns an error.
 func DiscoverInventoryFiles(paths ...string) ([]*InventoryFile, error) {
@@ -36,8 +43,8 @@ func DiscoverInventoryFiles(paths ...string) ([]*InventoryFile, error) {
 				return err
 			}
 
-			if info.IsDir() || filepath.Ext(path) != ".hcl" {
-				return nil // Skip directories and non-HCL files
+			if info.IsDir() || !isInventoryFileExtension(filepath.Ext(path)) {
+				return nil // Skip directories and files in unsupported formats
 			}
 
 			content, err := os.ReadFile(path)
@@ -45,9 +52,12 @@ func DiscoverInventoryFiles(paths ...string) ([]*InventoryFile, error) {
 				return fmt.Errorf("failed to read inventory file %q: %w", path, err)
 			}
 
+			hostVars, groupVars := varsFileTarget(path)
 			inventoryFiles = append(inventoryFiles, &InventoryFile{
-				Path:    path,
-				Content: content,
+				Path:      path,
+				Content:   content,
+				HostVars:  hostVars,
+				GroupVars: groupVars,
 			})
 
 			return nil
@@ -61,24 +71,51 @@ func DiscoverInventoryFiles(paths ...string) ([]*InventoryFile, error) {
 	return inventoryFiles, nil
 }
 
+// isInventoryFileExtension indicates whether a file with the extension is an inventory file.
+func isInventoryFileExtension(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".hcl", ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
 // ParseInventoryFiles parses the content of the inventory files and returns the parsed inventory.
+//
+// Files with the ".json" extension are parsed with the JSON syntax of HCL and files with the ".yaml" or ".yml"
+// extension are mapped onto the same structure. All other files are parsed with the native syntax of HCL.
+// Files of different formats are merged into a single inventory.
+// The vars of host_vars and group_vars files are applied to their host or group, as described by applyVarsFiles.
 func ParseInventoryFiles(files ...*InventoryFile) (*Inventory, hcl.Diagnostics) {
 	parser := hclparse.NewParser()
 	diags := hcl.Diagnostics{}
 	hclFiles := make([]*hcl.File, 0, len(files))
+	varsFiles := []*varsFile{}
 	for _, file := range files {
-		hclFile, moreDiags := parser.ParseHCL(file.Content, file.Path)
+		hclFile, moreDiags := parseInventoryFile(parser, file)
 		diags = diags.Extend(moreDiags)
 		if moreDiags.HasErrors() {
 			continue // Skip files with parsing errors
 		}
 
-		hclFiles = append(hclFiles, hclFile)
+		if file.HostVars == "" && file.GroupVars == "" {
+			hclFiles = append(hclFiles, hclFile)
+			continue
+		}
+
+		vars, moreDiags := parseVarsFile(file, hclFile)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		varsFiles = append(varsFiles, vars)
 	}
 
 	mergedBody := hcl.MergeFiles(hclFiles)
 
-	inventory, moreDiags := parseHCLBody(mergedBody)
+	inventory, moreDiags := parseHCLBody(mergedBody, varsFiles)
 	diags = diags.Extend(moreDiags)
 	if moreDiags.HasErrors() {
 		return nil, diags
@@ -87,12 +124,30 @@ func ParseInventoryFiles(files ...*InventoryFile) (*Inventory, hcl.Diagnostics)
 	return inventory, diags
 }
 
-func parseHCLBody(body hcl.Body) (*Inventory, hcl.Diagnostics) {
+// parseInventoryFile parses an inventory file with the syntax of its format.
+func parseInventoryFile(parser *hclparse.Parser, file *InventoryFile) (*hcl.File, hcl.Diagnostics) {
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".json":
+		return parser.ParseJSON(file.Content, file.Path)
+	case ".yaml", ".yml":
+		return parseYAML(file.Content, file.Path)
+	default:
+		return parser.ParseHCL(file.Content, file.Path)
+	}
+}
+
+func parseHCLBody(body hcl.Body, varsFiles []*varsFile) (*Inventory, hcl.Diagnostics) {
 	intermediate, diags := parseHCLBodyToIntermediate(body)
 	if diags.HasErrors() {
 		return nil, diags
 	}
 
+	moreDiags := applyVarsFiles(intermediate, varsFiles)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
 	inventory, moreDiags := resolveIntermediate(intermediate)
 	diags = diags.Extend(moreDiags)
 	if moreDiags.HasErrors() {
@@ -119,6 +174,7 @@ func parseHCLBodyToIntermediate(body hcl.Body) (*intermediateInventory, hcl.Diag
 	escalateBlocks := []*hcl.Block{}
 	groupBlocks := []*hcl.Block{}
 	hostBlocks := []*hcl.Block{}
+	pluginBlocks := []*hcl.Block{}
 
 	for _, block := range content.Blocks {
 
@@ -133,6 +189,8 @@ func parseHCLBodyToIntermediate(body hcl.Body) (*intermediateInventory, hcl.Diag
 			groupBlocks = append(groupBlocks, block)
 		case "host":
 			hostBlocks = append(hostBlocks, block)
+		case "plugin":
+			pluginBlocks = append(pluginBlocks, block)
 		}
 	}
 
@@ -173,6 +231,20 @@ func parseHCLBodyToIntermediate(body hcl.Body) (*intermediateInventory, hcl.Diag
 		intermediate.hosts = hosts
 	}
 
+	plugins, moreDiags := parsePluginBlocks(pluginBlocks)
+	diags = diags.Extend(moreDiags)
+	if diags.HasErrors() {
+		return nil, diags // Do not run plugins of an invalid inventory
+	}
+
+	for _, plugin := range plugins {
+		diags = diags.Extend(plugin.load(intermediate))
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
 	for _, host := range intermediate.hosts {
 		for _, groupName := range host.groups {
 			if _, exists := intermediate.groups[groupName]; !exists {
@@ -590,28 +662,36 @@ func parseHostBlocksToIntermediate(blocks []*hcl.Block) (map[string]*intermediat
 	hosts := make(map[string]*intermediateHost)
 
 	for _, block := range blocks {
-		host, moreDiags := parseHostBlockToIntermediate(block)
+		instances, moreDiags := expandHostBlock(block)
 		diags = diags.Extend(moreDiags)
-
 		if moreDiags.HasErrors() {
 			continue
 		}
 
-		if _, exists := hosts[host.name]; exists {
-			// TODO - merge hosts if they have the same name/context?
-			diags = diags.Append(&hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Duplicate host name",
-				Detail: fmt.Sprintf(
-					"Host '%s' is defined multiple times in the inventory file. Each host must have a unique name.",
-					host.name,
-				),
-				Subject: host.hclRange,
-			})
-			continue
-		}
+		for _, instance := range instances {
+			host, moreDiags := parseHostBlockToIntermediate(block, instance)
+			diags = diags.Extend(moreDiags)
+
+			if moreDiags.HasErrors() {
+				continue
+			}
 
-		hosts[host.name] = host
+			if _, exists := hosts[host.name]; exists {
+				// TODO - merge hosts if they have the same name/context?
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate host name",
+					Detail: fmt.Sprintf(
+						"Host '%s' is defined multiple times in the inventory file. Each host must have a unique name.",
+						host.name,
+					),
+					Subject: host.hclRange,
+				})
+				continue
+			}
+
+			hosts[host.name] = host
+		}
 	}
 
 	if diags.HasErrors() {
@@ -621,8 +701,8 @@ func parseHostBlocksToIntermediate(blocks []*hcl.Block) (map[string]*intermediat
 	return hosts, diags
 }
 
-func parseHostBlockToIntermediate(block *hcl.Block) (*intermediateHost, hcl.Diagnostics) {
-	hostName := block.Labels[0]
+func parseHostBlockToIntermediate(block *hcl.Block, instance *hostInstance) (*intermediateHost, hcl.Diagnostics) {
+	hostName := instance.name
 	if hostName == "" {
 		return nil, hcl.Diagnostics{
 			&hcl.Diagnostic{
@@ -667,25 +747,37 @@ func parseHostBlockToIntermediate(block *hcl.Block) (*intermediateHost, hcl.Diag
 	vars, moreDiags := parseVarsBlocksToIntermediate(varsBlocks)
 	diags = diags.Extend(moreDiags)
 	if !moreDiags.HasErrors() {
-		host.vars = vars
+		for name, attr := range vars {
+			host.vars[name] = withEach(attr, instance.each)
+		}
 	}
 
 	transport, moreDiags := parseTransportBlocksToIntermediate(transportBlocks)
 	diags = diags.Extend(moreDiags)
 	if !moreDiags.HasErrors() {
+		if transport != nil {
+			for name, attr := range transport.config {
+				transport.config[name] = withEach(attr, instance.each)
+			}
+		}
+
 		host.transport = transport
 	}
 
 	escalate, moreDiags := parseEscalateBlocksToIntermediate(escalateBlocks)
 	diags = diags.Extend(moreDiags)
 	if !moreDiags.HasErrors() {
+		if escalate != nil {
+			escalate.password = withEach(escalate.password, instance.each)
+		}
+
 		host.escalate = escalate
 	}
 
 	for name, attr := range content.Attributes {
 		switch name {
 		case "groups":
-			value, moreDiags := attr.Expr.Value(nil)
+			value, moreDiags := withEach(attr, instance.each).Expr.Value(nil)
 			diags = diags.Extend(moreDiags)
 			if moreDiags.HasErrors() {
 				continue // Skip this attribute if there are errors
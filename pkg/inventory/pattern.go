@@ -0,0 +1,245 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package inventory
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/trippsoft/forge/pkg/set"
+)
+
+// TargetNotFoundError is returned when a host pattern names a target that does not exist in the inventory.
+type TargetNotFoundError struct {
+	Target string
+}
+
+// Error implements error.
+func (e *TargetNotFoundError) Error() string {
+	return fmt.Sprintf("the target %q does not exist in the inventory", e.Target)
+}
+
+// ResolvePattern resolves a host pattern to the hosts it selects.
+//
+// A pattern is a list of terms separated by commas or colons. Each term is one of:
+//   - a target name, which is a host name, a group name or 'all'
+//   - a glob, such as 'web*', which matches target names
+//   - a regular expression prefixed with '~', such as '~web[0-9]+', which matches target names
+//   - a file prefixed with '@', which contains one term per line
+//
+// Terms prefixed with '&' intersect the selected hosts with the hosts of the term, and terms prefixed with '!' exclude
+// the hosts of the term. If a pattern only has such terms, they apply to all hosts.
+// A pattern or term that is exactly the name of a target selects that target, even if its name contains separators or
+// glob characters.
+// The hosts are returned sorted by name.
+func (i *Inventory) ResolvePattern(pattern string) ([]*Host, error) {
+	if hosts, exists := i.targets[strings.TrimSpace(pattern)]; exists {
+		return sortedHosts(hosts), nil
+	}
+
+	terms, err := splitPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(terms) == 0 {
+		return nil, errors.New("the host pattern is empty")
+	}
+
+	included := set.NewSet[*Host]()
+	intersections := []*set.Set[*Host]{}
+	excluded := set.NewSet[*Host]()
+	hasInclusions := false
+
+	for _, term := range terms {
+		switch {
+		case strings.HasPrefix(term, "!"):
+			hosts, err := i.resolveTerm(term[1:])
+			if err != nil {
+				return nil, err
+			}
+
+			for _, host := range hosts {
+				excluded.Add(host)
+			}
+
+		case strings.HasPrefix(term, "&"):
+			hosts, err := i.resolveTerm(term[1:])
+			if err != nil {
+				return nil, err
+			}
+
+			intersections = append(intersections, set.NewSet(hosts...))
+
+		default:
+			hosts, err := i.resolveTerm(term)
+			if err != nil {
+				return nil, err
+			}
+
+			hasInclusions = true
+			for _, host := range hosts {
+				included.Add(host)
+			}
+		}
+	}
+
+	if !hasInclusions {
+		for _, host := range i.hosts {
+			included.Add(host)
+		}
+	}
+
+	if len(intersections) > 0 {
+		included = set.Intersection(append([]*set.Set[*Host]{included}, intersections...)...)
+	}
+
+	return sortedHosts(set.Difference(included, excluded).Items()), nil
+}
+
+// sortedHosts returns a copy of the hosts sorted by name.
+func sortedHosts(hosts []*Host) []*Host {
+	sorted := slices.Clone(hosts)
+	slices.SortFunc(sorted, func(a, b *Host) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return sorted
+}
+
+// resolveTerm resolves a single term of a host pattern without its '&' or '!' prefix.
+func (i *Inventory) resolveTerm(term string) ([]*Host, error) {
+	if hosts, exists := i.targets[term]; exists {
+		return hosts, nil
+	}
+
+	switch {
+	case term == "":
+		return nil, errors.New("the host pattern contains an empty term")
+
+	case strings.HasPrefix(term, "@"):
+		return i.resolveFileTerm(term[1:])
+
+	case strings.HasPrefix(term, "~"):
+		re, err := regexp.Compile(term[1:])
+		if err != nil {
+			return nil, fmt.Errorf("the host pattern %q is not a valid regular expression: %w", term, err)
+		}
+
+		return i.matchTargets(re.MatchString), nil
+
+	case strings.ContainsAny(term, "*?["):
+		_, err := path.Match(term, "")
+		if err != nil {
+			return nil, fmt.Errorf("the host pattern %q is not a valid glob: %w", term, err)
+		}
+
+		return i.matchTargets(func(name string) bool {
+			matched, _ := path.Match(term, name)
+			return matched
+		}), nil
+	}
+
+	return nil, &TargetNotFoundError{Target: term}
+}
+
+// resolveFileTerm resolves the terms listed in a file, one per line.
+//
+// Blank lines and lines starting with '#' are ignored.
+func (i *Inventory) resolveFileTerm(path string) ([]*Host, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("the host pattern file %q could not be read: %w", path, err)
+	}
+	defer file.Close()
+
+	hosts := set.NewSet[*Host]()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lineHosts, err := i.ResolvePattern(line)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, host := range lineHosts {
+			hosts.Add(host)
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("the host pattern file %q could not be read: %w", path, err)
+	}
+
+	return hosts.Items(), nil
+}
+
+// matchTargets returns the hosts of every target whose name matches.
+func (i *Inventory) matchTargets(match func(name string) bool) []*Host {
+	hosts := set.NewSet[*Host]()
+	for name, targetHosts := range i.targets {
+		if !match(name) {
+			continue
+		}
+
+		for _, host := range targetHosts {
+			hosts.Add(host)
+		}
+	}
+
+	return hosts.Items()
+}
+
+// splitPattern splits a host pattern into its terms.
+//
+// Commas and colons within brackets, braces or parentheses do not separate terms, so they can be used in regular
+// expressions and globs.
+func splitPattern(pattern string) ([]string, error) {
+	terms := []string{}
+	depth := 0
+	start := 0
+
+	addTerm := func(end int) {
+		term := strings.TrimSpace(pattern[start:end])
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	for index, char := range pattern {
+		switch char {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("the host pattern %q has an unmatched %q", pattern, char)
+			}
+		case ',', ':':
+			if depth == 0 {
+				addTerm(index)
+				start = index + 1
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("the host pattern %q has an unclosed bracket", pattern)
+	}
+
+	addTerm(len(pattern))
+
+	return terms, nil
+}
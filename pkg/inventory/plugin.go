@@ -0,0 +1,666 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/trippsoft/forge/pkg/hclutil"
+	"github.com/trippsoft/forge/pkg/plugin"
+	"github.com/trippsoft/forge/pkg/transport"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// PluginFormatProtobuf is the format of an inventory plugin that writes a PluginInventory message to its standard
+	// output, framed as by plugin.Write.
+	PluginFormatProtobuf = "protobuf"
+
+	// PluginFormatJSON is the format of an inventory plugin that writes a PluginInventory message to its standard
+	// output as JSON.
+	PluginFormatJSON = "json"
+
+	// DefaultPluginTimeout is the default maximum duration of the run of an inventory plugin.
+	DefaultPluginTimeout = time.Minute
+)
+
+// inventoryPlugin is an executable that is run to generate part of the inventory.
+//
+// This represents a plugin block within an inventory file.
+type inventoryPlugin struct {
+	name    string
+	command string
+	args    []string
+	format  string
+	timeout time.Duration
+
+	hclRange *hcl.Range
+}
+
+func parsePluginBlocks(blocks []*hcl.Block) ([]*inventoryPlugin, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	plugins := make([]*inventoryPlugin, 0, len(blocks))
+	for _, block := range blocks {
+		p, moreDiags := parsePluginBlock(block)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		if slices.ContainsFunc(plugins, func(other *inventoryPlugin) bool { return other.name == p.name }) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate plugin name",
+				Detail: fmt.Sprintf(
+					"Plugin '%s' is defined multiple times in the inventory. Each plugin must have a unique name.",
+					p.name,
+				),
+				Subject: p.hclRange,
+			})
+			continue
+		}
+
+		plugins = append(plugins, p)
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return plugins, diags
+}
+
+func parsePluginBlock(block *hcl.Block) (*inventoryPlugin, hcl.Diagnostics) {
+	if block.Labels[0] == "" {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Empty plugin name",
+			Detail:   "The plugin name cannot be empty.",
+			Subject:  &block.DefRange,
+		}}
+	}
+
+	diags := hcl.Diagnostics{}
+	content, moreDiags := block.Body.Content(pluginBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a plugin block")
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	p := &inventoryPlugin{
+		name:     block.Labels[0],
+		format:   PluginFormatProtobuf,
+		timeout:  DefaultPluginTimeout,
+		hclRange: &block.DefRange,
+	}
+
+	for name, attr := range content.Attributes {
+		switch name {
+		case "command":
+			command, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			if command == "" {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Empty plugin command",
+					Detail:   fmt.Sprintf("The 'command' attribute of plugin '%s' cannot be empty.", p.name),
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			p.command = command
+
+		case "args":
+			value, moreDiags := attr.Expr.Value(nil)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			args, err := convert.Convert(value, cty.List(cty.String))
+			if err != nil || args.IsNull() || !args.IsWhollyKnown() {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid plugin args",
+					Detail:   fmt.Sprintf("The 'args' attribute of plugin '%s' must be a list of strings.", p.name),
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			for _, arg := range args.AsValueSlice() {
+				p.args = append(p.args, arg.AsString())
+			}
+
+		case "format":
+			format, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			if format != PluginFormatProtobuf && format != PluginFormatJSON {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid plugin format",
+					Detail: fmt.Sprintf(
+						"The format %q of plugin '%s' is not supported. Allowed formats are: %q, %q",
+						format,
+						p.name,
+						PluginFormatProtobuf,
+						PluginFormatJSON,
+					),
+					Subject: &attr.Range,
+				})
+				continue
+			}
+
+			p.format = format
+
+		case "timeout":
+			timeout, moreDiags := hclutil.ConvertHCLAttributeToDuration(attr, nil)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			if timeout <= 0 {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid plugin timeout",
+					Detail:   fmt.Sprintf("The 'timeout' attribute of plugin '%s' must be positive.", p.name),
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			p.timeout = timeout
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return p, diags
+}
+
+// load runs the plugin and merges the inventory it returns into the intermediate inventory.
+func (p *inventoryPlugin) load(intermediate *intermediateInventory) hcl.Diagnostics {
+	output, diags := p.run()
+	if diags.HasErrors() {
+		return diags
+	}
+
+	pluginIntermediate, moreDiags := p.toIntermediate(output)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	return diags.Extend(mergeIntermediateInventory(intermediate, pluginIntermediate))
+}
+
+// run runs the plugin's command in the directory of the inventory file that defines it and decodes its output.
+//
+// A relative command that contains a path separator is relative to that directory, while a bare command name is
+// looked up in the PATH.
+func (p *inventoryPlugin) run() (*PluginInventory, hcl.Diagnostics) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Dir = filepath.Dir(p.hclRange.Filename)
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Inventory plugin timed out",
+			Detail:   fmt.Sprintf("The plugin '%s' did not finish within %s.", p.name, p.timeout),
+			Subject:  p.hclRange,
+		}}
+	}
+
+	if err != nil {
+		detail := fmt.Sprintf("The plugin '%s' failed: %s", p.name, err.Error())
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			detail = fmt.Sprintf("%s\n%s", detail, message)
+		}
+
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Inventory plugin failed",
+			Detail:   detail,
+			Subject:  p.hclRange,
+		}}
+	}
+
+	output := &PluginInventory{}
+	if p.format == PluginFormatJSON {
+		err = protojson.Unmarshal(stdout.Bytes(), output)
+	} else {
+		err = plugin.Read(stdout, output)
+	}
+
+	if err != nil {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid inventory plugin output",
+			Detail:   fmt.Sprintf("The output of plugin '%s' is not a valid %s inventory: %s", p.name, p.format, err),
+			Subject:  p.hclRange,
+		}}
+	}
+
+	return output, hcl.Diagnostics{}
+}
+
+// toIntermediate converts the inventory returned by the plugin to an intermediate inventory.
+//
+// Every element of the intermediate inventory refers to the range of the plugin block, so diagnostics of later stages
+// point at the plugin that returned it.
+func (p *inventoryPlugin) toIntermediate(output *PluginInventory) (*intermediateInventory, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	intermediate := &intermediateInventory{
+		groups: make(map[string]*intermediateGroup, len(output.Groups)),
+		hosts:  make(map[string]*intermediateHost, len(output.Hosts)),
+	}
+
+	vars, moreDiags := p.varsToIntermediate(output.Vars)
+	diags = diags.Extend(moreDiags)
+	intermediate.vars = vars
+
+	transport, moreDiags := p.transportToIntermediate(output.Transport)
+	diags = diags.Extend(moreDiags)
+	intermediate.transport = transport
+	intermediate.escalate = p.escalateToIntermediate(output.Escalate)
+
+	for name, g := range output.Groups {
+		if name == "" {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Empty group name",
+				Detail:   fmt.Sprintf("The plugin '%s' returned a group with an empty name.", p.name),
+				Subject:  p.hclRange,
+			})
+			continue
+		}
+
+		group := &intermediateGroup{
+			name:     name,
+			parent:   g.GetParent(),
+			escalate: p.escalateToIntermediate(g.GetEscalate()),
+			hclRange: p.hclRange,
+		}
+
+		group.vars, moreDiags = p.varsToIntermediate(g.GetVars())
+		diags = diags.Extend(moreDiags)
+
+		group.transport, moreDiags = p.transportToIntermediate(g.GetTransport())
+		diags = diags.Extend(moreDiags)
+
+		intermediate.groups[name] = group
+	}
+
+	for name, h := range output.Hosts {
+		if name == "" {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Empty host name",
+				Detail:   fmt.Sprintf("The plugin '%s' returned a host with an empty name.", p.name),
+				Subject:  p.hclRange,
+			})
+			continue
+		}
+
+		host := &intermediateHost{
+			name:      name,
+			escalate:  p.escalateToIntermediate(h.GetEscalate()),
+			allGroups: []string{},
+			hclRange:  p.hclRange,
+		}
+
+		host.vars, moreDiags = p.varsToIntermediate(h.GetVars())
+		diags = diags.Extend(moreDiags)
+
+		host.transport, moreDiags = p.transportToIntermediate(h.GetTransport())
+		diags = diags.Extend(moreDiags)
+
+		for _, groupName := range h.GetGroups() {
+			if groupName == "" {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Empty group reference",
+					Detail: fmt.Sprintf(
+						"The groups of host %q returned by plugin '%s' contain an empty string. "+
+							"Each group reference must be a non-empty string.",
+						name,
+						p.name,
+					),
+					Subject: p.hclRange,
+				})
+				continue
+			}
+
+			host.groups = append(host.groups, groupName)
+		}
+
+		intermediate.hosts[name] = host
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return intermediate, diags
+}
+
+func (p *inventoryPlugin) varsToIntermediate(
+	vars map[string]*structpb.Value,
+) (map[string]*hcl.Attribute, hcl.Diagnostics) {
+
+	diags := hcl.Diagnostics{}
+	attributes := make(map[string]*hcl.Attribute, len(vars))
+	for name, value := range vars {
+		if !hclsyntax.ValidIdentifier(name) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable name",
+				Detail:   fmt.Sprintf("The plugin '%s' returned the variable %q, which is not a valid name.", p.name, name),
+				Subject:  p.hclRange,
+			})
+			continue
+		}
+
+		attr, moreDiags := p.attribute(name, value)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		attributes[name] = attr
+	}
+
+	return attributes, diags
+}
+
+func (p *inventoryPlugin) transportToIntermediate(t *PluginTransport) (*intermediateTransport, hcl.Diagnostics) {
+	if t == nil {
+		return nil, hcl.Diagnostics{}
+	}
+
+	var schema *hcl.BodySchema
+	switch t.GetType() {
+	case string(transport.TransportTypeLocal):
+		schema = transportLocalSchema
+	case string(transport.TransportTypeSSH):
+		schema = transportSSHSchema
+	default:
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid transport type",
+			Detail: fmt.Sprintf(
+				"The transport type %q returned by plugin '%s' is not supported. Allowed types are: %q, %q",
+				t.GetType(),
+				p.name,
+				transport.TransportTypeLocal,
+				transport.TransportTypeSSH,
+			),
+			Subject: p.hclRange,
+		}}
+	}
+
+	diags := hcl.Diagnostics{}
+	intermediate := &intermediateTransport{
+		name:     t.GetType(),
+		config:   make(map[string]*hcl.Attribute, len(t.GetConfig())),
+		hclRange: p.hclRange,
+	}
+
+	for name, value := range t.GetConfig() {
+		supported := slices.ContainsFunc(schema.Attributes, func(attr hcl.AttributeSchema) bool {
+			return attr.Name == name
+		})
+
+		if !supported {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported argument",
+				Detail: fmt.Sprintf(
+					"An argument named %q is not expected in a transport %q returned by plugin '%s'.",
+					name,
+					t.GetType(),
+					p.name,
+				),
+				Subject: p.hclRange,
+			})
+			continue
+		}
+
+		attr, moreDiags := p.attribute(name, value)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		intermediate.config[name] = attr
+	}
+
+	return intermediate, diags
+}
+
+func (p *inventoryPlugin) escalateToIntermediate(e *PluginEscalate) *intermediateEscalate {
+	if e == nil {
+		return nil
+	}
+
+	escalate := &intermediateEscalate{}
+	if e.GetPassword() != "" {
+		escalate.password, _ = p.attribute("password", structpb.NewStringValue(e.GetPassword()))
+	}
+
+	return escalate
+}
+
+// attribute converts a value returned by the plugin to an attribute with a static expression.
+func (p *inventoryPlugin) attribute(name string, value *structpb.Value) (*hcl.Attribute, hcl.Diagnostics) {
+	ctyValue := cty.NullVal(cty.DynamicPseudoType)
+	if value != nil {
+		content, err := protojson.Marshal(value)
+		if err == nil {
+			var ctyType cty.Type
+			ctyType, err = ctyjson.ImpliedType(content)
+			if err == nil {
+				ctyValue, err = ctyjson.Unmarshal(content, ctyType)
+			}
+		}
+
+		if err != nil {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid inventory plugin value",
+				Detail:   fmt.Sprintf("The value of %q returned by plugin '%s' is not valid: %s", name, p.name, err),
+				Subject:  p.hclRange,
+			}}
+		}
+	}
+
+	return &hcl.Attribute{
+		Name:      name,
+		Expr:      hcl.StaticExpr(ctyValue, *p.hclRange),
+		Range:     *p.hclRange,
+		NameRange: *p.hclRange,
+	}, hcl.Diagnostics{}
+}
+
+// mergeIntermediateInventory merges the source intermediate inventory into the destination.
+//
+// Hosts, groups, variables and transport attributes that are defined by both are reported as duplicates.
+func mergeIntermediateInventory(dst, src *intermediateInventory) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if dst.vars == nil {
+		dst.vars = make(map[string]*hcl.Attribute, len(src.vars))
+	}
+
+	for name, attr := range src.vars {
+		if _, exists := dst.vars[name]; exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate variable name",
+				Detail: fmt.Sprintf(
+					"Variable '%s' is defined multiple times in the inventory. Each variable must have a unique name.",
+					name,
+				),
+				Subject: &attr.Range,
+			})
+			continue
+		}
+
+		dst.vars[name] = attr
+	}
+
+	transport, moreDiags := mergeIntermediateTransports(dst.transport, src.transport)
+	diags = diags.Extend(moreDiags)
+	dst.transport = transport
+
+	escalate, moreDiags := mergeIntermediateEscalates(dst.escalate, src.escalate)
+	diags = diags.Extend(moreDiags)
+	dst.escalate = escalate
+
+	if dst.groups == nil {
+		dst.groups = make(map[string]*intermediateGroup, len(src.groups))
+	}
+
+	for name, group := range src.groups {
+		if _, exists := dst.groups[name]; exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate group name",
+				Detail: fmt.Sprintf(
+					"Group '%s' is defined multiple times in the inventory. Each group must have a unique name.",
+					name,
+				),
+				Subject: group.hclRange,
+			})
+			continue
+		}
+
+		dst.groups[name] = group
+	}
+
+	if dst.hosts == nil {
+		dst.hosts = make(map[string]*intermediateHost, len(src.hosts))
+	}
+
+	for name, host := range src.hosts {
+		if _, exists := dst.hosts[name]; exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate host name",
+				Detail: fmt.Sprintf(
+					"Host '%s' is defined multiple times in the inventory. Each host must have a unique name.",
+					name,
+				),
+				Subject: host.hclRange,
+			})
+			continue
+		}
+
+		dst.hosts[name] = host
+	}
+
+	return diags
+}
+
+func mergeIntermediateTransports(dst, src *intermediateTransport) (*intermediateTransport, hcl.Diagnostics) {
+	if dst == nil {
+		return src, hcl.Diagnostics{}
+	}
+
+	if src == nil {
+		return dst, hcl.Diagnostics{}
+	}
+
+	if dst.name != src.name {
+		return dst, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Multiple transport blocks with different types",
+			Detail: fmt.Sprintf(
+				"Found multiple transports with different types: '%s' and '%s'. Only one transport type is allowed.",
+				dst.name,
+				src.name,
+			),
+			Subject: src.hclRange,
+		}}
+	}
+
+	diags := hcl.Diagnostics{}
+	for name, attr := range src.config {
+		if _, exists := dst.config[name]; exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate transport configuration",
+				Detail: fmt.Sprintf(
+					"The attribute '%s' is defined multiple times in the transport. Each attribute must have a "+
+						"unique name.",
+					name,
+				),
+				Subject: &attr.Range,
+			})
+			continue
+		}
+
+		dst.config[name] = attr
+	}
+
+	return dst, diags
+}
+
+func mergeIntermediateEscalates(dst, src *intermediateEscalate) (*intermediateEscalate, hcl.Diagnostics) {
+	if dst == nil {
+		return src, hcl.Diagnostics{}
+	}
+
+	if src == nil || src.password == nil {
+		return dst, hcl.Diagnostics{}
+	}
+
+	if dst.password != nil {
+		return dst, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Duplicate escalate configuration",
+			Detail:   "The attribute 'password' is defined multiple times in the escalate configuration.",
+			Subject:  &src.password.Range,
+		}}
+	}
+
+	dst.password = src.password
+	return dst, hcl.Diagnostics{}
+}
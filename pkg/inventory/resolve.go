@@ -907,6 +907,15 @@ func buildFinalInventory(
 	diags := hcl.Diagnostics{}
 	inventory := NewInventory(map[string]*Host{}, map[string][]*Host{}, map[string][]*Host{})
 	inventory.targets["all"] = make([]*Host, 0, len(intermediate.hosts))
+	for groupName, group := range intermediate.groups {
+		parent := group.parent
+		if parent == "all" {
+			parent = ""
+		}
+
+		inventory.parents[groupName] = parent
+	}
+
 	for hostName, intermediateHost := range intermediate.hosts {
 		vars, exists := hostVars[hostName]
 		if !exists {
@@ -929,6 +938,7 @@ func buildFinalInventory(
 
 		builder := NewHostBuilder().
 			WithName(hostName).
+			WithGroups(intermediateHost.groups).
 			WithTransport(t).
 			WithEscalateConfig(escalateConfig).
 			WithVars(vars)
@@ -44,6 +44,10 @@ var (
 				Type:       "host",
 				LabelNames: []string{"name"},
 			},
+			{
+				Type:       "plugin",
+				LabelNames: []string{"name"},
+			},
 		},
 		Attributes: []hcl.AttributeSchema{},
 	}
@@ -105,6 +109,26 @@ var (
 			},
 		},
 	}
+	pluginBlockSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "command",
+				Required: true,
+			},
+			{
+				Name:     "args",
+				Required: false,
+			},
+			{
+				Name:     "format",
+				Required: false,
+			},
+			{
+				Name:     "timeout",
+				Required: false,
+			},
+		},
+	}
 	groupBlockSchema = &hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
 			{
@@ -147,6 +171,26 @@ var (
 				Name:     "groups",
 				Required: false,
 			},
+			{
+				Name:     "for_each",
+				Required: false,
+			},
+			{
+				Name:     "name",
+				Required: false,
+			},
+		},
+	}
+	hostForEachSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "for_each",
+				Required: false,
+			},
+			{
+				Name:     "name",
+				Required: false,
+			},
 		},
 	}
 )
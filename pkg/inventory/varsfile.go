@@ -0,0 +1,157 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package inventory
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+const (
+	hostVarsDirName  = "host_vars"
+	groupVarsDirName = "group_vars"
+
+	// allGroupName is the name of the group that contains every host.
+	//
+	// The vars of its group_vars file apply to the whole inventory.
+	allGroupName = "all"
+)
+
+// varsFile is a host_vars or group_vars file, which contains the vars of a host or group.
+type varsFile struct {
+	path  string
+	host  string
+	group string
+
+	vars hcl.Attributes
+}
+
+// varsFileTarget returns the name of the host or group that a file contains vars for, based on its path.
+//
+// A file named host_vars/<host>.<ext>, or any file within a host_vars/<host> directory, contains the vars of the
+// host. Files within group_vars are handled the same way for groups. Both names are empty for other files.
+func varsFileTarget(path string) (host string, group string) {
+	dir, file := filepath.Split(filepath.Clean(path))
+	dir = filepath.Clean(dir)
+
+	name := strings.TrimSuffix(file, filepath.Ext(file))
+	switch filepath.Base(dir) {
+	case hostVarsDirName:
+		return name, ""
+	case groupVarsDirName:
+		return "", name
+	}
+
+	name = filepath.Base(dir)
+	switch filepath.Base(filepath.Dir(dir)) {
+	case hostVarsDirName:
+		return name, ""
+	case groupVarsDirName:
+		return "", name
+	}
+
+	return "", ""
+}
+
+// parseVarsFile parses the vars of a host_vars or group_vars file.
+//
+// Unlike an inventory file, the vars are defined at the top level of the file rather than within a vars block.
+func parseVarsFile(file *InventoryFile, hclFile *hcl.File) (*varsFile, hcl.Diagnostics) {
+	attributes, diags := hclFile.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	v := &varsFile{
+		path:  file.Path,
+		host:  file.HostVars,
+		group: file.GroupVars,
+		vars:  attributes,
+	}
+
+	return v, diags
+}
+
+// applyVarsFiles applies the vars of host_vars and group_vars files to the hosts and groups of the inventory.
+//
+// The vars of a file take precedence over the vars of the host or group block it is for, and files are applied in
+// the order they were discovered, so a var in a later file takes precedence over the same var in an earlier one.
+// The vars of group_vars/all apply to the whole inventory, like a top-level vars block.
+// Vars files for a host or group that is not defined in the inventory are reported as errors.
+func applyVarsFiles(intermediate *intermediateInventory, files []*varsFile) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	for _, file := range files {
+		var vars map[string]*hcl.Attribute
+		switch {
+		case file.host != "":
+			host, exists := intermediate.hosts[file.host]
+			if !exists {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Vars for undefined host",
+					Detail: fmt.Sprintf(
+						"The vars file %q contains vars for host '%s', which is not defined in the inventory.",
+						file.path,
+						file.host,
+					),
+					Subject: file.subject(),
+				})
+				continue
+			}
+
+			if host.vars == nil {
+				host.vars = make(map[string]*hcl.Attribute)
+			}
+
+			vars = host.vars
+
+		case file.group == allGroupName:
+			if intermediate.vars == nil {
+				intermediate.vars = make(map[string]*hcl.Attribute)
+			}
+
+			vars = intermediate.vars
+
+		default:
+			group, exists := intermediate.groups[file.group]
+			if !exists {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Vars for undefined group",
+					Detail: fmt.Sprintf(
+						"The vars file %q contains vars for group '%s', which is not defined in the inventory.",
+						file.path,
+						file.group,
+					),
+					Subject: file.subject(),
+				})
+				continue
+			}
+
+			if group.vars == nil {
+				group.vars = make(map[string]*hcl.Attribute)
+			}
+
+			vars = group.vars
+		}
+
+		for name, attr := range file.vars {
+			vars[name] = attr
+		}
+	}
+
+	return diags
+}
+
+// subject returns the range of the start of the vars file, for use in diagnostics.
+func (v *varsFile) subject() *hcl.Range {
+	return &hcl.Range{
+		Filename: v.path,
+		Start:    hcl.InitialPos,
+		End:      hcl.InitialPos,
+	}
+}
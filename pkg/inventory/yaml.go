@@ -0,0 +1,653 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package inventory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+var yamlErrorLinePattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlFile holds the information needed to map positions within a YAML inventory file to HCL ranges.
+type yamlFile struct {
+	filename   string
+	content    []byte
+	lineStarts []int
+}
+
+// parseYAML parses the content of a YAML inventory file.
+//
+// The YAML document is mapped onto the same structure as the JSON syntax of an inventory file. Block types are keys
+// whose values are mappings, nested once per block label, and strings are evaluated as HCL templates.
+// A file that contains multiple YAML documents is treated as though each document were a separate file.
+func parseYAML(content []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	f := &yamlFile{
+		filename:   filename,
+		content:    content,
+		lineStarts: []int{0},
+	}
+
+	for i, b := range content {
+		if b == '\n' {
+			f.lineStarts = append(f.lineStarts, i+1)
+		}
+	}
+
+	diags := hcl.Diagnostics{}
+	bodies := []hcl.Body{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		document := &yaml.Node{}
+		err := decoder.Decode(document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			diags = diags.Append(f.errorDiagnostic(err))
+			return nil, diags
+		}
+
+		root := resolveYAMLAlias(document.Content[0])
+		if root.Kind == yaml.ScalarNode && root.ShortTag() == "!!null" {
+			continue
+		}
+
+		if root.Kind != yaml.MappingNode {
+			r := f.rangeOf(root)
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid YAML inventory",
+				Detail:   "The root of a YAML inventory document must be a mapping.",
+				Subject:  &r,
+			})
+			continue
+		}
+
+		bodies = append(bodies, &yamlBody{file: f, node: root})
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	file := &hcl.File{
+		Body:  hcl.MergeBodies(bodies),
+		Bytes: content,
+	}
+
+	return file, diags
+}
+
+// errorDiagnostic converts an error returned by the YAML decoder into a diagnostic.
+func (f *yamlFile) errorDiagnostic(err error) *hcl.Diagnostic {
+	diag := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid YAML syntax",
+		Detail:   strings.TrimPrefix(err.Error(), "yaml: "),
+	}
+
+	matches := yamlErrorLinePattern.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return diag
+	}
+
+	line, _ := strconv.Atoi(matches[1])
+	start := f.pos(line, 1)
+	diag.Detail = matches[2]
+	diag.Subject = &hcl.Range{
+		Filename: f.filename,
+		Start:    start,
+		End:      start,
+	}
+
+	return diag
+}
+
+// pos returns the HCL position of a line and column within the YAML file.
+func (f *yamlFile) pos(line, column int) hcl.Pos {
+	offset := 0
+	if line > 0 && line <= len(f.lineStarts) {
+		offset = f.lineStarts[line-1]
+	}
+
+	return hcl.Pos{
+		Line:   line,
+		Column: column,
+		Byte:   offset + column - 1,
+	}
+}
+
+// valueStart returns the HCL position of the start of the value of a scalar node.
+//
+// The value of a quoted scalar starts after its opening quote, and that of a literal or folded block scalar starts
+// at the indentation of the first non-empty line after its header.
+func (f *yamlFile) valueStart(node *yaml.Node) hcl.Pos {
+	switch {
+	case node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0:
+		return f.pos(node.Line, node.Column+1)
+
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		for line := node.Line + 1; line <= len(f.lineStarts); line++ {
+			text := f.line(line)
+			trimmed := strings.TrimLeft(text, " ")
+			if strings.TrimSpace(trimmed) != "" {
+				return f.pos(line, len(text)-len(trimmed)+1)
+			}
+		}
+	}
+
+	return f.pos(node.Line, node.Column)
+}
+
+// line returns the content of a line within the YAML file, without its line ending.
+func (f *yamlFile) line(line int) string {
+	start := f.lineStarts[line-1]
+	end := len(f.content)
+	if line < len(f.lineStarts) {
+		end = f.lineStarts[line]
+	}
+
+	return strings.TrimRight(string(f.content[start:end]), "\r\n")
+}
+
+// rangeOf returns the HCL range of a YAML node.
+//
+// The range of a scalar covers its value when it fits on a single line. Otherwise, it only covers its start.
+func (f *yamlFile) rangeOf(node *yaml.Node) hcl.Range {
+	start := f.pos(node.Line, node.Column)
+	end := start
+	if node.Kind == yaml.ScalarNode && !strings.Contains(node.Value, "\n") {
+		end = f.pos(node.Line, node.Column+len(node.Value))
+	}
+
+	return hcl.Range{
+		Filename: f.filename,
+		Start:    start,
+		End:      end,
+	}
+}
+
+// yamlPair is a key and value within a YAML mapping.
+type yamlPair struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// yamlPairs returns the key and value pairs of a YAML mapping, with merge keys expanded.
+//
+// Keys defined directly within the mapping take precedence over keys from merged mappings.
+func yamlPairs(node *yaml.Node) []yamlPair {
+	node = resolveYAMLAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	pairs := []yamlPair{}
+	merged := []yamlPair{}
+	defined := map[string]struct{}{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.ShortTag() != "!!merge" {
+			pairs = append(pairs, yamlPair{key: key, value: value})
+			defined[key.Value] = struct{}{}
+			continue
+		}
+
+		value = resolveYAMLAlias(value)
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+
+		for _, source := range sources {
+			merged = append(merged, yamlPairs(source)...)
+		}
+	}
+
+	for _, pair := range merged {
+		if _, exists := defined[pair.key.Value]; exists {
+			continue
+		}
+
+		pairs = append(pairs, pair)
+		defined[pair.key.Value] = struct{}{}
+	}
+
+	return pairs
+}
+
+// resolveYAMLAlias returns the node an alias refers to, or the node itself if it is not an alias.
+func resolveYAMLAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}
+
+// yamlBody is an hcl.Body backed by a YAML mapping.
+type yamlBody struct {
+	file   *yamlFile
+	node   *yaml.Node
+	hidden map[string]struct{}
+}
+
+// Content implements hcl.Body.
+func (b *yamlBody) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	content, remain, diags := b.PartialContent(schema)
+	hidden := remain.(*yamlBody).hidden
+	for _, pair := range yamlPairs(b.node) {
+		if _, exists := hidden[pair.key.Value]; exists {
+			continue
+		}
+
+		r := b.file.rangeOf(pair.key)
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported argument",
+			Detail:   fmt.Sprintf("An argument named %q is not expected here.", pair.key.Value),
+			Subject:  &r,
+		})
+	}
+
+	return content, diags
+}
+
+// PartialContent implements hcl.Body.
+func (b *yamlBody) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	content := &hcl.BodyContent{
+		Attributes:       hcl.Attributes{},
+		MissingItemRange: b.MissingItemRange(),
+	}
+
+	hidden := make(map[string]struct{}, len(b.hidden))
+	for name := range b.hidden {
+		hidden[name] = struct{}{}
+	}
+
+	attributeSchemas := make(map[string]hcl.AttributeSchema, len(schema.Attributes))
+	for _, attributeSchema := range schema.Attributes {
+		attributeSchemas[attributeSchema.Name] = attributeSchema
+	}
+
+	blockSchemas := make(map[string]hcl.BlockHeaderSchema, len(schema.Blocks))
+	for _, blockSchema := range schema.Blocks {
+		blockSchemas[blockSchema.Type] = blockSchema
+	}
+
+	for _, pair := range yamlPairs(b.node) {
+		name := pair.key.Value
+		if _, exists := b.hidden[name]; exists {
+			continue
+		}
+
+		if _, exists := attributeSchemas[name]; exists {
+			hidden[name] = struct{}{}
+			if existing, exists := content.Attributes[name]; exists {
+				diags = diags.Append(b.duplicateAttributeDiagnostic(pair, existing))
+				continue
+			}
+
+			content.Attributes[name] = b.attribute(pair)
+			continue
+		}
+
+		if blockSchema, exists := blockSchemas[name]; exists {
+			hidden[name] = struct{}{}
+			blocks, moreDiags := b.blocks(pair.value, blockSchema, pair.key, []string{}, []hcl.Range{})
+			diags = diags.Extend(moreDiags)
+			content.Blocks = append(content.Blocks, blocks...)
+		}
+	}
+
+	for _, attributeSchema := range schema.Attributes {
+		if !attributeSchema.Required {
+			continue
+		}
+
+		if _, exists := content.Attributes[attributeSchema.Name]; exists {
+			continue
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required argument",
+			Detail: fmt.Sprintf(
+				"The argument %q is required, but no definition was found.",
+				attributeSchema.Name,
+			),
+			Subject: &content.MissingItemRange,
+		})
+	}
+
+	remain := &yamlBody{
+		file:   b.file,
+		node:   b.node,
+		hidden: hidden,
+	}
+
+	return content, remain, diags
+}
+
+// JustAttributes implements hcl.Body.
+func (b *yamlBody) JustAttributes() (hcl.Attributes, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	attributes := hcl.Attributes{}
+	for _, pair := range yamlPairs(b.node) {
+		name := pair.key.Value
+		if _, exists := b.hidden[name]; exists {
+			continue
+		}
+
+		if existing, exists := attributes[name]; exists {
+			diags = diags.Append(b.duplicateAttributeDiagnostic(pair, existing))
+			continue
+		}
+
+		attributes[name] = b.attribute(pair)
+	}
+
+	return attributes, diags
+}
+
+// MissingItemRange implements hcl.Body.
+func (b *yamlBody) MissingItemRange() hcl.Range {
+	r := b.file.rangeOf(b.node)
+	r.End = r.Start
+	return r
+}
+
+// attribute returns the HCL attribute for a key and value within the YAML mapping.
+func (b *yamlBody) attribute(pair yamlPair) *hcl.Attribute {
+	nameRange := b.file.rangeOf(pair.key)
+	return &hcl.Attribute{
+		Name: pair.key.Value,
+		Expr: &yamlExpr{
+			file: b.file,
+			node: pair.value,
+		},
+		Range:     hcl.RangeBetween(nameRange, b.file.rangeOf(pair.value)),
+		NameRange: nameRange,
+	}
+}
+
+// duplicateAttributeDiagnostic returns the diagnostic for a key that is defined multiple times in the YAML mapping.
+func (b *yamlBody) duplicateAttributeDiagnostic(pair yamlPair, existing *hcl.Attribute) *hcl.Diagnostic {
+	r := b.file.rangeOf(pair.key)
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Duplicate argument",
+		Detail: fmt.Sprintf(
+			"The argument %q was already set at %s. Each argument may be set only once.",
+			pair.key.Value,
+			existing.NameRange.String(),
+		),
+		Subject: &r,
+	}
+}
+
+// blocks returns the HCL blocks defined by the value of a block type key within the YAML mapping.
+//
+// Each label of the block is a key of a nested mapping. The innermost value is either the mapping that forms the
+// body of the block, a sequence of such mappings, or null for an empty block.
+func (b *yamlBody) blocks(
+	node *yaml.Node,
+	schema hcl.BlockHeaderSchema,
+	typeKey *yaml.Node,
+	labels []string,
+	labelRanges []hcl.Range,
+) (hcl.Blocks, hcl.Diagnostics) {
+
+	diags := hcl.Diagnostics{}
+	node = resolveYAMLAlias(node)
+	isNull := node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+
+	if len(labels) < len(schema.LabelNames) {
+		if node.Kind != yaml.MappingNode {
+			r := b.file.rangeOf(node)
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Incorrect YAML value type",
+				Detail: fmt.Sprintf(
+					"A mapping is required here, with keys giving the %s of each %s block.",
+					schema.LabelNames[len(labels)],
+					schema.Type,
+				),
+				Subject: &r,
+			})
+			return nil, diags
+		}
+
+		blocks := hcl.Blocks{}
+		for _, pair := range yamlPairs(node) {
+			moreBlocks, moreDiags := b.blocks(
+				pair.value,
+				schema,
+				typeKey,
+				append(labels[:len(labels):len(labels)], pair.key.Value),
+				append(labelRanges[:len(labelRanges):len(labelRanges)], b.file.rangeOf(pair.key)),
+			)
+			diags = diags.Extend(moreDiags)
+			blocks = append(blocks, moreBlocks...)
+		}
+
+		return blocks, diags
+	}
+
+	bodies := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		bodies = node.Content
+	}
+
+	blocks := hcl.Blocks{}
+	for _, body := range bodies {
+		body = resolveYAMLAlias(body)
+		if body.Kind != yaml.MappingNode && !isNull {
+			r := b.file.rangeOf(body)
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Incorrect YAML value type",
+				Detail:   fmt.Sprintf("A mapping is required here, giving the body of the %s block.", schema.Type),
+				Subject:  &r,
+			})
+			continue
+		}
+
+		typeRange := b.file.rangeOf(typeKey)
+		defRange := typeRange
+		if len(labelRanges) > 0 {
+			defRange = labelRanges[len(labelRanges)-1]
+		}
+
+		blocks = append(blocks, &hcl.Block{
+			Type:   schema.Type,
+			Labels: labels,
+			Body: &yamlBody{
+				file: b.file,
+				node: body,
+			},
+			DefRange:    defRange,
+			TypeRange:   typeRange,
+			LabelRanges: labelRanges,
+		})
+	}
+
+	return blocks, diags
+}
+
+// yamlExpr is an hcl.Expression backed by a YAML value.
+//
+// Strings are evaluated as HCL templates, sequences as tuples, and mappings as objects.
+type yamlExpr struct {
+	file *yamlFile
+	node *yaml.Node
+}
+
+// Value implements hcl.Expression.
+func (e *yamlExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	node := resolveYAMLAlias(e.node)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		diags := hcl.Diagnostics{}
+		if len(node.Content) == 0 {
+			return cty.EmptyTupleVal, diags
+		}
+
+		values := make([]cty.Value, 0, len(node.Content))
+		for _, element := range node.Content {
+			value, moreDiags := (&yamlExpr{file: e.file, node: element}).Value(ctx)
+			diags = diags.Extend(moreDiags)
+			values = append(values, value)
+		}
+
+		return cty.TupleVal(values), diags
+
+	case yaml.MappingNode:
+		diags := hcl.Diagnostics{}
+		pairs := yamlPairs(node)
+		if len(pairs) == 0 {
+			return cty.EmptyObjectVal, diags
+		}
+
+		values := make(map[string]cty.Value, len(pairs))
+		for _, pair := range pairs {
+			value, moreDiags := (&yamlExpr{file: e.file, node: pair.value}).Value(ctx)
+			diags = diags.Extend(moreDiags)
+			values[pair.key.Value] = value
+		}
+
+		return cty.ObjectVal(values), diags
+
+	case yaml.ScalarNode:
+		return e.scalarValue(node, ctx)
+	}
+
+	r := e.Range()
+	return cty.DynamicVal, hcl.Diagnostics{
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported YAML value",
+			Detail:   "Only scalars, sequences, and mappings are supported in a YAML inventory.",
+			Subject:  &r,
+		},
+	}
+}
+
+// scalarValue returns the value of a YAML scalar.
+func (e *yamlExpr) scalarValue(node *yaml.Node, ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	switch node.ShortTag() {
+	case "!!null":
+		return cty.NullVal(cty.DynamicPseudoType), diags
+
+	case "!!bool":
+		var value bool
+		if err := node.Decode(&value); err == nil {
+			return cty.BoolVal(value), diags
+		}
+
+	case "!!int":
+		var value int64
+		if err := node.Decode(&value); err == nil {
+			return cty.NumberIntVal(value), diags
+		}
+
+		if value, err := cty.ParseNumberVal(node.Value); err == nil {
+			return value, diags
+		}
+
+	case "!!float":
+		var value float64
+		if err := node.Decode(&value); err == nil {
+			return cty.NumberFloatVal(value), diags
+		}
+
+	default:
+		template, moreDiags := e.template(node)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return cty.DynamicVal, diags
+		}
+
+		value, moreDiags := template.Value(ctx)
+		diags = diags.Extend(moreDiags)
+		return value, diags
+	}
+
+	r := e.Range()
+	diags = diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid YAML value",
+		Detail:   fmt.Sprintf("The value %q cannot be decoded as %s.", node.Value, node.ShortTag()),
+		Subject:  &r,
+	})
+
+	return cty.DynamicVal, diags
+}
+
+// template parses a YAML string as an HCL template.
+func (e *yamlExpr) template(node *yaml.Node) (hclsyntax.Expression, hcl.Diagnostics) {
+	return hclsyntax.ParseTemplate([]byte(node.Value), e.file.filename, e.file.valueStart(node))
+}
+
+// Variables implements hcl.Expression.
+func (e *yamlExpr) Variables() []hcl.Traversal {
+	node := resolveYAMLAlias(e.node)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		variables := []hcl.Traversal{}
+		for _, element := range node.Content {
+			variables = append(variables, (&yamlExpr{file: e.file, node: element}).Variables()...)
+		}
+
+		return variables
+
+	case yaml.MappingNode:
+		variables := []hcl.Traversal{}
+		for _, pair := range yamlPairs(node) {
+			variables = append(variables, (&yamlExpr{file: e.file, node: pair.value}).Variables()...)
+		}
+
+		return variables
+
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!str" {
+			return nil
+		}
+
+		template, diags := e.template(node)
+		if diags.HasErrors() {
+			return nil
+		}
+
+		return template.Variables()
+	}
+
+	return nil
+}
+
+// Range implements hcl.Expression.
+func (e *yamlExpr) Range() hcl.Range {
+	return e.file.rangeOf(resolveYAMLAlias(e.node))
+}
+
+// StartRange implements hcl.Expression.
+func (e *yamlExpr) StartRange() hcl.Range {
+	r := e.Range()
+	r.End = r.Start
+	return r
+}
@@ -22,6 +22,7 @@ var (
 	builtinModules = []Module{
 		assert,
 		message,
+		setVars,
 	}
 )
 
@@ -90,3 +91,51 @@ type Module interface {
 	// Run runs the module with the given context and configuration.
 	Run(ctx context.Context, config *RunConfig) *result.Result
 }
+
+// AsyncModule is implemented by modules that can run detached from their session on the managed host.
+type AsyncModule interface {
+	Module
+
+	// StartAsync starts the module detached on the managed host as the job with the given ID and returns its status.
+	//
+	// The job fails if it does not finish within the timeout.
+	StartAsync(ctx context.Context, config *RunConfig, id string, timeout time.Duration) (*AsyncStatus, error)
+
+	// PollAsync returns the status of the job with the given job file.
+	PollAsync(ctx context.Context, config *RunConfig, jobFile string) (*AsyncStatus, error)
+}
+
+// RuntimeVarsModule is implemented by modules whose output sets runtime variables of the host they run for.
+//
+// Runtime variables are merged into the variables of the host for the later steps and processes of the workflow run.
+type RuntimeVarsModule interface {
+	Module
+
+	// RuntimeVars returns the runtime variables set by the output of a successful run of the module.
+	RuntimeVars(output cty.Value) map[string]cty.Value
+}
+
+// AsyncStatus is the status of a module running detached on the managed host.
+type AsyncStatus struct {
+	// ID is the ID of the job.
+	ID string
+
+	// JobFile is the path of the file on the managed host that holds the status of the job.
+	JobFile string
+
+	// Finished indicates whether the job has finished.
+	Finished bool
+
+	// Result is the result of the module once the job has finished.
+	Result *result.Result
+}
+
+// Output returns the output of a step that started the job without waiting for it to finish.
+func (s *AsyncStatus) Output() cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"job_id":   cty.StringVal(s.ID),
+		"job_file": cty.StringVal(s.JobFile),
+		"started":  cty.True,
+		"finished": cty.BoolVal(s.Finished),
+	})
+}
@@ -5,6 +5,10 @@ package module
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	"github.com/trippsoft/forge/pkg/hclspec"
 	"github.com/trippsoft/forge/pkg/plugin"
@@ -48,38 +52,35 @@ func (m *LocalPluginModule) Run(ctx context.Context, config *RunConfig) *result.
 
 	defer session.Close()
 
-	input := make(map[string][]byte, len(config.Input))
-	for k, v := range config.Input {
-		value, err := json.Marshal(v, cty.DynamicPseudoType)
-		if err != nil {
-			return result.NewFailure(err, err.Error())
-		}
-
-		input[k] = value
-	}
-
-	request := &pluginv1.RunModuleRequest{
-		ModuleName: m.id.moduleName,
-		HostInfo:   config.HostInfo,
-		Input:      input,
-		WhatIf:     config.WhatIf,
-	}
-
-	err = plugin.Write(session.Stdin(), request)
+	request, err := newRunModuleRequest(m.id, config)
 	if err != nil {
-		return result.NewFailure(err, "")
+		return result.NewFailure(err, err.Error())
 	}
 
-	response := &pluginv1.RunModuleResponse{}
-
-	err = plugin.Read(session.Stdout(), response)
+	response, err := runPluginRequest(ctx, session, request)
 	if err != nil {
-		return result.NewFailure(err, "")
+		return sessionFailure(ctx, err)
 	}
 
 	return response.Result.ToResult()
 }
 
+// StartAsync implements AsyncModule.
+func (m *LocalPluginModule) StartAsync(
+	ctx context.Context,
+	config *RunConfig,
+	id string,
+	timeout time.Duration,
+) (*AsyncStatus, error) {
+
+	return startAsync(ctx, transport.LocalTransport, m.basePath, m.id, config, id, timeout)
+}
+
+// PollAsync implements AsyncModule.
+func (m *LocalPluginModule) PollAsync(ctx context.Context, config *RunConfig, jobFile string) (*AsyncStatus, error) {
+	return pollAsync(ctx, transport.LocalTransport, m.basePath, m.id, config, jobFile)
+}
+
 // NewLocalPluginModule creates a new LocalPluginModule.
 func NewLocalPluginModule(basePath string, id *ModuleID, spec *hclspec.Spec) Module {
 	return &LocalPluginModule{
@@ -122,43 +123,200 @@ func (m *RemotePluginModule) Run(ctx context.Context, config *RunConfig) *result
 
 	defer session.Close()
 
+	request, err := newRunModuleRequest(m.id, config)
+	if err != nil {
+		return result.NewFailure(err, err.Error())
+	}
+
+	response, err := runPluginRequest(ctx, session, request)
+	if err != nil {
+		return sessionFailure(ctx, err)
+	}
+
+	return response.Result.ToResult()
+}
+
+// StartAsync implements AsyncModule.
+func (m *RemotePluginModule) StartAsync(
+	ctx context.Context,
+	config *RunConfig,
+	id string,
+	timeout time.Duration,
+) (*AsyncStatus, error) {
+
+	return startAsync(ctx, config.Transport, m.basePath, m.id, config, id, timeout)
+}
+
+// PollAsync implements AsyncModule.
+func (m *RemotePluginModule) PollAsync(ctx context.Context, config *RunConfig, jobFile string) (*AsyncStatus, error) {
+	return pollAsync(ctx, config.Transport, m.basePath, m.id, config, jobFile)
+}
+
+// NewRemotePluginModule creates a new RemotePluginModule.
+func NewRemotePluginModule(basePath string, id *ModuleID, spec *hclspec.Spec) Module {
+	return &RemotePluginModule{
+		basePath: basePath,
+		id:       id,
+		spec:     spec,
+	}
+}
+
+// sessionFailure returns the failure of a plugin session that could not be written to or read from.
+//
+// If the context is done, the session was closed because of it, so the cause of the context is reported with the
+// session error as its detail.
+func sessionFailure(ctx context.Context, err error) *result.Result {
+	if ctx.Err() != nil {
+		return result.NewFailure(context.Cause(ctx), err.Error())
+	}
+
+	return result.NewFailure(err, "")
+}
+
+// newRunModuleRequest creates the request that runs the module with the configuration.
+func newRunModuleRequest(id *ModuleID, config *RunConfig) (*pluginv1.RunModuleRequest, error) {
 	input := make(map[string][]byte, len(config.Input))
 	for k, v := range config.Input {
 		value, err := json.Marshal(v, cty.DynamicPseudoType)
 		if err != nil {
-			return result.NewFailure(err, err.Error())
+			return nil, err
 		}
 
 		input[k] = value
 	}
 
-	request := &pluginv1.RunModuleRequest{
-		ModuleName: m.id.moduleName,
+	return &pluginv1.RunModuleRequest{
+		ModuleName: id.moduleName,
 		HostInfo:   config.HostInfo,
 		Input:      input,
 		WhatIf:     config.WhatIf,
-	}
+	}, nil
+}
 
-	err = plugin.Write(session.Stdin(), request)
+// runPluginRequest writes the request to the plugin session and reads its response.
+func runPluginRequest(
+	ctx context.Context,
+	session plugin.Session,
+	request *pluginv1.RunModuleRequest,
+) (*pluginv1.RunModuleResponse, error) {
+
+	stop := context.AfterFunc(ctx, func() {
+		session.Close()
+	})
+	defer stop()
+
+	err := plugin.Write(session.Stdin(), request)
 	if err != nil {
-		return result.NewFailure(err, "")
+		return nil, err
 	}
 
 	response := &pluginv1.RunModuleResponse{}
 
 	err = plugin.Read(session.Stdout(), response)
 	if err != nil {
-		return result.NewFailure(err, "")
+		return nil, err
 	}
 
-	return response.Result.ToResult()
+	return response, nil
 }
 
-// NewRemotePluginModule creates a new RemotePluginModule.
-func NewRemotePluginModule(basePath string, id *ModuleID, spec *hclspec.Spec) Module {
-	return &RemotePluginModule{
-		basePath: basePath,
-		id:       id,
-		spec:     spec,
+// startAsync starts the module of a plugin detached on the managed host of the transport.
+func startAsync(
+	ctx context.Context,
+	t transport.Transport,
+	basePath string,
+	id *ModuleID,
+	config *RunConfig,
+	jobID string,
+	timeout time.Duration,
+) (*AsyncStatus, error) {
+
+	tempPath, err := t.TempPath()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := newRunModuleRequest(id, config)
+	if err != nil {
+		return nil, err
 	}
+
+	request.Async = &pluginv1.AsyncJob{
+		Id:             jobID,
+		TempPath:       tempPath,
+		TimeoutSeconds: int64(math.Ceil(timeout.Seconds())),
+	}
+
+	return runAsyncRequest(ctx, t, basePath, id, config, request)
+}
+
+// pollAsync returns the status of an async job of a plugin on the managed host of the transport.
+func pollAsync(
+	ctx context.Context,
+	t transport.Transport,
+	basePath string,
+	id *ModuleID,
+	config *RunConfig,
+	jobFile string,
+) (*AsyncStatus, error) {
+
+	request := &pluginv1.RunModuleRequest{
+		ModuleName:  id.moduleName,
+		PollJobFile: jobFile,
+	}
+
+	return runAsyncRequest(ctx, t, basePath, id, config, request)
+}
+
+// runAsyncRequest runs a request that starts or polls an async job and returns the status of the job.
+func runAsyncRequest(
+	ctx context.Context,
+	t transport.Transport,
+	basePath string,
+	id *ModuleID,
+	config *RunConfig,
+	request *pluginv1.RunModuleRequest,
+) (*AsyncStatus, error) {
+
+	session, err := t.StartPluginSession(ctx, basePath, id.namespace, id.pluginName, config.Escalation)
+	if err != nil {
+		return nil, err
+	}
+
+	defer session.Close()
+
+	response, err := runPluginRequest(ctx, session, request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %w", context.Cause(ctx), err)
+		}
+
+		return nil, err
+	}
+
+	if response.AsyncStatus == nil {
+		if response.Result != nil {
+			r := response.Result.ToResult()
+			if r.Error != nil {
+				return nil, r.Error
+			}
+		}
+
+		return nil, errors.New("the plugin did not return the status of the async job")
+	}
+
+	status := &AsyncStatus{
+		ID:       response.AsyncStatus.Id,
+		JobFile:  response.AsyncStatus.JobFile,
+		Finished: response.AsyncStatus.Finished,
+	}
+
+	if status.Finished {
+		status.Result = result.NewFailure(fmt.Errorf("the async job %q finished without a result", status.ID), "")
+		if response.AsyncStatus.Result != nil {
+			status.Result = response.AsyncStatus.Result.ToResult()
+		}
+	}
+
+	return status, nil
 }
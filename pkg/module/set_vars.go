@@ -0,0 +1,78 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/trippsoft/forge/pkg/hclspec"
+	"github.com/trippsoft/forge/pkg/result"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var (
+	setVarsInputSpec = hclspec.NewSpec(hclspec.Object(hclspec.RequiredField("vars", hclspec.Raw)))
+	setVarsID        = NewModuleID("", "", "set_vars")
+
+	setVars Module = &SetVarsModule{}
+)
+
+// SetVarsModule defines the set_vars module that sets runtime variables of the host it runs for.
+//
+// The output of the module is an object of the variables, which the workflow merges into the host's variables.
+type SetVarsModule struct{}
+
+// ID implements Module.
+func (m *SetVarsModule) ID() *ModuleID {
+	return setVarsID
+}
+
+// InputSpec implements Module.
+func (m *SetVarsModule) InputSpec() *hclspec.Spec {
+	return setVarsInputSpec
+}
+
+// Run implements Module.
+func (m *SetVarsModule) Run(ctx context.Context, config *RunConfig) *result.Result {
+	if config == nil {
+		return result.NewFailure(errors.New("config is nil"), "")
+	}
+
+	if config.Input == nil {
+		return result.NewFailure(errors.New("input is nil"), "")
+	}
+
+	vars := config.Input["vars"]
+	if vars.IsNull() || !vars.IsWhollyKnown() {
+		return result.NewFailure(errors.New("vars must be known and not null"), "")
+	}
+
+	varsType := vars.Type()
+	if !varsType.IsObjectType() && !varsType.IsMapType() {
+		return result.NewFailure(fmt.Errorf("vars must be an object, got %s", varsType.FriendlyName()), "")
+	}
+
+	output := make(map[string]cty.Value, vars.LengthInt())
+	for name, value := range vars.AsValueMap() {
+		if !hclsyntax.ValidIdentifier(name) {
+			return result.NewFailure(fmt.Errorf("%q is not a valid variable name", name), "")
+		}
+
+		output[name] = value
+	}
+
+	return result.NewNotChanged(cty.ObjectVal(output))
+}
+
+// RuntimeVars implements RuntimeVarsModule.
+func (m *SetVarsModule) RuntimeVars(output cty.Value) map[string]cty.Value {
+	if output.IsNull() || !output.IsWhollyKnown() || !output.Type().IsObjectType() {
+		return nil
+	}
+
+	return output.AsValueMap()
+}
@@ -0,0 +1,90 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSetVarsModuleInputSpec(t *testing.T) {
+	spec := setVars.InputSpec()
+	if spec == nil {
+		t.Fatal("Expected non-nil input spec from InputSpec(), got nil")
+	}
+
+	err := spec.ValidateSpec()
+	if err != nil {
+		t.Errorf("expected no errors from ValidateSpec(), got: %q", err.Error())
+	}
+}
+
+func TestSetVarsModuleRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     cty.Value
+		expected map[string]cty.Value
+		failed   bool
+	}{
+		{
+			name: "object",
+			vars: cty.ObjectVal(map[string]cty.Value{
+				"release": cty.StringVal("v1.2.0"),
+				"port":    cty.NumberIntVal(8080),
+			}),
+			expected: map[string]cty.Value{
+				"release": cty.StringVal("v1.2.0"),
+				"port":    cty.NumberIntVal(8080),
+			},
+		},
+		{
+			name:     "map",
+			vars:     cty.MapVal(map[string]cty.Value{"release": cty.StringVal("v1.2.0")}),
+			expected: map[string]cty.Value{"release": cty.StringVal("v1.2.0")},
+		},
+		{
+			name:   "not an object",
+			vars:   cty.StringVal("release"),
+			failed: true,
+		},
+		{
+			name:   "invalid name",
+			vars:   cty.ObjectVal(map[string]cty.Value{"my release": cty.StringVal("v1.2.0")}),
+			failed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setVars.Run(context.Background(), &RunConfig{
+				Input: map[string]cty.Value{"vars": tt.vars},
+			})
+
+			if r.Failed != tt.failed {
+				t.Fatalf("expected failed to be %t, got %t: %v", tt.failed, r.Failed, r.Error)
+			}
+
+			if tt.failed {
+				return
+			}
+
+			if r.Changed {
+				t.Error("Expected module to not indicate changes were made")
+			}
+
+			vars := setVars.(RuntimeVarsModule).RuntimeVars(r.Output)
+			if len(vars) != len(tt.expected) {
+				t.Fatalf("expected %d runtime vars, got %d", len(tt.expected), len(vars))
+			}
+
+			for name, expected := range tt.expected {
+				if !vars[name].RawEquals(expected) {
+					t.Errorf("expected runtime var %q to be %#v, got %#v", name, expected, vars[name])
+				}
+			}
+		})
+	}
+}
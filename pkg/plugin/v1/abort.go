@@ -0,0 +1,79 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package pluginv1
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// abortWatcher kills the process tree of the plugin if the controller abandons the session before the module
+// completes.
+//
+// The controller abandons a session by closing it, which closes the standard input of the plugin, or by sending it a
+// termination signal. Killing the process tree ensures commands started by the module do not outlive the session.
+type abortWatcher struct {
+	mutex    sync.Mutex
+	finished bool
+	signals  chan os.Signal
+}
+
+// watchForAbort starts watching for the controller to abandon the session.
+//
+// The process tree is set up first, so the commands started by the module can be killed with the plugin.
+func watchForAbort() *abortWatcher {
+	setupProcessTree()
+
+	w := &abortWatcher{
+		signals: make(chan os.Signal, 1),
+	}
+
+	signal.Notify(w.signals, os.Interrupt, syscall.SIGTERM)
+
+	aborted := make(chan struct{})
+	var once sync.Once
+	abort := func() {
+		once.Do(func() {
+			close(aborted)
+		})
+	}
+
+	go func() {
+		io.Copy(io.Discard, os.Stdin)
+		abort()
+	}()
+
+	go func() {
+		<-w.signals
+		abort()
+	}()
+
+	go func() {
+		<-aborted
+
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+
+		if w.finished {
+			return
+		}
+
+		killProcessTree()
+		os.Exit(1)
+	}()
+
+	return w
+}
+
+// finish stops watching for the controller to abandon the session, because the module completed.
+func (w *abortWatcher) finish() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.finished = true
+	signal.Stop(w.signals)
+}
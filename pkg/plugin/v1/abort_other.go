@@ -0,0 +1,29 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package pluginv1
+
+import (
+	"os"
+	"syscall"
+)
+
+// setupProcessTree makes the plugin the leader of a process group, which the commands it starts inherit.
+//
+// If the plugin is a session leader, it already leads its process group and the call fails harmlessly.
+func setupProcessTree() {
+	syscall.Setpgid(0, 0)
+}
+
+// killProcessTree kills the process group of the plugin, including the plugin itself.
+//
+// If the plugin does not lead its process group, killing the group could kill unrelated processes, so only the plugin
+// exits.
+func killProcessTree() {
+	pid := os.Getpid()
+	if syscall.Getpgrp() == pid {
+		syscall.Kill(-pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+
+package pluginv1
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// job is the job object of the plugin, which the processes it starts inherit.
+var job windows.Handle
+
+// setupProcessTree assigns the plugin to a job object that kills its processes when it is closed.
+//
+// If the job object cannot be created or assigned, only the plugin exits when it is aborted.
+func setupProcessTree() {
+	handle, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return
+	}
+
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE | windows.JOB_OBJECT_LIMIT_BREAKAWAY_OK,
+		},
+	}
+
+	_, err = windows.SetInformationJobObject(
+		handle,
+		windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)),
+		uint32(unsafe.Sizeof(info)),
+	)
+	if err != nil {
+		windows.CloseHandle(handle)
+		return
+	}
+
+	err = windows.AssignProcessToJobObject(handle, windows.CurrentProcess())
+	if err != nil {
+		windows.CloseHandle(handle)
+		return
+	}
+
+	job = handle
+}
+
+// killProcessTree terminates the processes in the job object of the plugin, including the plugin itself.
+func killProcessTree() {
+	if job == 0 {
+		return
+	}
+
+	windows.TerminateJobObject(job, 1)
+}
@@ -0,0 +1,238 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package pluginv1
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/trippsoft/forge/pkg/plugin"
+	"github.com/trippsoft/forge/pkg/result"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// asyncCommand is the argument that starts a plugin as the detached runner of an async job.
+	asyncCommand = "async"
+
+	// asyncJobDirectory is the directory within the temp path that holds the job files of async jobs.
+	asyncJobDirectory = "forge-async"
+)
+
+// ReadAsyncJobStatus reads the status of an async job from its job file.
+//
+// The job file must be within an async job directory. If cleanup is true, the job file is removed once the status
+// reports that the job has finished.
+func ReadAsyncJobStatus(jobFile string, cleanup bool) (*AsyncJobStatus, error) {
+	err := validateAsyncJobFile(jobFile)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(jobFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read async job file %q: %w", jobFile, err)
+	}
+
+	status := &AsyncJobStatus{}
+	err = protojson.Unmarshal(content, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse async job file %q: %w", jobFile, err)
+	}
+
+	if cleanup && status.Finished {
+		err = os.Remove(jobFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove async job file %q: %w", jobFile, err)
+		}
+	}
+
+	return status, nil
+}
+
+// validateAsyncJobFile returns an error if the path is not that of a job file within an async job directory.
+func validateAsyncJobFile(jobFile string) error {
+	path := filepath.Clean(jobFile)
+	if !filepath.IsAbs(path) || filepath.Base(filepath.Dir(path)) != asyncJobDirectory || filepath.Ext(path) != ".json" {
+		return fmt.Errorf("the async job file %q is not within an async job directory", jobFile)
+	}
+
+	return nil
+}
+
+// writeAsyncJobStatus writes the status of an async job to its job file.
+//
+// The status is written to a temporary file that replaces the job file, so readers never see a partial status.
+func writeAsyncJobStatus(status *AsyncJobStatus) error {
+	content, err := protojson.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to serialize async job status: %w", err)
+	}
+
+	tempFile := status.JobFile + ".tmp"
+	err = os.WriteFile(tempFile, content, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write async job file %q: %w", tempFile, err)
+	}
+
+	err = os.Rename(tempFile, status.JobFile)
+	if err != nil {
+		return fmt.Errorf("failed to replace async job file %q: %w", status.JobFile, err)
+	}
+
+	return nil
+}
+
+// handlePollRequest responds with the status of the async job in the job file.
+//
+// The job file is removed once the job has finished.
+func (p *PluginV1) handlePollRequest(jobFile string) error {
+	status, err := ReadAsyncJobStatus(jobFile, true)
+	if err != nil {
+		return plugin.Write(os.Stdout, &RunModuleResponse{Result: NewFailure(err, "")})
+	}
+
+	return plugin.Write(os.Stdout, &RunModuleResponse{AsyncStatus: status})
+}
+
+// startAsyncJob starts a detached copy of the plugin that runs the module of the request and responds with the
+// status of the job.
+//
+// The job file is created before the copy starts, so the job can be polled as soon as the response is read.
+func (p *PluginV1) startAsyncJob(request *RunModuleRequest) error {
+	tempPath := request.Async.TempPath
+	if tempPath == "" {
+		tempPath = os.TempDir()
+	}
+
+	jobDirectory := filepath.Join(tempPath, asyncJobDirectory)
+	err := os.MkdirAll(jobDirectory, 0700)
+	if err != nil {
+		err = fmt.Errorf("failed to create async job directory %q: %w", jobDirectory, err)
+		return plugin.Write(os.Stdout, &RunModuleResponse{Result: NewFailure(err, "")})
+	}
+
+	status := &AsyncJobStatus{
+		Id:       request.Async.Id,
+		JobFile:  filepath.Join(jobDirectory, request.Async.Id+".json"),
+		Finished: false,
+	}
+
+	err = writeAsyncJobStatus(status)
+	if err != nil {
+		return plugin.Write(os.Stdout, &RunModuleResponse{Result: NewFailure(err, "")})
+	}
+
+	err = startDetachedRunner(status.JobFile, request)
+	if err != nil {
+		os.Remove(status.JobFile)
+		err = fmt.Errorf("failed to start async job %q: %w", status.Id, err)
+		return plugin.Write(os.Stdout, &RunModuleResponse{Result: NewFailure(err, "")})
+	}
+
+	return plugin.Write(os.Stdout, &RunModuleResponse{AsyncStatus: status})
+}
+
+// startDetachedRunner starts a copy of the plugin that runs the request detached from the plugin session.
+//
+// The request is written to the standard input of the copy. Each set of process attributes is tried in turn until the
+// copy starts.
+func startDetachedRunner(jobFile string, request *RunModuleRequest) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	for _, attributes := range detachedProcessAttributes() {
+		cmd := exec.Command(executable, asyncCommand, jobFile)
+		cmd.SysProcAttr = attributes
+
+		stdin, e := cmd.StdinPipe()
+		if e != nil {
+			return e
+		}
+
+		e = cmd.Start()
+		if e != nil {
+			err = e
+			continue
+		}
+
+		e = plugin.Write(stdin, request)
+		stdin.Close()
+		if e != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return e
+		}
+
+		return cmd.Process.Release()
+	}
+
+	return err
+}
+
+// handleAsyncJob runs the module of the request on standard input as the detached runner of an async job and writes
+// its result to the job file.
+//
+// If the job does not finish within its timeout, its process tree is killed and the job fails.
+func (p *PluginV1) handleAsyncJob(jobFile string) error {
+	var request RunModuleRequest
+	err := plugin.Read(os.Stdin, &request)
+	if err != nil {
+		return err
+	}
+
+	setupProcessTree()
+
+	status := &AsyncJobStatus{
+		Id:       request.Async.GetId(),
+		JobFile:  jobFile,
+		Finished: false,
+	}
+
+	var mutex sync.Mutex
+	finish := func(r func() *AsyncJobStatus) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if status.Finished {
+			return nil
+		}
+
+		status = r()
+		return writeAsyncJobStatus(status)
+	}
+
+	timeout := time.Duration(request.Async.GetTimeoutSeconds()) * time.Second
+	if timeout > 0 {
+		time.AfterFunc(timeout, func() {
+			finish(func() *AsyncJobStatus {
+				err := fmt.Errorf("the async job did not finish within %s", timeout)
+				return &AsyncJobStatus{Id: status.Id, JobFile: jobFile, Finished: true, Result: NewFailure(err, "")}
+			})
+
+			killProcessTree()
+			os.Exit(1)
+		})
+	}
+
+	var r *result.ModuleResult
+	mod, input, err := p.prepareModule(&request)
+	if err != nil {
+		r = NewFailure(err, "")
+	} else {
+		r = mod.RunModule(request.HostInfo, input, request.WhatIf)
+	}
+
+	return finish(func() *AsyncJobStatus {
+		return &AsyncJobStatus{Id: status.Id, JobFile: jobFile, Finished: true, Result: r}
+	})
+}
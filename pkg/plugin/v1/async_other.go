@@ -0,0 +1,18 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package pluginv1
+
+import "syscall"
+
+// detachedProcessAttributes returns the process attributes that detach the runner of an async job from the plugin
+// session.
+//
+// The runner starts a new session, so it is not killed with the process group of the plugin.
+func detachedProcessAttributes() []*syscall.SysProcAttr {
+	return []*syscall.SysProcAttr{
+		{Setsid: true},
+	}
+}
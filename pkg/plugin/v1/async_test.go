@@ -0,0 +1,96 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package pluginv1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestAsyncJob(t *testing.T, id string, finished bool) string {
+	t.Helper()
+
+	jobDirectory := filepath.Join(t.TempDir(), asyncJobDirectory)
+	err := os.MkdirAll(jobDirectory, 0700)
+	if err != nil {
+		t.Fatalf("failed to create async job directory: %v", err)
+	}
+
+	status := &AsyncJobStatus{
+		Id:       id,
+		JobFile:  filepath.Join(jobDirectory, id+".json"),
+		Finished: finished,
+	}
+
+	err = writeAsyncJobStatus(status)
+	if err != nil {
+		t.Fatalf("failed to write async job status: %v", err)
+	}
+
+	return status.JobFile
+}
+
+func TestReadAsyncJobStatus_Cleanup(t *testing.T) {
+	tests := []struct {
+		name     string
+		finished bool
+		cleanup  bool
+		removed  bool
+	}{
+		{name: "finished with cleanup", finished: true, cleanup: true, removed: true},
+		{name: "finished without cleanup", finished: true, cleanup: false, removed: false},
+		{name: "running with cleanup", finished: false, cleanup: true, removed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobFile := writeTestAsyncJob(t, "job1", tt.finished)
+
+			status, err := ReadAsyncJobStatus(jobFile, tt.cleanup)
+			if err != nil {
+				t.Fatalf("failed to read async job status: %v", err)
+			}
+
+			if status.Id != "job1" || status.Finished != tt.finished {
+				t.Errorf("expected job1 with finished %t, got %q with finished %t", tt.finished, status.Id, status.Finished)
+			}
+
+			_, err = os.Stat(jobFile)
+			if removed := os.IsNotExist(err); removed != tt.removed {
+				t.Errorf("expected the job file to be removed to be %t, got %t", tt.removed, removed)
+			}
+		})
+	}
+}
+
+func TestReadAsyncJobStatus_OutsideJobDirectory(t *testing.T) {
+	jobFile := writeTestAsyncJob(t, "job1", true)
+	jobDirectory := filepath.Dir(jobFile)
+
+	outside := filepath.Join(t.TempDir(), "job1.json")
+	err := os.WriteFile(outside, []byte("{}"), 0600)
+	if err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	paths := []string{
+		outside,
+		filepath.Join(jobDirectory, "..", "job1.json"),
+		filepath.Join(jobDirectory, "job1.txt"),
+		filepath.Join(asyncJobDirectory, "job1.json"),
+	}
+
+	for _, path := range paths {
+		_, err := ReadAsyncJobStatus(path, true)
+		if err == nil {
+			t.Errorf("expected reading %q to be rejected", path)
+		}
+	}
+
+	_, err = os.Stat(outside)
+	if err != nil {
+		t.Errorf("expected the file outside the job directory to be kept: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+
+package pluginv1
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// detachedProcessAttributes returns the process attributes that detach the runner of an async job from the plugin
+// session.
+//
+// The runner breaks away from the job object of the session if the job allows it, so it is not terminated when the
+// session closes. Otherwise, it starts within the job object.
+func detachedProcessAttributes() []*syscall.SysProcAttr {
+	flags := uint32(windows.DETACHED_PROCESS | windows.CREATE_NEW_PROCESS_GROUP)
+	return []*syscall.SysProcAttr{
+		{CreationFlags: flags | windows.CREATE_BREAKAWAY_FROM_JOB},
+		{CreationFlags: flags},
+	}
+}
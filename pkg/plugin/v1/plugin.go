@@ -31,6 +31,10 @@ func (p *PluginV1) Run() error {
 		return p.handleMetadataRequest()
 	}
 
+	if len(os.Args) > 2 && os.Args[1] == asyncCommand {
+		return p.handleAsyncJob(os.Args[2])
+	}
+
 	return p.handleRunModuleRequest()
 }
 
@@ -79,26 +83,46 @@ func (p *PluginV1) handleRunModuleRequest() error {
 		return err
 	}
 
+	if request.PollJobFile != "" {
+		return p.handlePollRequest(request.PollJobFile)
+	}
+
+	mod, input, err := p.prepareModule(&request)
+	if err != nil {
+		return err
+	}
+
+	if request.Async != nil {
+		return p.startAsyncJob(&request)
+	}
+
+	watcher := watchForAbort()
+	r := mod.RunModule(request.HostInfo, input, request.WhatIf)
+	watcher.finish()
+
+	response := &RunModuleResponse{Result: r}
+
+	return plugin.Write(os.Stdout, response)
+}
+
+// prepareModule returns the module named by the request and its decoded input.
+func (p *PluginV1) prepareModule(request *RunModuleRequest) (PluginModule, map[string]cty.Value, error) {
 	mod, ok := p.modules[request.ModuleName]
 	if !ok {
-		return fmt.Errorf("unknown module: %s", request.ModuleName)
+		return nil, nil, fmt.Errorf("unknown module: %s", request.ModuleName)
 	}
 
 	input := make(map[string]cty.Value, len(request.Input))
 	for k, v := range request.Input {
 		val, err := json.Unmarshal(v, cty.DynamicPseudoType)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		input[k] = val
 	}
 
-	r := mod.RunModule(request.HostInfo, input, request.WhatIf)
-
-	response := &RunModuleResponse{Result: r}
-
-	return plugin.Write(os.Stdout, response)
+	return mod, input, nil
 }
 
 // NewPluginV1 creates a new PluginV1 instance with the given namespace, plugin name, and modules.
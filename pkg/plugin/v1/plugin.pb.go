@@ -27,10 +27,12 @@ type RunModuleRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ModuleName string            `protobuf:"bytes,1,opt,name=moduleName,proto3" json:"moduleName,omitempty"`
-	HostInfo   *info.HostInfo    `protobuf:"bytes,2,opt,name=hostInfo,proto3" json:"hostInfo,omitempty"`
-	WhatIf     bool              `protobuf:"varint,3,opt,name=what_if,json=whatIf,proto3" json:"what_if,omitempty"`
-	Input      map[string][]byte `protobuf:"bytes,4,rep,name=input,proto3" json:"input,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ModuleName  string            `protobuf:"bytes,1,opt,name=moduleName,proto3" json:"moduleName,omitempty"`
+	HostInfo    *info.HostInfo    `protobuf:"bytes,2,opt,name=hostInfo,proto3" json:"hostInfo,omitempty"`
+	WhatIf      bool              `protobuf:"varint,3,opt,name=what_if,json=whatIf,proto3" json:"what_if,omitempty"`
+	Input       map[string][]byte `protobuf:"bytes,4,rep,name=input,proto3" json:"input,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Async       *AsyncJob         `protobuf:"bytes,5,opt,name=async,proto3" json:"async,omitempty"`
+	PollJobFile string            `protobuf:"bytes,6,opt,name=poll_job_file,json=pollJobFile,proto3" json:"poll_job_file,omitempty"`
 }
 
 func (x *RunModuleRequest) Reset() {
@@ -93,12 +95,27 @@ func (x *RunModuleRequest) GetInput() map[string][]byte {
 	return nil
 }
 
+func (x *RunModuleRequest) GetAsync() *AsyncJob {
+	if x != nil {
+		return x.Async
+	}
+	return nil
+}
+
+func (x *RunModuleRequest) GetPollJobFile() string {
+	if x != nil {
+		return x.PollJobFile
+	}
+	return ""
+}
+
 type RunModuleResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Result *result.ModuleResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	Result      *result.ModuleResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	AsyncStatus *AsyncJobStatus      `protobuf:"bytes,2,opt,name=async_status,json=asyncStatus,proto3" json:"async_status,omitempty"`
 }
 
 func (x *RunModuleResponse) Reset() {
@@ -140,6 +157,147 @@ func (x *RunModuleResponse) GetResult() *result.ModuleResult {
 	return nil
 }
 
+func (x *RunModuleResponse) GetAsyncStatus() *AsyncJobStatus {
+	if x != nil {
+		return x.AsyncStatus
+	}
+	return nil
+}
+
+type AsyncJob struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id             string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TempPath       string `protobuf:"bytes,2,opt,name=temp_path,json=tempPath,proto3" json:"temp_path,omitempty"`
+	TimeoutSeconds int64  `protobuf:"varint,3,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+}
+
+func (x *AsyncJob) Reset() {
+	*x = AsyncJob{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_plugin_v1_plugin_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AsyncJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AsyncJob) ProtoMessage() {}
+
+func (x *AsyncJob) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1_plugin_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AsyncJob.ProtoReflect.Descriptor instead.
+func (*AsyncJob) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1_plugin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AsyncJob) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AsyncJob) GetTempPath() string {
+	if x != nil {
+		return x.TempPath
+	}
+	return ""
+}
+
+func (x *AsyncJob) GetTimeoutSeconds() int64 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+type AsyncJobStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	JobFile  string               `protobuf:"bytes,2,opt,name=job_file,json=jobFile,proto3" json:"job_file,omitempty"`
+	Finished bool                 `protobuf:"varint,3,opt,name=finished,proto3" json:"finished,omitempty"`
+	Result   *result.ModuleResult `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *AsyncJobStatus) Reset() {
+	*x = AsyncJobStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_plugin_v1_plugin_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AsyncJobStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AsyncJobStatus) ProtoMessage() {}
+
+func (x *AsyncJobStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1_plugin_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AsyncJobStatus.ProtoReflect.Descriptor instead.
+func (*AsyncJobStatus) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1_plugin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AsyncJobStatus) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AsyncJobStatus) GetJobFile() string {
+	if x != nil {
+		return x.JobFile
+	}
+	return ""
+}
+
+func (x *AsyncJobStatus) GetFinished() bool {
+	if x != nil {
+		return x.Finished
+	}
+	return false
+}
+
+func (x *AsyncJobStatus) GetResult() *result.ModuleResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
 var File_pkg_plugin_v1_plugin_proto protoreflect.FileDescriptor
 
 var file_pkg_plugin_v1_plugin_proto_rawDesc = []byte{
@@ -148,7 +306,7 @@ var file_pkg_plugin_v1_plugin_proto_rawDesc = []byte{
 	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x31, 0x1a, 0x13, 0x70, 0x6b, 0x67, 0x2f, 0x69, 0x6e, 0x66,
 	0x6f, 0x2f, 0x69, 0x6e, 0x66, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x17, 0x70, 0x6b,
 	0x67, 0x2f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x2f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xef, 0x01, 0x0a, 0x10, 0x52, 0x75, 0x6e, 0x4d, 0x6f, 0x64,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xbe, 0x02, 0x0a, 0x10, 0x52, 0x75, 0x6e, 0x4d, 0x6f, 0x64,
 	0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x6f,
 	0x64, 0x75, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
 	0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x08, 0x68, 0x6f,
@@ -159,19 +317,42 @@ var file_pkg_plugin_v1_plugin_proto_rawDesc = []byte{
 	0x3c, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26,
 	0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6e, 0x4d, 0x6f,
 	0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x49, 0x6e, 0x70, 0x75,
-	0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x1a, 0x38, 0x0a,
-	0x0a, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
-	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x41, 0x0a, 0x11, 0x52, 0x75, 0x6e, 0x4d, 0x6f,
-	0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06,
-	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72,
-	0x65, 0x73, 0x75, 0x6c, 0x74, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x75,
-	0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69,
-	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x72, 0x69, 0x70, 0x70, 0x73, 0x6f,
-	0x66, 0x74, 0x2f, 0x66, 0x6f, 0x72, 0x67, 0x65, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x6c, 0x75,
-	0x67, 0x69, 0x6e, 0x2f, 0x76, 0x31, 0x3b, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x76, 0x31, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x29, 0x0a,
+	0x05, 0x61, 0x73, 0x79, 0x6e, 0x63, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70,
+	0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x73, 0x79, 0x6e, 0x63, 0x4a, 0x6f,
+	0x62, 0x52, 0x05, 0x61, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x22, 0x0a, 0x0d, 0x70, 0x6f, 0x6c, 0x6c,
+	0x5f, 0x6a, 0x6f, 0x62, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x70, 0x6f, 0x6c, 0x6c, 0x4a, 0x6f, 0x62, 0x46, 0x69, 0x6c, 0x65, 0x1a, 0x38, 0x0a, 0x0a,
+	0x49, 0x6e, 0x70, 0x75, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x7f, 0x0a, 0x11, 0x52, 0x75, 0x6e, 0x4d, 0x6f, 0x64,
+	0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x3c, 0x0a, 0x0c, 0x61, 0x73, 0x79,
+	0x6e, 0x63, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x73, 0x79, 0x6e,
+	0x63, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x0b, 0x61, 0x73, 0x79, 0x6e,
+	0x63, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x60, 0x0a, 0x08, 0x41, 0x73, 0x79, 0x6e, 0x63,
+	0x4a, 0x6f, 0x62, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x65, 0x6d, 0x70, 0x5f, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x50, 0x61, 0x74, 0x68,
+	0x12, 0x27, 0x0a, 0x0f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x73, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x85, 0x01, 0x0a, 0x0e, 0x41, 0x73,
+	0x79, 0x6e, 0x63, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x19, 0x0a, 0x08,
+	0x6a, 0x6f, 0x62, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6a, 0x6f, 0x62, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x69, 0x6e, 0x69, 0x73,
+	0x68, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x66, 0x69, 0x6e, 0x69, 0x73,
+	0x68, 0x65, 0x64, 0x12, 0x2c, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x2e, 0x4d, 0x6f, 0x64,
+	0x75, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x74, 0x72, 0x69, 0x70, 0x70, 0x73, 0x6f, 0x66, 0x74, 0x2f, 0x66, 0x6f, 0x72, 0x67, 0x65, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2f, 0x76, 0x31, 0x3b, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -186,23 +367,28 @@ func file_pkg_plugin_v1_plugin_proto_rawDescGZIP() []byte {
 	return file_pkg_plugin_v1_plugin_proto_rawDescData
 }
 
-var file_pkg_plugin_v1_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pkg_plugin_v1_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_pkg_plugin_v1_plugin_proto_goTypes = []interface{}{
 	(*RunModuleRequest)(nil),    // 0: plugin.v1.RunModuleRequest
 	(*RunModuleResponse)(nil),   // 1: plugin.v1.RunModuleResponse
-	nil,                         // 2: plugin.v1.RunModuleRequest.InputEntry
-	(*info.HostInfo)(nil),       // 3: info.HostInfo
-	(*result.ModuleResult)(nil), // 4: result.ModuleResult
+	(*AsyncJob)(nil),            // 2: plugin.v1.AsyncJob
+	(*AsyncJobStatus)(nil),      // 3: plugin.v1.AsyncJobStatus
+	nil,                         // 4: plugin.v1.RunModuleRequest.InputEntry
+	(*info.HostInfo)(nil),       // 5: info.HostInfo
+	(*result.ModuleResult)(nil), // 6: result.ModuleResult
 }
 var file_pkg_plugin_v1_plugin_proto_depIdxs = []int32{
-	3, // 0: plugin.v1.RunModuleRequest.hostInfo:type_name -> info.HostInfo
-	2, // 1: plugin.v1.RunModuleRequest.input:type_name -> plugin.v1.RunModuleRequest.InputEntry
-	4, // 2: plugin.v1.RunModuleResponse.result:type_name -> result.ModuleResult
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	5, // 0: plugin.v1.RunModuleRequest.hostInfo:type_name -> info.HostInfo
+	4, // 1: plugin.v1.RunModuleRequest.input:type_name -> plugin.v1.RunModuleRequest.InputEntry
+	2, // 2: plugin.v1.RunModuleRequest.async:type_name -> plugin.v1.AsyncJob
+	6, // 3: plugin.v1.RunModuleResponse.result:type_name -> result.ModuleResult
+	3, // 4: plugin.v1.RunModuleResponse.async_status:type_name -> plugin.v1.AsyncJobStatus
+	6, // 5: plugin.v1.AsyncJobStatus.result:type_name -> result.ModuleResult
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_pkg_plugin_v1_plugin_proto_init() }
@@ -235,6 +421,30 @@ func file_pkg_plugin_v1_plugin_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_plugin_v1_plugin_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AsyncJob); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_plugin_v1_plugin_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AsyncJobStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -242,7 +452,7 @@ func file_pkg_plugin_v1_plugin_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_plugin_v1_plugin_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -28,6 +28,14 @@ type Transport interface {
 	OS() (string, error)   // OS returns the operating system of the managed system.
 	Arch() (string, error) // Arch returns the architecture of the managed system.
 
+	// Identity returns a string that identifies the managed system the transport connects to, such as the address of
+	// an SSH server and the user that logs in to it.
+	Identity() string
+
+	// TempPath returns the path on the managed system where Forge stores temporary files, such as plugins and the job
+	// files of async steps.
+	TempPath() (string, error)
+
 	Connect() error // Connect establishes the transport connection.
 	Close() error   // Close terminates the transport connection.
 
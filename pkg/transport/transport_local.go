@@ -7,9 +7,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/trippsoft/forge/pkg/plugin"
 )
@@ -23,15 +25,29 @@ type localPluginSession struct {
 	stdout  io.ReadCloser
 	stderr  io.ReadCloser
 	stdin   io.WriteCloser
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // Close implements [plugin.Session].
+//
+// Close is safe to call more than once and from multiple goroutines.
 func (l *localPluginSession) Close() error {
+	l.closeOnce.Do(func() {
+		l.closeErr = l.close()
+	})
+
+	return l.closeErr
+}
+
+// close terminates the plugin process and waits for it to exit.
+func (l *localPluginSession) close() error {
 	l.stdout.Close()
 	l.stderr.Close()
 	l.stdin.Close()
 
-	err := l.command.Process.Kill()
+	err := killProcessTree(l.command.Process)
 	if err != nil {
 		return err
 	}
@@ -71,6 +87,16 @@ func (l *localTransport) Arch() (string, error) {
 	return runtime.GOARCH, nil
 }
 
+// Identity implements [Transport].
+func (l *localTransport) Identity() string {
+	return string(TransportTypeLocal)
+}
+
+// TempPath implements [Transport].
+func (l *localTransport) TempPath() (string, error) {
+	return os.TempDir(), nil
+}
+
 // Connect implements [Transport].
 func (l *localTransport) Connect() error {
 	return nil
@@ -99,6 +125,7 @@ func (l *localTransport) StartPluginSession(
 	}
 
 	cmd := exec.CommandContext(ctx, path)
+	configureProcessTree(cmd)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -143,7 +170,7 @@ func (l *localTransport) StartPluginSession(
 				if readErr != io.EOF {
 					errChan <- fmt.Errorf("error reading stderr for plugin at '%s': %w", path, readErr)
 					stdin.Close()
-					cmd.Process.Kill()
+					killProcessTree(cmd.Process)
 					cmd.Wait()
 				}
 				return
@@ -159,12 +186,12 @@ func (l *localTransport) StartPluginSession(
 	select {
 	case <-ctx.Done():
 		stdin.Close()
-		cmd.Process.Kill()
+		killProcessTree(cmd.Process)
 		cmd.Wait()
 		return nil, fmt.Errorf("context cancelled while starting plugin at '%s': %w", path, ctx.Err())
 	case err := <-errChan:
 		stdin.Close()
-		cmd.Process.Kill()
+		killProcessTree(cmd.Process)
 		cmd.Wait()
 		return nil, err
 	case <-readyChan:
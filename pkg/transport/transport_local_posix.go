@@ -9,8 +9,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
+	"syscall"
 
 	"github.com/trippsoft/forge/pkg/plugin"
 )
@@ -28,6 +30,7 @@ func (l *localTransport) startEscalatedPluginSession(
 	args := []string{"-c", fmt.Sprintf("sudo -S -p '%s:' -u %s %s", forgeSudoPrompt, user, path)}
 
 	cmd := exec.CommandContext(ctx, "/bin/sh", args...)
+	configureProcessTree(cmd)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -73,7 +76,7 @@ func (l *localTransport) startEscalatedPluginSession(
 					if promptsAnswered >= 3 {
 						errChan <- fmt.Errorf("too many sudo password attempts for plugin at '%s'", path)
 						stdin.Close()
-						cmd.Process.Kill()
+						killProcessTree(cmd.Process)
 						cmd.Wait()
 						return
 					}
@@ -83,7 +86,7 @@ func (l *localTransport) startEscalatedPluginSession(
 					if err != nil {
 						errChan <- fmt.Errorf("failed to write password to stdin for plugin at '%s': %w", path, err)
 						stdin.Close()
-						cmd.Process.Kill()
+						killProcessTree(cmd.Process)
 						cmd.Wait()
 						return
 					}
@@ -96,7 +99,7 @@ func (l *localTransport) startEscalatedPluginSession(
 				if readErr != io.EOF {
 					errChan <- fmt.Errorf("error reading stderr for plugin at '%s': %w", path, readErr)
 					stdin.Close()
-					cmd.Process.Kill()
+					killProcessTree(cmd.Process)
 					cmd.Wait()
 				}
 				return
@@ -112,12 +115,12 @@ func (l *localTransport) startEscalatedPluginSession(
 	select {
 	case <-ctx.Done():
 		stdin.Close()
-		cmd.Process.Kill()
+		killProcessTree(cmd.Process)
 		cmd.Wait()
 		return nil, fmt.Errorf("context cancelled while starting plugin at '%s': %w", path, ctx.Err())
 	case err := <-errChan:
 		stdin.Close()
-		cmd.Process.Kill()
+		killProcessTree(cmd.Process)
 		cmd.Wait()
 		return nil, err
 	case <-readyChan:
@@ -129,3 +132,23 @@ func (l *localTransport) startEscalatedPluginSession(
 		}, nil
 	}
 }
+
+// configureProcessTree starts the command in its own process group, so cancelling it kills its whole process tree.
+func configureProcessTree(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return killProcessTree(cmd.Process)
+	}
+}
+
+// killProcessTree kills the process group led by the process.
+//
+// If the group cannot be signalled, such as when it contains processes of another user, only the process is killed.
+func killProcessTree(process *os.Process) error {
+	err := syscall.Kill(-process.Pid, syscall.SIGKILL)
+	if err != nil {
+		return process.Kill()
+	}
+
+	return nil
+}
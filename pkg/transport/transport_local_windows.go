@@ -9,7 +9,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/trippsoft/forge/pkg/plugin"
@@ -28,6 +30,7 @@ func (l *localTransport) startEscalatedPluginSession(
 	args := []string{"/c", fmt.Sprintf("gsudo.exe -u %s %q", user, path)}
 
 	cmd := exec.CommandContext(ctx, "cmd.exe", args...)
+	configureProcessTree(cmd)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -72,7 +75,7 @@ func (l *localTransport) startEscalatedPluginSession(
 					if promptsAnswered >= 3 {
 						errChan <- fmt.Errorf("too many gsudo password attempts for plugin at '%s'", path)
 						stdin.Close()
-						cmd.Process.Kill()
+						killProcessTree(cmd.Process)
 						cmd.Wait()
 						return
 					}
@@ -82,7 +85,7 @@ func (l *localTransport) startEscalatedPluginSession(
 					if err != nil {
 						errChan <- fmt.Errorf("failed to write password to stdin for plugin at '%s': %w", path, err)
 						stdin.Close()
-						cmd.Process.Kill()
+						killProcessTree(cmd.Process)
 						cmd.Wait()
 						return
 					}
@@ -95,7 +98,7 @@ func (l *localTransport) startEscalatedPluginSession(
 				if readErr != io.EOF {
 					errChan <- fmt.Errorf("error reading stderr for plugin at '%s': %w", path, readErr)
 					stdin.Close()
-					cmd.Process.Kill()
+					killProcessTree(cmd.Process)
 					cmd.Wait()
 				}
 				return
@@ -111,12 +114,12 @@ func (l *localTransport) startEscalatedPluginSession(
 	select {
 	case <-ctx.Done():
 		stdin.Close()
-		cmd.Process.Kill()
+		killProcessTree(cmd.Process)
 		cmd.Wait()
 		return nil, fmt.Errorf("context cancelled while starting plugin at '%s': %w", path, ctx.Err())
 	case err := <-errChan:
 		stdin.Close()
-		cmd.Process.Kill()
+		killProcessTree(cmd.Process)
 		cmd.Wait()
 		return nil, err
 	case <-readyChan:
@@ -133,6 +136,7 @@ func (l *localTransport) startPluginSessionAsSystem(ctx context.Context, path st
 	args := []string{"/c", fmt.Sprintf("gsudo.exe -s %q", path)}
 
 	cmd := exec.CommandContext(ctx, "cmd.exe", args...)
+	configureProcessTree(cmd)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -177,7 +181,7 @@ func (l *localTransport) startPluginSessionAsSystem(ctx context.Context, path st
 				if readErr != io.EOF {
 					errChan <- fmt.Errorf("error reading stderr for plugin at '%s': %w", path, readErr)
 					stdin.Close()
-					cmd.Process.Kill()
+					killProcessTree(cmd.Process)
 					cmd.Wait()
 				}
 				return
@@ -193,12 +197,12 @@ func (l *localTransport) startPluginSessionAsSystem(ctx context.Context, path st
 	select {
 	case <-ctx.Done():
 		stdin.Close()
-		cmd.Process.Kill()
+		killProcessTree(cmd.Process)
 		cmd.Wait()
 		return nil, fmt.Errorf("context cancelled while starting plugin at '%s': %w", path, ctx.Err())
 	case err := <-errChan:
 		stdin.Close()
-		cmd.Process.Kill()
+		killProcessTree(cmd.Process)
 		cmd.Wait()
 		return nil, err
 	case <-readyChan:
@@ -210,3 +214,22 @@ func (l *localTransport) startPluginSessionAsSystem(ctx context.Context, path st
 		}, nil
 	}
 }
+
+// configureProcessTree makes cancelling the command kill its whole process tree.
+func configureProcessTree(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return killProcessTree(cmd.Process)
+	}
+}
+
+// killProcessTree kills the process and its descendants with taskkill.
+//
+// If taskkill fails, only the process is killed.
+func killProcessTree(process *os.Process) error {
+	err := exec.Command("taskkill.exe", "/T", "/F", "/PID", strconv.Itoa(process.Pid)).Run()
+	if err != nil {
+		return process.Kill()
+	}
+
+	return nil
+}
@@ -5,6 +5,8 @@ package transport
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"runtime"
 
 	"github.com/trippsoft/forge/pkg/plugin"
@@ -34,6 +36,16 @@ func (m *MockTransport) Arch() (string, error) {
 	return runtime.GOARCH, nil
 }
 
+// Identity implements [Transport].
+func (m *MockTransport) Identity() string {
+	return fmt.Sprintf("%s://mock", m.TransportType)
+}
+
+// TempPath implements [Transport].
+func (m *MockTransport) TempPath() (string, error) {
+	return os.TempDir(), nil
+}
+
 // Connect implements [Transport].
 func (m *MockTransport) Connect() error {
 	return nil
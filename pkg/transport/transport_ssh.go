@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
 
 	"github.com/pkg/sftp"
 	"github.com/trippsoft/forge/pkg/plugin"
@@ -79,6 +80,28 @@ func (s *sshTransport) Arch() (string, error) {
 	return s.platform.Arch(), nil
 }
 
+// Identity implements [Transport].
+func (s *sshTransport) Identity() string {
+	address := net.JoinHostPort(s.host, fmt.Sprintf("%d", s.port))
+	if s.config == nil || s.config.User == "" {
+		return fmt.Sprintf("ssh://%s", address)
+	}
+
+	return fmt.Sprintf("ssh://%s@%s", s.config.User, address)
+}
+
+// TempPath implements [Transport].
+func (s *sshTransport) TempPath() (string, error) {
+	if s.tempPath == "" {
+		err := s.Connect()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return s.tempPath, nil
+}
+
 // Connect implements [Transport].
 func (s *sshTransport) Connect() error {
 	if s.client != nil {
@@ -208,10 +231,28 @@ type sshPluginSession struct {
 	stdout  io.Reader
 	stderr  io.ReadCloser
 	stdin   io.WriteCloser
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // Close implements [plugin.Session].
+//
+// Close is safe to call more than once and from multiple goroutines.
 func (s *sshPluginSession) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.close()
+	})
+
+	return s.closeErr
+}
+
+// close closes the SSH session and waits for the remote command to exit.
+//
+// Closing the standard input of the plugin and signalling it tells the plugin to kill its process tree if its module
+// has not completed. Servers that do not support signals ignore the signal.
+func (s *sshPluginSession) close() error {
+	s.session.Signal(ssh.SIGTERM)
 	s.stdin.Close()
 	s.stderr.Close()
 	err := s.session.Close()
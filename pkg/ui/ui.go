@@ -73,6 +73,14 @@ type UI interface {
 	// The iterationLabel is the label for the specific iteration.
 	// The result indicates the outcome of the step execution for that iteration.
 	PrintIterationResult(hostname, iterationLabel string, result *result.Result)
+
+	// PrintAttemptResult prints the result of an attempt of a step that will be retried.
+	//
+	// The hostname is the name of the managed system.
+	// The iterationLabel is the label for the specific iteration, if any.
+	// The attempt is the number of the attempt, starting at 1, out of the maximum number of attempts.
+	// The result indicates the outcome of the attempt.
+	PrintAttemptResult(hostname, iterationLabel string, attempt, attempts int, result *result.Result)
 }
 
 type mockUI struct{}
@@ -108,3 +116,7 @@ func (m *mockUI) PrintHostResult(hostname string, result *result.Result) {
 // PrintIterationResult implements UI.
 func (m *mockUI) PrintIterationResult(hostname string, iterationLabel string, result *result.Result) {
 }
+
+// PrintAttemptResult implements UI.
+func (m *mockUI) PrintAttemptResult(hostname, iterationLabel string, attempt, attempts int, result *result.Result) {
+}
@@ -0,0 +1,432 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package workflow
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/hclutil"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/result"
+	"github.com/trippsoft/forge/pkg/set"
+	"github.com/trippsoft/forge/pkg/ui"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Block represents a group of steps with steps that handle their failure and steps that always run.
+//
+// This represents a parsed block block within a process.
+// The steps of a block share the step context of the block, so they can reference and be referenced by other steps.
+type Block struct {
+	common   *StepCommonConfig
+	escalate *StepEscalateConfig
+
+	steps  []Step
+	rescue []Step
+	always []Step
+}
+
+// ID implements Step.
+func (b *Block) ID() string {
+	return b.common.id
+}
+
+// Common returns the common configuration of the block.
+//
+// This is used primarily for testing purposes.
+func (b *Block) Common() *StepCommonConfig {
+	return b.common
+}
+
+// Escalate returns the escalation configuration of the block.
+//
+// This is used primarily for testing purposes.
+func (b *Block) Escalate() *StepEscalateConfig {
+	return b.escalate
+}
+
+// Steps returns a clone of the slice of all steps in the block.
+//
+// This is used primarily for testing purposes.
+func (b *Block) Steps() []Step {
+	return slices.Clone(b.steps)
+}
+
+// Rescue returns a clone of the slice of all steps that run on hosts where a step in the block failed.
+//
+// This is used primarily for testing purposes.
+func (b *Block) Rescue() []Step {
+	return slices.Clone(b.rescue)
+}
+
+// Always returns a clone of the slice of all steps that run after the block regardless of failures.
+//
+// This is used primarily for testing purposes.
+func (b *Block) Always() []Step {
+	return slices.Clone(b.always)
+}
+
+// Run implements Step.
+//
+// The outputs of the steps within the block are stored in each host's step context, so only the outputs of the block
+// itself are returned.
+func (b *Block) Run(wc *WorkflowContext) (map[string]cty.Value, error) {
+	outputs, err := b.runSteps(wc)
+	return outputs[b.common.id], err
+}
+
+// runSteps implements stepGroup.
+//
+// The rescue steps run on hosts that failed in the block, with their failed status cleared.
+// Hosts on which the rescue steps succeed are no longer failed, and the errors of the block's steps are not returned.
+// The always steps run on every host the block started on, including failed hosts, which remain failed afterwards.
+func (b *Block) runSteps(wc *WorkflowContext) (map[string]map[string]cty.Value, error) {
+	wc.ui.PrintHeader(ui.HeaderLevel2, "BLOCK - ", b.common.name)
+
+	wc.LoadHostVars()
+
+	outputs := make(map[string]map[string]cty.Value)
+	blockOutputs := make(map[string]cty.Value)
+	outputs[b.common.id] = blockOutputs
+
+	started, err := b.startOnTargets(wc, blockOutputs)
+	if started.IsEmpty() {
+		return outputs, err
+	}
+
+	stepsErr := runSteps(wc.withHostFilter(started), b.steps, outputs)
+
+	failed := failedHosts(wc, started)
+	rescued := set.NewSet[*inventory.Host]()
+	if len(b.rescue) == 0 {
+		err = errors.Join(err, stepsErr)
+	} else if !failed.IsEmpty() {
+		wc.ui.PrintHeader(ui.HeaderLevel2, "RESCUE - ", b.common.name)
+
+		for _, host := range failed.Items() {
+			wc.ClearFailed(host)
+		}
+
+		rescueErr := runSteps(wc.withHostFilter(failed), b.rescue, outputs)
+		err = errors.Join(err, rescueErr)
+
+		for _, host := range failed.Items() {
+			if !wc.IsFailed(host) {
+				rescued.Add(host)
+			}
+		}
+	}
+
+	if len(b.always) > 0 {
+		wc.ui.PrintHeader(ui.HeaderLevel2, "ALWAYS - ", b.common.name)
+
+		stillFailed := failedHosts(wc, started)
+		for _, host := range stillFailed.Items() {
+			wc.ClearFailed(host)
+		}
+
+		alwaysErr := runSteps(wc.withHostFilter(started), b.always, outputs)
+		err = errors.Join(err, alwaysErr)
+
+		for _, host := range stillFailed.Items() {
+			wc.MarkFailed(host)
+		}
+	}
+
+	wc.ui.PrintHeader(ui.HeaderLevel2, "BLOCK COMPLETE - ", b.common.name)
+
+	hosts := started.Items()
+	slices.SortFunc(hosts, func(a, b *inventory.Host) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	for _, host := range hosts {
+		var r *result.Result
+		switch {
+		case wc.IsFailed(host):
+			// The failing step has already reported its error, so it is not returned again.
+			r = result.NewFailure(errors.New("one or more steps in the block failed"), "")
+		default:
+			r = result.NewNotChanged(cty.ObjectVal(map[string]cty.Value{
+				"rescued": cty.BoolVal(rescued.Contains(host)),
+			}))
+			r.Changed = blockChanged(outputs, host)
+		}
+
+		blockOutputs[host.Name()] = b.handleHostResult(NewHostWorkflowContext(wc, host), r)
+	}
+
+	return outputs, err
+}
+
+// startOnTargets evaluates the condition of the block for each active target and returns the hosts it starts on.
+//
+// The outputs of hosts the block does not start on are stored in the provided map.
+func (b *Block) startOnTargets(wc *WorkflowContext, outputs map[string]cty.Value) (*set.Set[*inventory.Host], error) {
+	started := set.NewSet[*inventory.Host]()
+
+	var err error
+	for _, host := range b.common.targets {
+		if !wc.IsActive(host) {
+			continue
+		}
+
+		hwc := NewHostWorkflowContext(wc, host)
+
+		hostErr := hwc.LoadEvalContext()
+		if hostErr != nil {
+			hwc.MarkFailed(host)
+			r := result.NewFailure(hostErr, "failed to load evaluation context")
+			outputs[host.Name()] = b.handleHostResult(hwc, r)
+			err = errors.Join(err, hostErr)
+			continue
+		}
+
+		condition := true
+		if b.common.condition != nil {
+			var diags hcl.Diagnostics
+			condition, diags = hclutil.ConvertHCLAttributeToBool(b.common.condition, hwc.evalContext)
+			if diags.HasErrors() {
+				hwc.MarkFailed(host)
+				r := result.NewFailure(diags, diags.Error())
+				outputs[host.Name()] = b.handleHostResult(hwc, r)
+				err = errors.Join(err, diags)
+				continue
+			}
+		}
+
+		if !condition {
+			outputs[host.Name()] = b.handleHostResult(hwc, result.NewSkipped())
+			continue
+		}
+
+		started.Add(host)
+	}
+
+	return started, err
+}
+
+func (b *Block) handleHostResult(hwc *HostWorkflowContext, r *result.Result) cty.Value {
+	hwc.ui.PrintHostResult(hwc.host.Name(), r)
+	output := formatResultOutput(r)
+	hwc.host.StoreStepOutput(b.common.id, output)
+
+	return output
+}
+
+// runSteps runs the steps in order and merges their outputs into the provided map.
+//
+// If the workflow is stopped, no further steps run.
+func runSteps(wc *WorkflowContext, steps []Step, outputs map[string]map[string]cty.Value) error {
+	var err error
+	for _, step := range steps {
+		stopErr := wc.stopped()
+		if stopErr != nil {
+			return errors.Join(err, stopErr)
+		}
+
+		err = errors.Join(err, runStep(wc, step, outputs))
+	}
+
+	return err
+}
+
+// failedHosts returns the hosts that are marked as failed.
+func failedHosts(wc *WorkflowContext, hosts *set.Set[*inventory.Host]) *set.Set[*inventory.Host] {
+	failed := set.NewSet[*inventory.Host]()
+	for _, host := range hosts.Items() {
+		if wc.IsFailed(host) {
+			failed.Add(host)
+		}
+	}
+
+	return failed
+}
+
+// blockChanged checks if any step that ran in the block reported a change on the host.
+func blockChanged(outputs map[string]map[string]cty.Value, host *inventory.Host) bool {
+	for _, output := range outputs {
+		value, exists := output[host.Name()]
+		if exists && outputChanged(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BlockBuilder is used to build a Block instance during parsing.
+type BlockBuilder struct {
+	common   *StepCommonConfig
+	escalate *StepEscalateConfig
+
+	steps  []StepBuilder
+	rescue []StepBuilder
+	always []StepBuilder
+}
+
+// WithCommon sets the common configuration for the block.
+func (b *BlockBuilder) WithCommon(common *StepCommonConfig) *BlockBuilder {
+	b.common = common
+	return b
+}
+
+// WithEscalate sets the escalation configuration for the block.
+func (b *BlockBuilder) WithEscalate(escalate *StepEscalateConfig) *BlockBuilder {
+	b.escalate = escalate
+	return b
+}
+
+// AddStep adds a StepBuilder to the steps of the block.
+//
+// The common and escalation configuration of the block are passed to the step when the block is added to its parent.
+func (b *BlockBuilder) AddStep(sb StepBuilder) *BlockBuilder {
+	b.steps = append(b.steps, sb)
+	return b
+}
+
+// AddRescueStep adds a StepBuilder to the steps that run on hosts where a step in the block failed.
+func (b *BlockBuilder) AddRescueStep(sb StepBuilder) *BlockBuilder {
+	b.rescue = append(b.rescue, sb)
+	return b
+}
+
+// AddAlwaysStep adds a StepBuilder to the steps that run after the block regardless of failures.
+func (b *BlockBuilder) AddAlwaysStep(sb StepBuilder) *BlockBuilder {
+	b.always = append(b.always, sb)
+	return b
+}
+
+// allSteps returns the builders of the steps, rescue steps and always steps of the block.
+func (b *BlockBuilder) allSteps() []StepBuilder {
+	return slices.Concat(b.steps, b.rescue, b.always)
+}
+
+// WithProcessCommon implements StepBuilder.
+func (b *BlockBuilder) WithProcessCommon(common *StepCommonConfig) StepBuilder {
+	b.common.Combine(common)
+	for _, sb := range b.allSteps() {
+		sb.WithProcessCommon(b.common)
+	}
+
+	return b
+}
+
+// WithProcessEscalate implements StepBuilder.
+func (b *BlockBuilder) WithProcessEscalate(escalate *StepEscalateConfig) StepBuilder {
+	if b.escalate == nil {
+		b.escalate = escalate
+	} else {
+		b.escalate.Combine(escalate)
+	}
+
+	for _, sb := range b.allSteps() {
+		sb.WithProcessEscalate(b.escalate)
+	}
+
+	return b
+}
+
+// AllTargets implements StepBuilder.
+//
+// Steps within the block only run on the block's targets, so those are the only targets returned.
+func (b *BlockBuilder) AllTargets() []*inventory.Host {
+	targets := slices.Clone(b.common.targets)
+	return targets
+}
+
+// notifications implements handlerNotifier.
+func (b *BlockBuilder) notifications() []*handlerNotification {
+	notifications := []*handlerNotification{}
+	for _, sb := range b.allSteps() {
+		if notifier, ok := sb.(handlerNotifier); ok {
+			notifications = append(notifications, notifier.notifications()...)
+		}
+	}
+
+	return notifications
+}
+
+// filterTags implements taggedStepBuilder.
+//
+// The block is selected if any of its steps are selected. Its rescue and always steps are filtered the same way.
+func (b *BlockBuilder) filterTags(filter *TagFilter) bool {
+	b.steps = filterTaggedSteps(b.steps, filter)
+	b.rescue = filterTaggedSteps(b.rescue, filter)
+	b.always = filterTaggedSteps(b.always, filter)
+
+	return len(b.steps) > 0
+}
+
+// allTags implements taggedStepBuilder.
+func (b *BlockBuilder) allTags() []string {
+	tags := set.NewSet(b.common.tags...)
+	collectTags(tags, b.allSteps())
+	return tags.Items()
+}
+
+// Build implements StepBuilder.
+func (b *BlockBuilder) Build() (Step, error) {
+	if b.common == nil {
+		return nil, errors.New("Block failed to build: common configuration is missing")
+	}
+
+	if b.common.id == "" {
+		return nil, errors.New("Block failed to build: id is missing")
+	}
+
+	if b.common.name == "" {
+		return nil, errors.New("Block failed to build: name is missing")
+	}
+
+	if b.common.targets == nil {
+		return nil, errors.New("Block failed to build: targets are missing")
+	}
+
+	steps, err := buildSteps(b.steps)
+	rescue, rescueErr := buildSteps(b.rescue)
+	always, alwaysErr := buildSteps(b.always)
+
+	err = errors.Join(err, rescueErr, alwaysErr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Block{
+		common:   b.common,
+		escalate: b.escalate,
+		steps:    steps,
+		rescue:   rescue,
+		always:   always,
+	}, nil
+}
+
+// buildSteps builds each of the provided step builders.
+func buildSteps(builders []StepBuilder) ([]Step, error) {
+	steps := make([]Step, 0, len(builders))
+	var err error
+	for _, sb := range builders {
+		step, stepErr := sb.Build()
+		if stepErr != nil || err != nil {
+			err = errors.Join(err, stepErr)
+			continue
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, err
+}
+
+// NewBlockBuilder creates a new instance of BlockBuilder.
+func NewBlockBuilder() *BlockBuilder {
+	return &BlockBuilder{
+		steps:  []StepBuilder{},
+		rescue: []StepBuilder{},
+		always: []StepBuilder{},
+	}
+}
@@ -4,46 +4,315 @@
 package workflow
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/trippsoft/forge/pkg/hclfunction"
+	"github.com/trippsoft/forge/pkg/info"
 	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/result"
 	"github.com/trippsoft/forge/pkg/set"
+	"github.com/trippsoft/forge/pkg/transport"
 	"github.com/trippsoft/forge/pkg/ui"
 	"github.com/zclconf/go-cty/cty"
 )
 
+// DefaultForks is the default maximum number of hosts that run modules or discover info concurrently.
+const DefaultForks = 10
+
+// localhostName is the name that delegate_to uses for the controller, unless the inventory has a host by that name.
+const localhostName = "localhost"
+
+// ErrStopped is returned when a workflow stops before it completes because its context or stop context is done.
+var ErrStopped = errors.New("workflow stopped")
+
 type WorkflowContext struct {
+	ctx         context.Context
+	stopCtx     context.Context
 	ui          ui.UI
 	inventory   *inventory.Inventory
+	localhost   *inventory.Host
 	debug       bool
 	hostVars    map[string]cty.Value
+	variables   map[string]cty.Value
+	locals      map[string]*hcl.Attribute
 	failedHosts *set.Set[*inventory.Host]
+	failedMutex *sync.RWMutex
+	hostFilter  *set.Set[*inventory.Host]
+	forks       chan struct{}
+	infoCache   *info.Cache
 	workingDir  string
+
+	discoverInfo    bool
+	discoveredHosts *set.Set[*inventory.Host]
+	discoveredMutex *sync.Mutex
+	delegateMutex   *sync.Mutex
+
+	notifications *handlerNotifications
+}
+
+// WithContext sets the context of the workflow run.
+//
+// When the context is done, running modules are cancelled and no further steps start.
+func (wc *WorkflowContext) WithContext(ctx context.Context) *WorkflowContext {
+	wc.ctx = ctx
+	return wc
+}
+
+// WithStopContext sets the context that stops the workflow gracefully.
+//
+// When the context is done, no further steps start, but running modules are allowed to finish.
+func (wc *WorkflowContext) WithStopContext(ctx context.Context) *WorkflowContext {
+	wc.stopCtx = ctx
+	return wc
+}
+
+// stopped returns an error wrapping ErrStopped and its cause if no further steps should start, or nil otherwise.
+func (wc *WorkflowContext) stopped() error {
+	for _, ctx := range []context.Context{wc.ctx, wc.stopCtx} {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", ErrStopped, context.Cause(ctx))
+		}
+	}
+
+	return nil
+}
+
+// sleep waits for the duration and reports whether it elapsed before the workflow was stopped.
+func (wc *WorkflowContext) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-wc.ctx.Done():
+		return false
+	case <-wc.stopCtx.Done():
+		return false
+	}
+}
+
+// WithForks sets the maximum number of hosts that run modules or discover info concurrently.
+//
+// A value less than 1 removes the limit.
+func (wc *WorkflowContext) WithForks(forks int) *WorkflowContext {
+	if forks < 1 {
+		wc.forks = nil
+		return wc
+	}
+
+	wc.forks = make(chan struct{}, forks)
+	return wc
+}
+
+// WithInfoCache sets the cache that host info is loaded from and stored in when processes discover info.
+//
+// A nil cache discovers the info of every host.
+func (wc *WorkflowContext) WithInfoCache(cache *info.Cache) *WorkflowContext {
+	wc.infoCache = cache
+	return wc
+}
+
+// WithVariables sets the values of the workflow variables, which are available as variable.*.
+func (wc *WorkflowContext) WithVariables(variables map[string]cty.Value) *WorkflowContext {
+	wc.variables = variables
+	return wc
+}
+
+// acquireFork blocks until a fork is available.
+func (wc *WorkflowContext) acquireFork() {
+	if wc.forks != nil {
+		wc.forks <- struct{}{}
+	}
+}
+
+// releaseFork releases a fork acquired with acquireFork.
+func (wc *WorkflowContext) releaseFork() {
+	if wc.forks != nil {
+		<-wc.forks
+	}
 }
 
 // LoadHostVars loads the variables for each host in the inventory into the WorkflowContext.
+//
+// The runtime variables of each host are merged into its variables, taking precedence over them.
+// The step outputs of each host, including those shared by run_once steps, are available as steps, unless the host
+// has a variable by that name.
 func (wc *WorkflowContext) LoadHostVars() {
 	wc.hostVars = make(map[string]cty.Value)
 	for _, host := range wc.inventory.Hosts() {
-		vars := host.Vars()
+		vars := maps.Clone(host.Vars())
+		if runtimeVars := host.RuntimeVars(); len(runtimeVars) > 0 {
+			if vars == nil {
+				vars = make(map[string]cty.Value, len(runtimeVars))
+			}
+
+			maps.Copy(vars, runtimeVars)
+		}
+
+		if _, exists := vars["steps"]; !exists {
+			if steps := hostStepOutputs(host); len(steps) > 0 {
+				if vars == nil {
+					vars = make(map[string]cty.Value, 1)
+				}
+
+				vars["steps"] = cty.ObjectVal(steps)
+			}
+		}
+
 		if len(vars) > 0 {
 			wc.hostVars[host.Name()] = cty.ObjectVal(vars)
 		}
 	}
 }
 
+// hostStepOutputs returns the step outputs of the current step context of a host.
+func hostStepOutputs(host *inventory.Host) map[string]cty.Value {
+	steps, err := host.GetCurrentContextSteps()
+	if err != nil {
+		return nil
+	}
+
+	outputs := make(map[string]cty.Value, len(steps))
+	for id, output := range steps {
+		if !output.Type().Equals(cty.NilType) {
+			outputs[id] = output
+		}
+	}
+
+	return outputs
+}
+
+// delegateHost returns the host named by a step's delegate_to attribute.
+//
+// The name is that of a host in the inventory, or localhost for the controller.
+func (wc *WorkflowContext) delegateHost(name string) (*inventory.Host, error) {
+	host, exists := wc.inventory.Host(name)
+	if exists {
+		return host, nil
+	}
+
+	if name == localhostName {
+		return wc.localhost, nil
+	}
+
+	return nil, fmt.Errorf("delegate_to host %q is not in the inventory", name)
+}
+
+// withDiscoverInfo returns a copy of the WorkflowContext that discovers the info of the hosts that steps delegate to,
+// if discoverInfo is true.
+//
+// The discovered hosts are shared with the original WorkflowContext.
+func (wc *WorkflowContext) withDiscoverInfo(discoverInfo bool) *WorkflowContext {
+	child := *wc
+	child.discoverInfo = discoverInfo
+	return &child
+}
+
+// populateInfo discovers the info of a host, or loads it from the info cache if one is set.
+func (wc *WorkflowContext) populateInfo(host *inventory.Host) *result.Result {
+	var r *result.Result
+	if wc.infoCache != nil {
+		r = wc.infoCache.Populate(wc.ctx, host.Name(), host.Info(), host.Transport())
+	} else {
+		r = host.Info().Populate(wc.ctx, host.Transport())
+	}
+
+	if !r.Failed {
+		wc.discoveredMutex.Lock()
+		wc.discoveredHosts.Add(host)
+		wc.discoveredMutex.Unlock()
+	}
+
+	return r
+}
+
+// discoverDelegateInfo discovers the info of a host that a step delegates to, if the process discovers info.
+//
+// The info of a host is only discovered, or loaded from the info cache, the first time a step delegates to it, unless
+// it has already been discovered as a target of a process.
+func (wc *WorkflowContext) discoverDelegateInfo(host *inventory.Host) error {
+	if !wc.discoverInfo {
+		return nil
+	}
+
+	wc.delegateMutex.Lock()
+	defer wc.delegateMutex.Unlock()
+
+	wc.discoveredMutex.Lock()
+	discovered := wc.discoveredHosts.Contains(host)
+	wc.discoveredMutex.Unlock()
+	if discovered {
+		return nil
+	}
+
+	wc.acquireFork()
+	r := wc.populateInfo(host)
+	wc.releaseFork()
+
+	if r.Failed {
+		return fmt.Errorf("failed to discover info for delegate host %q: %w", host.Name(), r.Error)
+	}
+
+	return nil
+}
+
 // IsFailed checks if the given host has been marked as failed in the workflow context.
 func (wc *WorkflowContext) IsFailed(host *inventory.Host) bool {
+	wc.failedMutex.RLock()
+	defer wc.failedMutex.RUnlock()
+
 	return wc.failedHosts.Contains(host)
 }
 
 // MarkFailed marks the given host as failed in the workflow context.
 func (wc *WorkflowContext) MarkFailed(host *inventory.Host) {
+	wc.failedMutex.Lock()
+	defer wc.failedMutex.Unlock()
+
 	wc.failedHosts.Add(host)
 }
 
+// ClearFailed clears the failed status of the given host in the workflow context.
+func (wc *WorkflowContext) ClearFailed(host *inventory.Host) {
+	wc.failedMutex.Lock()
+	defer wc.failedMutex.Unlock()
+
+	wc.failedHosts.Remove(host)
+}
+
+// IsActive checks if steps should run on the given host.
+//
+// A host is active if it has not failed and it is within the current host filter, if any.
+func (wc *WorkflowContext) IsActive(host *inventory.Host) bool {
+	if wc.hostFilter != nil && !wc.hostFilter.Contains(host) {
+		return false
+	}
+
+	return !wc.IsFailed(host)
+}
+
+// withHostFilter returns a copy of the WorkflowContext that only runs steps on the given hosts.
+//
+// If the WorkflowContext already has a host filter, the resulting filter is the intersection of both.
+// The failed hosts are shared with the original WorkflowContext.
+func (wc *WorkflowContext) withHostFilter(hosts *set.Set[*inventory.Host]) *WorkflowContext {
+	child := *wc
+	if wc.hostFilter != nil {
+		hosts = set.Intersection(wc.hostFilter, hosts)
+	}
+
+	child.hostFilter = hosts
+	return &child
+}
+
 // NewWorkflowContext creates a new WorkflowContext with the provided parameters.
 func NewWorkflowContext(ui ui.UI, i *inventory.Inventory, debug bool) (*WorkflowContext, error) {
 	workingDir, err := os.Getwd()
@@ -51,12 +320,32 @@ func NewWorkflowContext(ui ui.UI, i *inventory.Inventory, debug bool) (*Workflow
 		return nil, err
 	}
 
+	localhost, err := inventory.NewHostBuilder().
+		WithName(localhostName).
+		WithTransport(transport.LocalTransport).
+		WithEscalateConfig(inventory.NewEscalateConfig("")).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
 	return &WorkflowContext{
+		ctx:         context.Background(),
+		stopCtx:     context.Background(),
 		ui:          ui,
 		inventory:   i,
+		localhost:   localhost,
 		debug:       debug,
 		failedHosts: set.NewSet[*inventory.Host](),
+		failedMutex: &sync.RWMutex{},
+		forks:       make(chan struct{}, DefaultForks),
 		workingDir:  workingDir,
+
+		discoveredHosts: set.NewSet[*inventory.Host](),
+		discoveredMutex: &sync.Mutex{},
+		delegateMutex:   &sync.Mutex{},
+
+		notifications: newHandlerNotifications(),
 	}, nil
 }
 
@@ -68,18 +357,19 @@ type HostWorkflowContext struct {
 }
 
 // LoadEvalContext initializes the HCL evaluation context for the host workflow context.
+//
+// Functions resolve relative paths against the working directory the workflow context was created in.
 func (hwc *HostWorkflowContext) LoadEvalContext() error {
-	workingDir, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-
 	evalCtx := &hcl.EvalContext{
 		Variables: map[string]cty.Value{
 			"hostvars": cty.ObjectVal(hwc.hostVars),
 			"info":     cty.ObjectVal(hwc.host.Info().ToMapOfCtyValues()),
 		},
-		Functions: hclfunction.HCLFunctions(workingDir),
+		Functions: hclfunction.HCLFunctions(hwc.workingDir),
+	}
+
+	if len(hwc.variables) > 0 {
+		evalCtx.Variables["variable"] = cty.ObjectVal(hwc.variables)
 	}
 
 	vars, exists := hwc.hostVars[hwc.host.Name()]
@@ -101,6 +391,13 @@ func (hwc *HostWorkflowContext) LoadEvalContext() error {
 		evalCtx.Variables["input"] = cty.ObjectVal(procedureInputs)
 	}
 
+	if len(hwc.locals) > 0 {
+		diags := evaluateLocals(hwc.locals, evalCtx)
+		if diags.HasErrors() {
+			return diags
+		}
+	}
+
 	hwc.evalContext = evalCtx
 
 	return nil
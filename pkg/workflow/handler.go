@@ -0,0 +1,163 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package workflow
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/set"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// handlerNotification represents a reference from a step to a handler it notifies.
+type handlerNotification struct {
+	handler string
+	subject *hcl.Range
+}
+
+// handlerNotifier is implemented by step builders that can notify handlers.
+//
+// This is used to validate that every notified handler exists in the process.
+type handlerNotifier interface {
+	notifications() []*handlerNotification
+}
+
+// handlerNotifications tracks the hosts on which each handler has been notified.
+type handlerNotifications struct {
+	mutex sync.Mutex
+	hosts map[string]*set.Set[*inventory.Host]
+}
+
+func newHandlerNotifications() *handlerNotifications {
+	return &handlerNotifications{
+		hosts: map[string]*set.Set[*inventory.Host]{},
+	}
+}
+
+// notifyHandlers notifies the given handlers to run on the host when handlers are next flushed.
+func (wc *WorkflowContext) notifyHandlers(host *inventory.Host, handlers ...string) {
+	wc.notifications.mutex.Lock()
+	defer wc.notifications.mutex.Unlock()
+
+	for _, handler := range handlers {
+		hosts, exists := wc.notifications.hosts[handler]
+		if !exists {
+			hosts = set.NewSet[*inventory.Host]()
+			wc.notifications.hosts[handler] = hosts
+		}
+
+		hosts.Add(host)
+	}
+}
+
+// takeNotifiedHosts returns the active hosts on which the handler has been notified and clears them.
+//
+// Notifications for hosts outside the current host filter are kept for a later flush.
+func (wc *WorkflowContext) takeNotifiedHosts(handler string) *set.Set[*inventory.Host] {
+	wc.notifications.mutex.Lock()
+	defer wc.notifications.mutex.Unlock()
+
+	taken := set.NewSet[*inventory.Host]()
+
+	hosts, exists := wc.notifications.hosts[handler]
+	if !exists {
+		return taken
+	}
+
+	for _, host := range hosts.Items() {
+		if wc.hostFilter != nil && !wc.hostFilter.Contains(host) {
+			continue
+		}
+
+		hosts.Remove(host)
+		if !wc.IsFailed(host) {
+			taken.Add(host)
+		}
+	}
+
+	return taken
+}
+
+// clearNotifications removes all pending handler notifications.
+func (wc *WorkflowContext) clearNotifications() {
+	wc.notifications.mutex.Lock()
+	defer wc.notifications.mutex.Unlock()
+
+	clear(wc.notifications.hosts)
+}
+
+// FlushHandlers runs the notified handlers of a process on the hosts where they were notified.
+//
+// This represents a flush_handlers block within a process block. Handlers are also flushed at the end of a process.
+type FlushHandlers struct {
+	handlers []Step
+}
+
+// ID implements Step.
+func (f *FlushHandlers) ID() string {
+	return "flush_handlers"
+}
+
+// Handlers returns a clone of the slice of handlers that are flushed.
+//
+// This is used primarily for testing purposes.
+func (f *FlushHandlers) Handlers() []Step {
+	return slices.Clone(f.handlers)
+}
+
+// Run implements Step.
+//
+// The outputs of the handlers are stored in each host's step context under the handler's ID, so the returned map is
+// always empty.
+func (f *FlushHandlers) Run(wc *WorkflowContext) (map[string]cty.Value, error) {
+	_, err := f.runSteps(wc)
+	return map[string]cty.Value{}, err
+}
+
+// runSteps implements stepGroup.
+//
+// Each notified handler runs in the order they are defined.
+// Handlers can notify other handlers, so this repeats until no handler is pending.
+func (f *FlushHandlers) runSteps(wc *WorkflowContext) (map[string]map[string]cty.Value, error) {
+	outputs := make(map[string]map[string]cty.Value)
+
+	var err error
+	for range len(f.handlers) + 1 {
+		ran := false
+		for _, handler := range f.handlers {
+			stopErr := wc.stopped()
+			if stopErr != nil {
+				return outputs, errors.Join(err, stopErr)
+			}
+
+			hosts := wc.takeNotifiedHosts(handler.ID())
+			if hosts.IsEmpty() {
+				continue
+			}
+
+			ran = true
+
+			output, handlerErr := handler.Run(wc.withHostFilter(hosts))
+			err = errors.Join(err, handlerErr)
+
+			if outputs[handler.ID()] == nil {
+				outputs[handler.ID()] = make(map[string]cty.Value, len(output))
+			}
+
+			for host, value := range output {
+				outputs[handler.ID()][host] = value
+			}
+		}
+
+		if !ran {
+			break
+		}
+	}
+
+	return outputs, err
+}
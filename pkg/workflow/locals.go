@@ -0,0 +1,200 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/set"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// withLocals returns a copy of the WorkflowContext with the given locals added to its locals.
+//
+// The failed hosts are shared with the original WorkflowContext.
+func (wc *WorkflowContext) withLocals(locals map[string]*hcl.Attribute) *WorkflowContext {
+	if len(locals) == 0 {
+		return wc
+	}
+
+	child := *wc
+	child.locals = make(map[string]*hcl.Attribute, len(wc.locals)+len(locals))
+	maps.Copy(child.locals, wc.locals)
+	maps.Copy(child.locals, locals)
+
+	return &child
+}
+
+// localReferences returns the names of the locals referenced by the attribute's expression.
+func localReferences(attr *hcl.Attribute) []string {
+	references := []string{}
+	for _, traversal := range attr.Expr.Variables() {
+		if traversal.RootName() != "local" || len(traversal) < 2 {
+			continue
+		}
+
+		step, ok := traversal[1].(hcl.TraverseAttr)
+		if ok {
+			references = append(references, step.Name)
+		}
+	}
+
+	return references
+}
+
+// refersToVariable checks whether the attribute's expression refers to the named root variable, either directly or
+// through the locals it references.
+func refersToVariable(attr *hcl.Attribute, locals map[string]*hcl.Attribute, name string) bool {
+	visited := set.NewSet[string]()
+	pending := []*hcl.Attribute{attr}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		for _, traversal := range current.Expr.Variables() {
+			if traversal.RootName() == name {
+				return true
+			}
+		}
+
+		for _, reference := range localReferences(current) {
+			local, exists := locals[reference]
+			if !exists || visited.Contains(reference) {
+				continue
+			}
+
+			visited.Add(reference)
+			pending = append(pending, local)
+		}
+	}
+
+	return false
+}
+
+// validateLocalReferences reports the circular references between the provided locals.
+//
+// Only cycles that include one of the owned locals are reported, so locals shared by several scopes are reported once.
+func validateLocalReferences(locals map[string]*hcl.Attribute, owned *set.Set[string]) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(locals))
+	path := []string{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+
+		references := localReferences(locals[name])
+		slices.Sort(references)
+		for _, reference := range slices.Compact(references) {
+			if _, exists := locals[reference]; !exists {
+				continue
+			}
+
+			switch state[reference] {
+			case unvisited:
+				visit(reference)
+			case visiting:
+				cycle := path[slices.Index(path, reference):]
+				diags = diags.Extend(circularLocalDiags(locals, cycle, owned))
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+	}
+
+	names := slices.Sorted(maps.Keys(locals))
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+
+	return diags
+}
+
+func circularLocalDiags(locals map[string]*hcl.Attribute, cycle []string, owned *set.Set[string]) hcl.Diagnostics {
+	index := slices.IndexFunc(cycle, owned.Contains)
+	if index < 0 {
+		return nil
+	}
+
+	chain := make([]string, 0, len(cycle)+1)
+	for _, name := range cycle {
+		chain = append(chain, "local."+name)
+	}
+
+	chain = append(chain, "local."+cycle[0])
+
+	return hcl.Diagnostics{&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Circular local reference",
+		Detail: fmt.Sprintf(
+			"The local %q refers to itself through %s.",
+			cycle[index],
+			strings.Join(chain, " -> "),
+		),
+		Subject: &locals[cycle[index]].Range,
+	}}
+}
+
+// evaluateLocals evaluates the locals against the provided evaluation context.
+//
+// Locals can reference each other, so each pass evaluates the locals whose dependencies have been evaluated until no
+// further progress is made.
+// The evaluated locals are added to the evaluation context as local.*.
+func evaluateLocals(locals map[string]*hcl.Attribute, evalCtx *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	evaluatedLocals := make(map[string]cty.Value, len(locals))
+	pendingLocals := maps.Clone(locals)
+	pendingDiags := make(map[string]hcl.Diagnostics)
+
+	for len(pendingLocals) > 0 {
+		evalCtx.Variables["local"] = cty.ObjectVal(evaluatedLocals)
+
+		progressMade := false
+		for name, attr := range pendingLocals {
+			value, moreDiags := attr.Expr.Value(evalCtx)
+			if moreDiags.HasErrors() {
+				pendingDiags[name] = moreDiags
+				continue
+			}
+
+			diags = diags.Extend(moreDiags)
+			evaluatedLocals[name] = value
+			delete(pendingLocals, name)
+			delete(pendingDiags, name)
+			progressMade = true
+		}
+
+		if !progressMade {
+			break
+		}
+	}
+
+	evalCtx.Variables["local"] = cty.ObjectVal(evaluatedLocals)
+
+	for _, name := range slices.Sorted(maps.Keys(pendingLocals)) {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unresolvable local",
+			Detail:   fmt.Sprintf("The local %q could not be resolved: %s", name, pendingDiags[name].Error()),
+			Subject:  &pendingLocals[name].Range,
+		})
+	}
+
+	return diags
+}
@@ -0,0 +1,303 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/hclfunction"
+	"github.com/trippsoft/forge/pkg/hclutil"
+	"github.com/trippsoft/forge/pkg/secret"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Output represents an output of a workflow, which is evaluated after the workflow runs.
+//
+// This represents a top-level output block within a workflow file.
+type Output struct {
+	name        string
+	value       *hcl.Attribute
+	description string
+	sensitive   bool
+}
+
+// Name returns the name of the output.
+//
+// This is used primarily for testing purposes.
+func (o *Output) Name() string {
+	return o.name
+}
+
+// Value returns the HCL attribute representing the value of the output.
+//
+// This is used primarily for testing purposes.
+func (o *Output) Value() *hcl.Attribute {
+	return o.value
+}
+
+// Description returns the description of the output.
+//
+// This is used primarily for testing purposes.
+func (o *Output) Description() string {
+	return o.description
+}
+
+// Sensitive indicates whether the value of the output is filtered from output.
+//
+// This is used primarily for testing purposes.
+func (o *Output) Sensitive() bool {
+	return o.sensitive
+}
+
+// EvaluateOutputs evaluates the outputs of the workflow against the step outputs returned by its run.
+//
+// Within the value of an output, steps.<id>.<host> refers to the output of a step on a host, where later processes
+// take precedence over earlier ones with the same step ID. variable.* and hostvars are also available.
+// The values of sensitive outputs are registered with the secret filter.
+func (w *Workflow) EvaluateOutputs(
+	wc *WorkflowContext,
+	stepOutputs []map[string]map[string]cty.Value,
+) (map[string]cty.Value, hcl.Diagnostics) {
+
+	diags := hcl.Diagnostics{}
+	values := make(map[string]cty.Value, len(w.outputs))
+	if len(w.outputs) == 0 {
+		return values, diags
+	}
+
+	merged := make(map[string]map[string]cty.Value)
+	for _, outputs := range stepOutputs {
+		mergeOutputs(merged, outputs)
+	}
+
+	steps := make(map[string]cty.Value, len(merged))
+	for id, hostOutputs := range merged {
+		hosts := make(map[string]cty.Value, len(hostOutputs))
+		for host, output := range hostOutputs {
+			if !output.Type().Equals(cty.NilType) {
+				hosts[host] = output
+			}
+		}
+
+		steps[id] = cty.ObjectVal(hosts)
+	}
+
+	wc.LoadHostVars()
+
+	evalCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"hostvars": cty.ObjectVal(wc.hostVars),
+			"steps":    cty.ObjectVal(steps),
+		},
+		Functions: hclfunction.HCLFunctions(wc.workingDir),
+	}
+
+	if len(wc.variables) > 0 {
+		evalCtx.Variables["variable"] = cty.ObjectVal(wc.variables)
+	}
+
+	for _, output := range w.outputs {
+		value, moreDiags := output.value.Expr.Value(evalCtx)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		if output.sensitive {
+			for _, s := range hclutil.GetAllCtyStrings(value) {
+				secret.SecretFilter.AddSecret(s)
+			}
+		}
+
+		values[output.name] = value
+	}
+
+	return values, diags
+}
+
+// RunResult is the machine-readable result of a workflow run.
+//
+// Errors from evaluating the outputs are reported separately from the error of the run. Secrets are filtered from
+// every error.
+type RunResult struct {
+	Succeeded    bool                     `json:"succeeded"`
+	Error        string                   `json:"error,omitempty"`
+	OutputErrors []string                 `json:"output_errors,omitempty"`
+	Outputs      map[string]*OutputResult `json:"outputs"`
+	Processes    []*ProcessResult         `json:"processes"`
+}
+
+// OutputResult is the value of a workflow output in a RunResult.
+type OutputResult struct {
+	Value     json.RawMessage `json:"value"`
+	Type      json.RawMessage `json:"type"`
+	Sensitive bool            `json:"sensitive"`
+}
+
+// ProcessResult is the status of each step of a process on each host in a RunResult.
+type ProcessResult struct {
+	Name  string                            `json:"name"`
+	Steps map[string]map[string]*StepStatus `json:"steps"`
+}
+
+// StepStatus is the status of a step on a host in a RunResult.
+//
+// The status of a looped step is failed or changed if any iteration is, and skipped if every iteration is.
+type StepStatus struct {
+	Failed  bool     `json:"failed"`
+	Skipped bool     `json:"skipped"`
+	Changed bool     `json:"changed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// NewRunResult creates the machine-readable result of a workflow run from the step outputs and error returned by its
+// run, and the values and diagnostics returned by evaluating its outputs.
+//
+// An output that cannot be serialized is left out of the result, and its error is added to the output errors.
+func (w *Workflow) NewRunResult(
+	stepOutputs []map[string]map[string]cty.Value,
+	outputs map[string]cty.Value,
+	outputDiags hcl.Diagnostics,
+	err error,
+) *RunResult {
+
+	r := &RunResult{
+		Succeeded: err == nil,
+		Outputs:   make(map[string]*OutputResult, len(outputs)),
+		Processes: make([]*ProcessResult, 0, len(stepOutputs)),
+	}
+
+	if err != nil {
+		r.Error = secret.SecretFilter.Filter(err.Error())
+	}
+
+	for _, diag := range outputDiags {
+		if diag.Severity == hcl.DiagError {
+			r.OutputErrors = append(r.OutputErrors, secret.SecretFilter.Filter(diag.Error()))
+		}
+	}
+
+	for _, output := range w.outputs {
+		value, exists := outputs[output.name]
+		if !exists {
+			continue
+		}
+
+		valueJSON, e := ctyjson.Marshal(value, value.Type())
+		if e != nil {
+			e = fmt.Errorf("failed to serialize output %q: %w", output.name, e)
+			r.OutputErrors = append(r.OutputErrors, secret.SecretFilter.Filter(e.Error()))
+			continue
+		}
+
+		typeJSON, e := ctyjson.MarshalType(value.Type())
+		if e != nil {
+			e = fmt.Errorf("failed to serialize the type of output %q: %w", output.name, e)
+			r.OutputErrors = append(r.OutputErrors, secret.SecretFilter.Filter(e.Error()))
+			continue
+		}
+
+		r.Outputs[output.name] = &OutputResult{
+			Value:     valueJSON,
+			Type:      typeJSON,
+			Sensitive: output.sensitive,
+		}
+	}
+
+	for i, processOutputs := range stepOutputs {
+		process := &ProcessResult{
+			Steps: make(map[string]map[string]*StepStatus, len(processOutputs)),
+		}
+
+		if i < len(w.processes) {
+			process.Name = w.processes[i].name
+		}
+
+		for id, hostOutputs := range processOutputs {
+			process.Steps[id] = make(map[string]*StepStatus, len(hostOutputs))
+			for host, output := range hostOutputs {
+				if output.Type().Equals(cty.NilType) {
+					continue
+				}
+
+				process.Steps[id][host] = newStepStatus(output)
+			}
+		}
+
+		r.Processes = append(r.Processes, process)
+	}
+
+	return r
+}
+
+// WriteFile writes the run result to a JSON file.
+//
+// The file is only readable by its owner, as it contains the values of sensitive outputs.
+func (r *RunResult) WriteFile(path string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize run result: %w", err)
+	}
+
+	err = os.WriteFile(path, append(content, '\n'), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write run result %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// newStepStatus returns the status of a step from its output on a host.
+func newStepStatus(output cty.Value) *StepStatus {
+	if output.IsNull() || !output.IsWhollyKnown() {
+		return &StepStatus{}
+	}
+
+	outputType := output.Type()
+	if outputType.IsObjectType() && outputType.HasAttribute("failed") {
+		status := &StepStatus{
+			Failed:  outputFlag(output, "failed"),
+			Skipped: outputFlag(output, "skipped"),
+			Changed: outputFlag(output, "changed"),
+		}
+
+		if outputType.HasAttribute("error") && output.GetAttr("error").Type().Equals(cty.String) {
+			status.Errors = []string{secret.SecretFilter.Filter(output.GetAttr("error").AsString())}
+		}
+
+		return status
+	}
+
+	if !outputType.IsTupleType() && !outputType.IsObjectType() {
+		return &StepStatus{}
+	}
+
+	status := &StepStatus{Skipped: true}
+	for _, iteration := range output.AsValueSlice() {
+		iterationStatus := newStepStatus(iteration)
+		status.Failed = status.Failed || iterationStatus.Failed
+		status.Changed = status.Changed || iterationStatus.Changed
+		status.Skipped = status.Skipped && iterationStatus.Skipped
+		status.Errors = append(status.Errors, iterationStatus.Errors...)
+	}
+
+	status.Errors = slices.Compact(status.Errors)
+
+	return status
+}
+
+// outputFlag returns the value of a boolean attribute of a step output, or false if it is not a known boolean.
+func outputFlag(output cty.Value, name string) bool {
+	value := output.GetAttr(name)
+	if value.IsNull() || !value.IsKnown() || !value.Type().Equals(cty.Bool) {
+		return false
+	}
+
+	return value.True()
+}
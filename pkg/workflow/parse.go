@@ -4,15 +4,26 @@
 package workflow
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/trippsoft/forge/pkg/hclutil"
 	"github.com/trippsoft/forge/pkg/inventory"
 	"github.com/trippsoft/forge/pkg/module"
 	"github.com/trippsoft/forge/pkg/set"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
 )
 
 // Parser is responsible for parsing workflow files.
@@ -20,6 +31,12 @@ type Parser struct {
 	inventory      *inventory.Inventory
 	parser         *hclparse.Parser
 	moduleRegistry *module.Registry
+
+	tagFilter  *TagFilter
+	limit      *set.Set[*inventory.Host]
+	targetsCtx *hcl.EvalContext
+
+	files []string // Stack of files being parsed, used to resolve procedure sources and detect cycles.
 }
 
 // NewParser creates a new Parser instance.
@@ -31,8 +48,23 @@ func NewParser(inventory *inventory.Inventory, moduleRegistry *module.Registry)
 	}
 }
 
+// WithTagFilter sets the filter that selects the steps of each parsed process by their tags.
+func (p *Parser) WithTagFilter(filter *TagFilter) *Parser {
+	p.tagFilter = filter
+	return p
+}
+
+// WithLimit restricts the targets of every process and step to the given hosts.
+func (p *Parser) WithLimit(hosts []*inventory.Host) *Parser {
+	p.limit = set.NewSet(hosts...)
+	return p
+}
+
 // ParseWorkflowFile parses a workflow file from the given path and content.
 func (p *Parser) ParseWorkflowFile(path string, content []byte) (*Workflow, hcl.Diagnostics) {
+	p.pushFile(path)
+	defer p.popFile()
+
 	file, diags := p.parser.ParseHCL(content, path)
 	if diags.HasErrors() {
 		return nil, diags
@@ -45,13 +77,38 @@ func (p *Parser) ParseWorkflowFile(path string, content []byte) (*Workflow, hcl.
 		return nil, diags
 	}
 
+	variables, moreDiags := p.parseVariableBlocks(bodyContent)
+	diags = diags.Extend(moreDiags)
+
+	locals, moreDiags := p.parseLocalsBlocks(bodyContent.Blocks)
+	diags = diags.Extend(moreDiags)
+	if !moreDiags.HasErrors() {
+		diags = diags.Extend(p.validateLocals(nil, locals))
+	}
+
+	outputs, moreDiags := p.parseOutputBlocks(bodyContent)
+	diags = diags.Extend(moreDiags)
+
 	processes, moreDiags := p.parseProcessBlocks(bodyContent)
 	diags = diags.Extend(moreDiags)
 	if diags.HasErrors() {
 		return nil, diags
 	}
 
-	workflow, err := NewWorkflowBuilder().AddProcess(processes...).Build()
+	for _, process := range processes {
+		diags = diags.Extend(p.validateLocals(locals, process.locals))
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	workflow, err := NewWorkflowBuilder().
+		AddVariable(variables...).
+		WithLocals(locals).
+		AddProcess(processes...).
+		AddOutput(outputs...).
+		Build()
 	if err != nil {
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -63,11 +120,279 @@ func (p *Parser) ParseWorkflowFile(path string, content []byte) (*Workflow, hcl.
 	return workflow, diags
 }
 
+func (p *Parser) parseVariableBlocks(content *hcl.BodyContent) ([]*Variable, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	variables := []*Variable{}
+	names := set.NewSet[string]()
+
+	for _, block := range content.Blocks {
+		if block.Type != "variable" {
+			continue
+		}
+
+		if names.Contains(block.Labels[0]) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate variable",
+				Detail:   fmt.Sprintf("The variable %q is declared multiple times.", block.Labels[0]),
+				Subject:  &block.DefRange,
+			})
+			continue
+		}
+
+		names.Add(block.Labels[0])
+
+		variable, moreDiags := p.parseVariableBlock(block)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		variables = append(variables, variable)
+	}
+
+	return variables, diags
+}
+
+func (p *Parser) parseVariableBlock(block *hcl.Block) (*Variable, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := block.Body.Content(variableBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a variable block")
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	variable := &Variable{
+		name:         block.Labels[0],
+		varType:      cty.DynamicPseudoType,
+		defaultValue: cty.NullVal(cty.DynamicPseudoType),
+		required:     true,
+		validations:  []*VariableValidation{},
+	}
+
+	if attr, exists := content.Attributes["type"]; exists {
+		varType, moreDiags := typeexpr.TypeConstraint(attr.Expr)
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() {
+			variable.varType = varType
+			variable.defaultValue = cty.NullVal(varType)
+		}
+	}
+
+	if attr, exists := content.Attributes["description"]; exists {
+		description, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
+		diags = diags.Extend(moreDiags)
+		variable.description = description
+	}
+
+	if attr, exists := content.Attributes["sensitive"]; exists {
+		sensitive, moreDiags := hclutil.ConvertHCLAttributeToBool(attr, nil)
+		diags = diags.Extend(moreDiags)
+		variable.sensitive = sensitive
+	}
+
+	if attr, exists := content.Attributes["default"]; exists && !diags.HasErrors() {
+		value, moreDiags := attr.Expr.Value(nil)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil, diags
+		}
+
+		value, err := convert.Convert(value, variable.varType)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid default value",
+				Detail: fmt.Sprintf(
+					"The default value of variable %q is not a valid %s: %s",
+					variable.name,
+					variable.varType.FriendlyName(),
+					err.Error(),
+				),
+				Subject: attr.Expr.Range().Ptr(),
+			})
+		}
+
+		variable.defaultValue = value
+		variable.required = false
+	}
+
+	for _, childBlock := range content.Blocks {
+		childContent, moreDiags := childBlock.Body.Content(variableValidationBlockSchema)
+		hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a validation block")
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		errorMessage, moreDiags := hclutil.ConvertHCLAttributeToString(childContent.Attributes["error_message"], nil)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		variable.validations = append(variable.validations, &VariableValidation{
+			condition:    childContent.Attributes["condition"],
+			errorMessage: errorMessage,
+		})
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return variable, diags
+}
+
+// parseLocalsBlocks parses the attributes of every locals block among the provided blocks.
+func (p *Parser) parseLocalsBlocks(blocks []*hcl.Block) (map[string]*hcl.Attribute, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	locals := make(map[string]*hcl.Attribute)
+
+	for _, block := range blocks {
+		if block.Type != "locals" {
+			continue
+		}
+
+		attrs, moreDiags := block.Body.JustAttributes()
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		for name, attr := range attrs {
+			if _, exists := locals[name]; exists {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate local",
+					Detail:   fmt.Sprintf("The local %q is defined multiple times.", name),
+					Subject:  &attr.NameRange,
+				})
+				continue
+			}
+
+			locals[name] = attr
+		}
+	}
+
+	return locals, diags
+}
+
+// validateLocals checks that the locals do not redefine the outer locals or reference each other circularly.
+//
+// The outer locals are the workflow locals when validating the locals of a process, and nil otherwise.
+func (p *Parser) validateLocals(
+	outerLocals map[string]*hcl.Attribute,
+	locals map[string]*hcl.Attribute,
+) hcl.Diagnostics {
+
+	diags := hcl.Diagnostics{}
+	if len(locals) == 0 {
+		return diags
+	}
+
+	combined := make(map[string]*hcl.Attribute, len(outerLocals)+len(locals))
+	maps.Copy(combined, outerLocals)
+	for name, attr := range locals {
+		if _, exists := outerLocals[name]; exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate local",
+				Detail:   fmt.Sprintf("The local %q is already defined at the top level of the workflow.", name),
+				Subject:  &attr.NameRange,
+			})
+			continue
+		}
+
+		combined[name] = attr
+	}
+
+	if diags.HasErrors() {
+		return diags
+	}
+
+	return validateLocalReferences(combined, set.NewSet(slices.Collect(maps.Keys(locals))...))
+}
+
+func (p *Parser) parseOutputBlocks(content *hcl.BodyContent) ([]*Output, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	outputs := []*Output{}
+	names := set.NewSet[string]()
+
+	for _, block := range content.Blocks {
+		if block.Type != "output" {
+			continue
+		}
+
+		if names.Contains(block.Labels[0]) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate output",
+				Detail:   fmt.Sprintf("The output %q is declared multiple times.", block.Labels[0]),
+				Subject:  &block.DefRange,
+			})
+			continue
+		}
+
+		names.Add(block.Labels[0])
+
+		output, moreDiags := p.parseWorkflowOutputBlock(block)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		outputs = append(outputs, output)
+	}
+
+	return outputs, diags
+}
+
+func (p *Parser) parseWorkflowOutputBlock(block *hcl.Block) (*Output, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := block.Body.Content(workflowOutputBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in an output block")
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	output := &Output{
+		name:  block.Labels[0],
+		value: content.Attributes["value"],
+	}
+
+	if attr, exists := content.Attributes["description"]; exists {
+		description, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
+		diags = diags.Extend(moreDiags)
+		output.description = description
+	}
+
+	if attr, exists := content.Attributes["sensitive"]; exists {
+		sensitive, moreDiags := hclutil.ConvertHCLAttributeToBool(attr, nil)
+		diags = diags.Extend(moreDiags)
+		output.sensitive = sensitive
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return output, diags
+}
+
 func (p *Parser) parseProcessBlocks(content *hcl.BodyContent) ([]*ProcessBuilder, hcl.Diagnostics) {
 
 	processes := make([]*ProcessBuilder, 0, len(content.Blocks))
 	diags := hcl.Diagnostics{}
 	for _, block := range content.Blocks {
+		if block.Type != "process" {
+			continue
+		}
+
 		process, moreDiags := p.parseProcessBlock(block)
 		diags = diags.Extend(moreDiags)
 		if moreDiags.HasErrors() {
@@ -112,7 +437,7 @@ func (p *Parser) parseProcessBlock(block *hcl.Block) (*ProcessBuilder, hcl.Diagn
 		return nil, diags
 	}
 
-	builder := NewProcessBuilder()
+	builder := NewProcessBuilder().WithTagFilter(p.tagFilter)
 
 	foundEscalate := false
 	for _, childBlock := range content.Blocks {
@@ -147,6 +472,7 @@ func (p *Parser) parseProcessBlock(block *hcl.Block) (*ProcessBuilder, hcl.Diagn
 		builder.WithCommon(common)
 	}
 
+	handlerIDs := set.NewSet[string]()
 	for _, block := range content.Blocks {
 		switch block.Type {
 		case "step":
@@ -159,15 +485,64 @@ func (p *Parser) parseProcessBlock(block *hcl.Block) (*ProcessBuilder, hcl.Diagn
 			builder.AddStep(step)
 
 		case "procedure":
-			diags = diags.Append(&hcl.Diagnostic{
-				Severity: hcl.DiagWarning,
-				Summary:  "Procedure blocks are not yet supported",
-				Detail:   "Procedure blocks are currently not implemented. Skipping this block.",
-				Subject:  &block.TypeRange,
-			})
+			procedure, moreDiags := p.parseProcedureBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.AddStep(procedure)
+
+		case "block":
+			blockStep, moreDiags := p.parseBlockBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.AddStep(blockStep)
+
+		case "handler":
+			if handlerIDs.Contains(block.Labels[0]) {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate handler",
+					Detail:   fmt.Sprintf("A handler with the id %q is already defined in this process.", block.Labels[0]),
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+
+			handlerIDs.Add(block.Labels[0])
+
+			handler, moreDiags := p.parseStepBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.AddHandler(handler.(*SingleStepBuilder))
+
+		case "flush_handlers":
+			_, moreDiags := block.Body.Content(&hcl.BodySchema{})
+			hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a flush_handlers block")
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.AddFlushHandlers()
 		}
 	}
 
+	locals, moreDiags := p.parseLocalsBlocks(content.Blocks)
+	diags = diags.Extend(moreDiags)
+	if !moreDiags.HasErrors() {
+		builder.WithLocals(locals)
+	}
+
+	diags = diags.Extend(p.validateNotifications(builder, handlerIDs))
+
 	for name, attr := range content.Attributes {
 		switch name {
 		case "discover_info":
@@ -176,6 +551,20 @@ func (p *Parser) parseProcessBlock(block *hcl.Block) (*ProcessBuilder, hcl.Diagn
 			if !moreDiags.HasErrors() {
 				builder.WithDiscoverInfo(discoverInfo)
 			}
+
+		case "serial":
+			serial, moreDiags := p.parseSerialAttribute(attr)
+			diags = diags.Extend(moreDiags)
+			if !moreDiags.HasErrors() {
+				builder.WithSerial(serial...)
+			}
+
+		case "max_fail_percentage":
+			maxFailPercentage, moreDiags := p.parseMaxFailPercentageAttribute(attr)
+			diags = diags.Extend(moreDiags)
+			if !moreDiags.HasErrors() {
+				builder.WithMaxFailPercentage(maxFailPercentage)
+			}
 		}
 	}
 
@@ -197,32 +586,186 @@ func (p *Parser) parseProcessBlock(block *hcl.Block) (*ProcessBuilder, hcl.Diagn
 	return builder, diags
 }
 
-func (p *Parser) parseEscalateBlock(block *hcl.Block) (*StepEscalateConfig, hcl.Diagnostics) {
+// validateNotifications checks that every handler notified by a step or handler of the process is defined.
+func (p *Parser) validateNotifications(builder *ProcessBuilder, handlerIDs *set.Set[string]) hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
-	if block == nil {
-		return nil, diags
+
+	notifiers := make([]handlerNotifier, 0, len(builder.steps)+len(builder.handlers))
+	for _, sb := range builder.steps {
+		if notifier, ok := sb.(handlerNotifier); ok {
+			notifiers = append(notifiers, notifier)
+		}
 	}
 
-	if block.Type != "escalate" {
-		return nil, diags.Append(&hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Invalid block type",
-			Detail:   "Expected 'escalate' block.",
-			Subject:  &block.TypeRange,
-		})
+	for _, handler := range builder.handlers {
+		notifiers = append(notifiers, handler)
 	}
 
-	if len(block.Labels) != 0 {
-		diags = diags.Append(&hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Invalid block labels",
-			Detail:   "Expected no labels for 'escalate' block.",
-			Subject:  &block.TypeRange,
-		})
+	for _, notifier := range notifiers {
+		for _, notification := range notifier.notifications() {
+			if handlerIDs.Contains(notification.handler) {
+				continue
+			}
+
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Handler not found",
+				Detail:   fmt.Sprintf("The handler %q is not defined in this process.", notification.handler),
+				Subject:  notification.subject,
+			})
+		}
 	}
 
-	content, moreDiags := block.Body.Content(escalateBlockSchema)
-	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in an escalate block")
+	return diags
+}
+
+func (p *Parser) parseSerialAttribute(attr *hcl.Attribute) ([]*BatchSize, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	value, moreDiags := attr.Expr.Value(nil)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	if value.IsNull() || !value.IsWhollyKnown() {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid serial",
+			Detail:   "The 'serial' attribute must be a known, non-null value.",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+
+	valueType := value.Type()
+	if !valueType.IsListType() && !valueType.IsTupleType() {
+		batchSize, moreDiags := parseBatchSize(value, attr)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil, diags
+		}
+
+		return []*BatchSize{batchSize}, diags
+	}
+
+	if value.LengthInt() == 0 {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid serial",
+			Detail:   "The 'serial' attribute must contain at least one batch size.",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+
+	serial := make([]*BatchSize, 0, value.LengthInt())
+	it := value.ElementIterator()
+	for it.Next() {
+		_, elem := it.Element()
+		batchSize, moreDiags := parseBatchSize(elem, attr)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		serial = append(serial, batchSize)
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return serial, diags
+}
+
+// parseBatchSize parses a single batch size, which is either a number of hosts or a percentage string like "25%".
+func parseBatchSize(value cty.Value, attr *hcl.Attribute) (*BatchSize, hcl.Diagnostics) {
+	invalid := hcl.Diagnostics{&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid batch size",
+		Detail: "Each batch size in the 'serial' attribute must be a positive whole number or " +
+			"a percentage string between \"1%\" and \"100%\".",
+		Subject: attr.Expr.Range().Ptr(),
+	}}
+
+	if value.IsNull() {
+		return nil, invalid
+	}
+
+	if value.Type().Equals(cty.Number) {
+		count, accuracy := value.AsBigFloat().Int64()
+		if accuracy != big.Exact || count < 1 {
+			return nil, invalid
+		}
+
+		return NewBatchSize(int(count)), nil
+	}
+
+	if !value.Type().Equals(cty.String) {
+		return nil, invalid
+	}
+
+	percentageString, isPercentage := strings.CutSuffix(strings.TrimSpace(value.AsString()), "%")
+	if !isPercentage {
+		return nil, invalid
+	}
+
+	percentage, err := strconv.Atoi(strings.TrimSpace(percentageString))
+	if err != nil || percentage < 1 || percentage > 100 {
+		return nil, invalid
+	}
+
+	return NewBatchSizePercentage(percentage), nil
+}
+
+func (p *Parser) parseMaxFailPercentageAttribute(attr *hcl.Attribute) (float64, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	value, moreDiags := attr.Expr.Value(nil)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return 0, diags
+	}
+
+	var maxFailPercentage float64
+	err := gocty.FromCtyValue(value, &maxFailPercentage)
+	if err != nil || maxFailPercentage < 0 || maxFailPercentage > 100 {
+		return 0, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid max_fail_percentage",
+			Detail:   "The 'max_fail_percentage' attribute must be a number between 0 and 100.",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+
+	return maxFailPercentage, diags
+}
+
+func (p *Parser) parseEscalateBlock(block *hcl.Block) (*StepEscalateConfig, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	if block == nil {
+		return nil, diags
+	}
+
+	if block.Type != "escalate" {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid block type",
+			Detail:   "Expected 'escalate' block.",
+			Subject:  &block.TypeRange,
+		})
+	}
+
+	if len(block.Labels) != 0 {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid block labels",
+			Detail:   "Expected no labels for 'escalate' block.",
+			Subject:  &block.TypeRange,
+		})
+	}
+
+	content, moreDiags := block.Body.Content(escalateBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in an escalate block")
 	diags = diags.Extend(moreDiags)
 	if moreDiags.HasErrors() {
 		return nil, diags
@@ -326,6 +869,14 @@ func (p *Parser) parseCommonElements(content *hcl.BodyContent) (*StepCommonConfi
 			config.execTimeout = attr
 		case "what_if":
 			config.whatIf = attr
+		case "tags":
+			tags, moreDiags := p.parseTagsAttribute(attr)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			config.tags = tags
 		}
 	}
 
@@ -336,6 +887,41 @@ func (p *Parser) parseCommonElements(content *hcl.BodyContent) (*StepCommonConfi
 	return config, diags
 }
 
+func (p *Parser) parseTagsAttribute(attr *hcl.Attribute) ([]string, hcl.Diagnostics) {
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	value, err := convert.Convert(value, cty.List(cty.String))
+	if err != nil || value.IsNull() || !value.IsWhollyKnown() {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid tags",
+			Detail:   "The 'tags' attribute must be a list of strings.",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+
+	tags := []string{}
+	for _, tag := range value.AsValueSlice() {
+		if tag.IsNull() || tag.AsString() == "" {
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid tags",
+				Detail:   "Each tag in the 'tags' attribute must be a non-empty string.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+
+		if !slices.Contains(tags, tag.AsString()) {
+			tags = append(tags, tag.AsString())
+		}
+	}
+
+	return tags, diags
+}
+
 func (p *Parser) parseLoopBlock(block *hcl.Block) (*StepLoopConfig, hcl.Diagnostics) {
 	diags := hcl.Diagnostics{}
 	if block == nil {
@@ -425,10 +1011,61 @@ func (p *Parser) parseInputBlock(block *hcl.Block) (map[string]*hcl.Attribute, h
 	return input, diags
 }
 
+// resolveTargetPattern resolves a host pattern of the targets attribute to the hosts it selects.
+func (p *Parser) resolveTargetPattern(pattern string, attr *hcl.Attribute) ([]*inventory.Host, hcl.Diagnostics) {
+	hosts, err := p.inventory.ResolvePattern(pattern)
+	if err == nil {
+		return hosts, nil
+	}
+
+	var notFound *inventory.TargetNotFoundError
+	if errors.As(err, &notFound) {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Target not found",
+			Detail:   fmt.Sprintf("The target %q does not exist in the inventory", notFound.Target),
+			Subject:  attr.Expr.Range().Ptr(),
+		}}
+	}
+
+	return nil, hcl.Diagnostics{&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid target pattern",
+		Detail:   fmt.Sprintf("The target pattern %q is invalid: %s", pattern, err.Error()),
+		Subject:  attr.Expr.Range().Ptr(),
+	}}
+}
+
+// limitTargets removes the hosts outside the limit of the parser, if any.
+func (p *Parser) limitTargets(hosts []*inventory.Host) []*inventory.Host {
+	if p.limit == nil {
+		return hosts
+	}
+
+	limited := make([]*inventory.Host, 0, len(hosts))
+	for _, host := range hosts {
+		if p.limit.Contains(host) {
+			limited = append(limited, host)
+		}
+	}
+
+	return limited
+}
+
 func (p *Parser) parseTargetsAttribute(attr *hcl.Attribute) ([]*inventory.Host, hcl.Diagnostics) {
 	diags := hcl.Diagnostics{}
 
-	targetsValue, moreDiags := attr.Expr.Value(nil)
+	evalCtx, err := p.targetsEvalContext()
+	if err != nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to get working directory",
+			Detail:   err.Error(),
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+	}
+
+	targetsValue, moreDiags := attr.Expr.Value(evalCtx)
 	diags = diags.Extend(moreDiags)
 	if moreDiags.HasErrors() {
 		return nil, diags
@@ -456,19 +1093,13 @@ func (p *Parser) parseTargetsAttribute(attr *hcl.Attribute) ([]*inventory.Host,
 		return nil, diags
 
 	case targetsValue.Type().Equals(cty.String):
-		target, exists := p.inventory.Target(targetsValue.AsString())
-		if exists {
-			return target, diags
+		target, moreDiags := p.resolveTargetPattern(targetsValue.AsString(), attr)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil, diags
 		}
 
-		diags = diags.Append(&hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Target not found",
-			Detail:   fmt.Sprintf("The target %q does not exist in the inventory", targetsValue.AsString()),
-			Subject:  attr.Expr.Range().Ptr(),
-		})
-
-		return nil, diags
+		return p.limitTargets(target), diags
 
 	case targetsValue.Type().IsListType() || targetsValue.Type().IsSetType() || targetsValue.Type().IsTupleType():
 		it := targetsValue.ElementIterator()
@@ -503,18 +1134,10 @@ func (p *Parser) parseTargetsAttribute(attr *hcl.Attribute) ([]*inventory.Host,
 
 			seenTargets.Add(targetName)
 
-			target, exists := p.inventory.Target(targetName)
-			if exists {
-				for _, host := range target {
-					targetHosts.Add(host)
-				}
-			} else {
-				diags = diags.Append(&hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Target not found",
-					Detail:   fmt.Sprintf("The target %q does not exist in the inventory", elem.AsString()),
-					Subject:  attr.Expr.Range().Ptr(),
-				})
+			target, moreDiags := p.resolveTargetPattern(targetName, attr)
+			diags = diags.Extend(moreDiags)
+			for _, host := range target {
+				targetHosts.Add(host)
 			}
 		}
 
@@ -522,7 +1145,7 @@ func (p *Parser) parseTargetsAttribute(attr *hcl.Attribute) ([]*inventory.Host,
 			return nil, diags
 		}
 
-		return targetHosts.Items(), diags
+		return p.limitTargets(targetHosts.Items()), diags
 
 	default:
 		diags = diags.Append(&hcl.Diagnostic{
@@ -543,11 +1166,11 @@ func (p *Parser) parseStepBlock(block *hcl.Block) (StepBuilder, hcl.Diagnostics)
 		return nil, diags
 	}
 
-	if block.Type != "step" {
+	if block.Type != "step" && block.Type != "handler" {
 		return nil, hcl.Diagnostics{&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Invalid block type",
-			Detail:   "Expected 'step' block type.",
+			Detail:   "Expected 'step' or 'handler' block type.",
 			Subject:  &block.TypeRange,
 		}}
 	}
@@ -556,13 +1179,13 @@ func (p *Parser) parseStepBlock(block *hcl.Block) (StepBuilder, hcl.Diagnostics)
 		return nil, hcl.Diagnostics{&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Invalid block labels",
-			Detail:   "Expected exactly one label for 'step' block.",
+			Detail:   fmt.Sprintf("Expected exactly one label for '%s' block.", block.Type),
 			Subject:  &block.TypeRange,
 		}}
 	}
 
 	content, moreDiags := block.Body.Content(stepBlockSchema)
-	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a step block")
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, fmt.Sprintf("in a %s block", block.Type))
 	diags = diags.Extend(moreDiags)
 	if moreDiags.HasErrors() {
 		return nil, diags
@@ -585,6 +1208,7 @@ func (p *Parser) parseStepBlock(block *hcl.Block) (StepBuilder, hcl.Diagnostics)
 
 	foundEscalate := false
 	foundOutput := false
+	foundRetry := false
 
 	for _, block := range content.Blocks {
 		switch block.Type {
@@ -629,32 +1253,120 @@ func (p *Parser) parseStepBlock(block *hcl.Block) (StepBuilder, hcl.Diagnostics)
 			}
 
 			builder.WithOutput(output)
+
+		case "retry":
+			if foundRetry {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate retry block",
+					Detail:   "Only one retry block is allowed.",
+					Subject:  &block.TypeRange,
+				})
+				continue
+			}
+
+			foundRetry = true
+
+			retry, moreDiags := p.parseRetryBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.WithRetry(retry)
 		}
 	}
 
 	for name, attr := range content.Attributes {
-		if name != "module" {
-			continue
-		}
+		switch name {
+		case "module":
+			moduleName, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
 
-		moduleName, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
-		diags = diags.Extend(moreDiags)
-		if moreDiags.HasErrors() {
-			continue
+			module, exists := p.moduleRegistry.Lookup(moduleName)
+			if !exists {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Module not found",
+					Detail:   fmt.Sprintf("Module %q not found", moduleName),
+					Subject:  attr.NameRange.Ptr(),
+				})
+				continue
+			}
+
+			builder.WithModule(module)
+
+		case "run_once":
+			runOnce, moreDiags := hclutil.ConvertHCLAttributeToBool(attr, nil)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.WithRunOnce(runOnce)
+
+		case "run_once_host":
+			builder.WithRunOnceHost(attr)
+
+		case "async":
+			builder.WithAsync(attr)
+
+		case "poll":
+			builder.WithPoll(attr)
+
+		case "delegate_to":
+			builder.WithDelegateTo(attr)
+
+		case "throttle":
+			throttle, moreDiags := hclutil.ConvertHCLAttributeToUint16(attr, nil)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.WithThrottle(int(throttle))
+
+		case "notify":
+			notify, moreDiags := p.parseNotifyAttribute(attr)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.WithNotify(attr, notify...)
 		}
+	}
+
+	if builder.runOnceHost != nil && !builder.runOnce && !diags.HasErrors() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid run_once_host",
+			Detail:   "The 'run_once_host' attribute can only be used when 'run_once' is true.",
+			Subject:  builder.runOnceHost.NameRange.Ptr(),
+		})
+	}
+
+	if builder.poll != nil && builder.async == nil && !diags.HasErrors() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid poll",
+			Detail:   "The 'poll' attribute can only be used when 'async' is set.",
+			Subject:  builder.poll.NameRange.Ptr(),
+		})
+	}
 
-		module, exists := p.moduleRegistry.Lookup(moduleName)
-		if !exists {
+	if builder.async != nil && builder.module != nil && !diags.HasErrors() {
+		if _, ok := builder.module.(module.AsyncModule); !ok {
 			diags = diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
-				Summary:  "Module not found",
-				Detail:   fmt.Sprintf("Module %q not found", moduleName),
-				Subject:  attr.NameRange.Ptr(),
+				Summary:  "Invalid async",
+				Detail:   fmt.Sprintf("The module %q cannot run asynchronously.", builder.module.ID().ModuleName()),
+				Subject:  builder.async.NameRange.Ptr(),
 			})
-			continue
 		}
-
-		builder.WithModule(module)
 	}
 
 	if builder.module == nil && !diags.HasErrors() {
@@ -673,6 +1385,49 @@ func (p *Parser) parseStepBlock(block *hcl.Block) (StepBuilder, hcl.Diagnostics)
 	return builder, diags
 }
 
+func (p *Parser) parseNotifyAttribute(attr *hcl.Attribute) ([]string, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	notifyValue, moreDiags := attr.Expr.Value(nil)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	if notifyValue.Type().Equals(cty.String) && !notifyValue.IsNull() && notifyValue.IsWhollyKnown() {
+		return []string{notifyValue.AsString()}, diags
+	}
+
+	notifyList, err := convert.Convert(notifyValue, cty.List(cty.String))
+	if err != nil || notifyList.IsNull() || !notifyList.IsWhollyKnown() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid notify",
+			Detail:   "The 'notify' attribute must be a handler id or a list of handler ids.",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+
+		return nil, diags
+	}
+
+	notify := []string{}
+	for _, handler := range notifyList.AsValueSlice() {
+		if handler.IsNull() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid notify",
+				Detail:   "The 'notify' attribute cannot contain null values.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+
+		notify = append(notify, handler.AsString())
+	}
+
+	return notify, diags
+}
+
 func (p *Parser) parseOutputBlock(block *hcl.Block) (*StepOutputConfig, hcl.Diagnostics) {
 	diags := hcl.Diagnostics{}
 	if block == nil {
@@ -726,3 +1481,556 @@ func (p *Parser) parseOutputBlock(block *hcl.Block) (*StepOutputConfig, hcl.Diag
 
 	return config, diags
 }
+
+func (p *Parser) parseBlockBlock(block *hcl.Block) (StepBuilder, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	if block == nil {
+		return nil, diags
+	}
+
+	if block.Type != "block" {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid block type",
+			Detail:   "Expected 'block' block type.",
+			Subject:  &block.TypeRange,
+		}}
+	}
+
+	if len(block.Labels) != 1 {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid block labels",
+			Detail:   "Expected exactly one label for 'block' block.",
+			Subject:  &block.TypeRange,
+		}}
+	}
+
+	content, moreDiags := block.Body.Content(blockBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a block block")
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	common, moreDiags := p.parseCommonElements(content)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	common.id = block.Labels[0]
+
+	builder := NewBlockBuilder().WithCommon(common)
+
+	foundEscalate := false
+	foundRescue := false
+	foundAlways := false
+	for _, childBlock := range content.Blocks {
+		switch childBlock.Type {
+		case "escalate":
+			if foundEscalate {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate escalate block",
+					Detail:   "Only one escalate block is allowed per block.",
+					Subject:  &childBlock.TypeRange,
+				})
+				continue
+			}
+
+			foundEscalate = true
+
+			escalate, moreDiags := p.parseEscalateBlock(childBlock)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.WithEscalate(escalate)
+
+		case "rescue":
+			if foundRescue {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate rescue block",
+					Detail:   "Only one rescue block is allowed per block.",
+					Subject:  &childBlock.TypeRange,
+				})
+				continue
+			}
+
+			foundRescue = true
+
+			steps, moreDiags := p.parseBlockSection(childBlock)
+			diags = diags.Extend(moreDiags)
+			for _, step := range steps {
+				builder.AddRescueStep(step)
+			}
+
+		case "always":
+			if foundAlways {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate always block",
+					Detail:   "Only one always block is allowed per block.",
+					Subject:  &childBlock.TypeRange,
+				})
+				continue
+			}
+
+			foundAlways = true
+
+			steps, moreDiags := p.parseBlockSection(childBlock)
+			diags = diags.Extend(moreDiags)
+			for _, step := range steps {
+				builder.AddAlwaysStep(step)
+			}
+
+		default:
+			step, moreDiags := p.parseBlockStep(childBlock)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.AddStep(step)
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return builder, diags
+}
+
+// parseBlockSection parses the steps of a rescue or always block within a block block.
+func (p *Parser) parseBlockSection(block *hcl.Block) ([]StepBuilder, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := block.Body.Content(blockSectionSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, fmt.Sprintf("in a %s block", block.Type))
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	steps := make([]StepBuilder, 0, len(content.Blocks))
+	for _, childBlock := range content.Blocks {
+		step, moreDiags := p.parseBlockStep(childBlock)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, diags
+}
+
+// parseBlockStep parses a step, procedure or block block within a block block.
+func (p *Parser) parseBlockStep(block *hcl.Block) (StepBuilder, hcl.Diagnostics) {
+	switch block.Type {
+	case "procedure":
+		return p.parseProcedureBlock(block)
+	case "block":
+		return p.parseBlockBlock(block)
+	default:
+		return p.parseStepBlock(block)
+	}
+}
+
+func (p *Parser) parseRetryBlock(block *hcl.Block) (*StepRetryConfig, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	if block == nil {
+		return nil, diags
+	}
+
+	if block.Type != "retry" {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid block type",
+			Detail:   "Expected 'retry' block.",
+			Subject:  &block.TypeRange,
+		})
+	}
+
+	if len(block.Labels) != 0 {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid block labels",
+			Detail:   "Expected no labels for 'retry' block.",
+			Subject:  &block.TypeRange,
+		})
+	}
+
+	content, moreDiags := block.Body.Content(retryBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a retry block")
+	diags = diags.Extend(moreDiags)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	config := &StepRetryConfig{}
+
+	for name, attr := range content.Attributes {
+		switch name {
+		case "attempts":
+			config.attempts = attr
+		case "delay":
+			config.delay = attr
+		case "backoff":
+			config.backoff = attr
+		case "until":
+			config.until = attr
+		}
+	}
+
+	return config, diags
+}
+
+func (p *Parser) pushFile(path string) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	p.files = append(p.files, absPath)
+}
+
+func (p *Parser) popFile() {
+	p.files = p.files[:len(p.files)-1]
+}
+
+// resolveSource resolves a procedure source path relative to the directory of the file currently being parsed.
+func (p *Parser) resolveSource(source string) string {
+	if filepath.IsAbs(source) || len(p.files) == 0 {
+		return filepath.Clean(source)
+	}
+
+	return filepath.Join(filepath.Dir(p.files[len(p.files)-1]), source)
+}
+
+func (p *Parser) parseProcedureBlock(block *hcl.Block) (StepBuilder, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	if block == nil {
+		return nil, diags
+	}
+
+	if block.Type != "procedure" {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid block type",
+			Detail:   "Expected 'procedure' block type.",
+			Subject:  &block.TypeRange,
+		}}
+	}
+
+	if len(block.Labels) != 1 {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid block labels",
+			Detail:   "Expected exactly one label for 'procedure' block.",
+			Subject:  &block.TypeRange,
+		}}
+	}
+
+	content, moreDiags := block.Body.Content(procedureBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a procedure block")
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	common, moreDiags := p.parseCommonElements(content)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	common.id = block.Labels[0]
+
+	var escalate *StepEscalateConfig
+	foundEscalate := false
+	for _, block := range content.Blocks {
+		if block.Type != "escalate" {
+			continue
+		}
+
+		if foundEscalate {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate escalate block",
+				Detail:   "Only one escalate block is allowed per procedure.",
+				Subject:  &block.TypeRange,
+			})
+			continue
+		}
+
+		foundEscalate = true
+
+		var moreDiags hcl.Diagnostics
+		escalate, moreDiags = p.parseEscalateBlock(block)
+		diags = diags.Extend(moreDiags)
+	}
+
+	attr := content.Attributes["source"]
+	source, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
+	diags = diags.Extend(moreDiags)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	builder, moreDiags := p.parseProcedureFile(p.resolveSource(source), attr.Expr.Range().Ptr())
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(common.input)) {
+		if _, declared := builder.inputs[name]; declared {
+			continue
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported procedure input",
+			Detail:   fmt.Sprintf("The procedure %q does not declare an input named %q.", source, name),
+			Subject:  common.input[name].NameRange.Ptr(),
+		})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(builder.inputs)) {
+		if _, provided := common.input[name]; provided || !builder.inputs[name].required {
+			continue
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing procedure input",
+			Detail:   fmt.Sprintf("The input %q is required by the procedure %q.", name, source),
+			Subject:  &block.DefRange,
+		})
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	builder.WithCommon(common).WithEscalate(escalate)
+
+	return builder, diags
+}
+
+func (p *Parser) parseProcedureFile(path string, subject *hcl.Range) (*ProcedureBuilder, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	if slices.Contains(p.files, path) {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Circular procedure reference",
+			Detail:   fmt.Sprintf("The procedure file %q includes itself, either directly or through another procedure.", path),
+			Subject:  subject,
+		})
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read procedure file",
+			Detail:   fmt.Sprintf("The procedure file %q could not be read: %s", path, err.Error()),
+			Subject:  subject,
+		})
+	}
+
+	p.pushFile(path)
+	defer p.popFile()
+
+	file, moreDiags := p.parser.ParseHCL(content, path)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	bodyContent, moreDiags := file.Body.Content(procedureFileSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in a procedure file")
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	builder := NewProcedureBuilder().WithSource(path)
+
+	for _, block := range bodyContent.Blocks {
+		switch block.Type {
+		case "input":
+			input, moreDiags := p.parseProcedureInputBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			if _, exists := builder.inputs[input.name]; exists {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate procedure input",
+					Detail:   fmt.Sprintf("The input %q is declared multiple times.", input.name),
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+
+			builder.AddInput(input)
+
+		case "output":
+			output, moreDiags := p.parseProcedureOutputBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			if _, exists := builder.outputs[output.name]; exists {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate procedure output",
+					Detail:   fmt.Sprintf("The output %q is declared multiple times.", output.name),
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+
+			builder.AddOutput(output)
+
+		case "step":
+			step, moreDiags := p.parseStepBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.AddStep(step)
+
+		case "procedure":
+			procedure, moreDiags := p.parseProcedureBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.AddStep(procedure)
+
+		case "block":
+			blockStep, moreDiags := p.parseBlockBlock(block)
+			diags = diags.Extend(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			builder.AddStep(blockStep)
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return builder, diags
+}
+
+func (p *Parser) parseProcedureInputBlock(block *hcl.Block) (*ProcedureInput, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := block.Body.Content(procedureInputBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in an input block")
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	input := &ProcedureInput{
+		name:         block.Labels[0],
+		inputType:    cty.DynamicPseudoType,
+		defaultValue: cty.NullVal(cty.DynamicPseudoType),
+		required:     true,
+	}
+
+	if attr, exists := content.Attributes["type"]; exists {
+		inputType, moreDiags := typeexpr.TypeConstraint(attr.Expr)
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() {
+			input.inputType = inputType
+			input.defaultValue = cty.NullVal(inputType)
+		}
+	}
+
+	if attr, exists := content.Attributes["description"]; exists {
+		description, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
+		diags = diags.Extend(moreDiags)
+		input.description = description
+	}
+
+	if attr, exists := content.Attributes["default"]; exists && !diags.HasErrors() {
+		value, moreDiags := attr.Expr.Value(nil)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil, diags
+		}
+
+		value, err := convert.Convert(value, input.inputType)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid default value",
+				Detail: fmt.Sprintf(
+					"The default value of input %q is not a valid %s: %s",
+					input.name,
+					input.inputType.FriendlyName(),
+					err.Error(),
+				),
+				Subject: attr.Expr.Range().Ptr(),
+			})
+		}
+
+		input.defaultValue = value
+		input.required = false
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return input, diags
+}
+
+func (p *Parser) parseProcedureOutputBlock(block *hcl.Block) (*ProcedureOutput, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	content, moreDiags := block.Body.Content(procedureOutputBlockSchema)
+	hclutil.ModifyUnexpectedElementDiags(moreDiags, "in an output block")
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	output := &ProcedureOutput{
+		name:  block.Labels[0],
+		value: content.Attributes["value"],
+	}
+
+	if attr, exists := content.Attributes["description"]; exists {
+		description, moreDiags := hclutil.ConvertHCLAttributeToString(attr, nil)
+		diags = diags.Extend(moreDiags)
+		output.description = description
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return output, diags
+}
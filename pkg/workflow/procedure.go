@@ -0,0 +1,578 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/hclutil"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/result"
+	"github.com/trippsoft/forge/pkg/set"
+	"github.com/trippsoft/forge/pkg/ui"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ProcedureInput represents an input declared by a procedure file.
+//
+// This represents an input block within a procedure file.
+type ProcedureInput struct {
+	name         string
+	inputType    cty.Type
+	defaultValue cty.Value
+	required     bool
+	description  string
+}
+
+// Name returns the name of the input.
+//
+// This is used primarily for testing purposes.
+func (i *ProcedureInput) Name() string {
+	return i.name
+}
+
+// Type returns the type constraint of the input.
+//
+// This is used primarily for testing purposes.
+func (i *ProcedureInput) Type() cty.Type {
+	return i.inputType
+}
+
+// Default returns the default value of the input.
+//
+// This is used primarily for testing purposes.
+func (i *ProcedureInput) Default() cty.Value {
+	return i.defaultValue
+}
+
+// Required indicates whether the input must be provided by the procedure block.
+//
+// This is used primarily for testing purposes.
+func (i *ProcedureInput) Required() bool {
+	return i.required
+}
+
+// Description returns the description of the input.
+//
+// This is used primarily for testing purposes.
+func (i *ProcedureInput) Description() string {
+	return i.description
+}
+
+// ProcedureOutput represents an output declared by a procedure file.
+//
+// This represents an output block within a procedure file.
+type ProcedureOutput struct {
+	name        string
+	value       *hcl.Attribute
+	description string
+}
+
+// Name returns the name of the output.
+//
+// This is used primarily for testing purposes.
+func (o *ProcedureOutput) Name() string {
+	return o.name
+}
+
+// Value returns the HCL attribute representing the value of the output.
+//
+// This is used primarily for testing purposes.
+func (o *ProcedureOutput) Value() *hcl.Attribute {
+	return o.value
+}
+
+// Description returns the description of the output.
+//
+// This is used primarily for testing purposes.
+func (o *ProcedureOutput) Description() string {
+	return o.description
+}
+
+// Procedure represents a reusable group of steps loaded from a procedure file.
+//
+// The steps of a procedure run in their own step context, so they can only reference each other through steps.*.
+// The procedure's outputs are stored in the calling step context under the procedure's ID.
+type Procedure struct {
+	common   *StepCommonConfig
+	escalate *StepEscalateConfig
+	source   string
+
+	inputs  map[string]*ProcedureInput
+	outputs map[string]*ProcedureOutput
+
+	steps []Step
+}
+
+// ID implements Step.
+func (p *Procedure) ID() string {
+	return p.common.id
+}
+
+// Common returns the common configuration of the procedure.
+//
+// This is used primarily for testing purposes.
+func (p *Procedure) Common() *StepCommonConfig {
+	return p.common
+}
+
+// Escalate returns the escalation configuration of the procedure.
+//
+// This is used primarily for testing purposes.
+func (p *Procedure) Escalate() *StepEscalateConfig {
+	return p.escalate
+}
+
+// Source returns the path of the procedure file.
+//
+// This is used primarily for testing purposes.
+func (p *Procedure) Source() string {
+	return p.source
+}
+
+// Inputs returns a clone of the inputs declared by the procedure file.
+//
+// This is used primarily for testing purposes.
+func (p *Procedure) Inputs() map[string]*ProcedureInput {
+	return maps.Clone(p.inputs)
+}
+
+// Outputs returns a clone of the outputs declared by the procedure file.
+//
+// This is used primarily for testing purposes.
+func (p *Procedure) Outputs() map[string]*ProcedureOutput {
+	return maps.Clone(p.outputs)
+}
+
+// Steps returns a clone of the slice of all steps in the procedure.
+//
+// This is used primarily for testing purposes.
+func (p *Procedure) Steps() []Step {
+	return slices.Clone(p.steps)
+}
+
+// Run implements Step.
+func (p *Procedure) Run(wc *WorkflowContext) (map[string]cty.Value, error) {
+	wc.ui.PrintHeader(ui.HeaderLevel2, "PROCEDURE - ", p.common.name)
+
+	wc.LoadHostVars()
+
+	var err error
+	mutex := sync.Mutex{}
+	errChannel := make(chan error)
+	outputs := make(map[string]cty.Value)
+	started := set.NewSet[*inventory.Host]()
+
+	for _, host := range p.common.targets {
+		go func(h *inventory.Host) {
+			if !wc.IsActive(h) {
+				errChannel <- nil
+				return
+			}
+
+			output, ok, hostErr := p.startOnHost(NewHostWorkflowContext(wc, h))
+
+			mutex.Lock()
+			if ok {
+				started.Add(h)
+			} else {
+				outputs[h.Name()] = output
+			}
+			mutex.Unlock()
+
+			errChannel <- hostErr
+		}(host)
+	}
+
+	for range p.common.targets {
+		hostErr := <-errChannel
+		err = errors.Join(err, hostErr)
+	}
+
+	if started.IsEmpty() {
+		return outputs, err
+	}
+
+	procedureContext := wc.withHostFilter(started)
+	for _, step := range p.steps {
+		stopErr := wc.stopped()
+		if stopErr != nil {
+			err = errors.Join(err, stopErr)
+			break
+		}
+
+		_, stepErr := step.Run(procedureContext)
+		err = errors.Join(err, stepErr)
+	}
+
+	wc.ui.PrintHeader(ui.HeaderLevel2, "PROCEDURE COMPLETE - ", p.common.name)
+
+	wc.LoadHostVars()
+
+	startedHosts := started.Items()
+	for _, host := range startedHosts {
+		go func(h *inventory.Host) {
+			output, hostErr := p.endOnHost(NewHostWorkflowContext(wc, h))
+
+			mutex.Lock()
+			outputs[h.Name()] = output
+			mutex.Unlock()
+
+			errChannel <- hostErr
+		}(host)
+	}
+
+	for range startedHosts {
+		hostErr := <-errChannel
+		err = errors.Join(err, hostErr)
+	}
+
+	return outputs, err
+}
+
+// startOnHost evaluates the condition and inputs of the procedure for the host and starts a new step context.
+//
+// If the procedure does not start on the host, the returned value is the host's output for the procedure.
+func (p *Procedure) startOnHost(hwc *HostWorkflowContext) (cty.Value, bool, error) {
+	err := hwc.LoadEvalContext()
+	if err != nil {
+		hwc.MarkFailed(hwc.host)
+		r := result.NewFailure(err, "failed to load evaluation context")
+		return p.handleHostResult(hwc, r), false, err
+	}
+
+	condition := true
+	if p.common.condition != nil {
+		var diags hcl.Diagnostics
+		condition, diags = hclutil.ConvertHCLAttributeToBool(p.common.condition, hwc.evalContext)
+		if diags.HasErrors() {
+			hwc.MarkFailed(hwc.host)
+			r := result.NewFailure(diags, diags.Error())
+			return p.handleHostResult(hwc, r), false, diags
+		}
+	}
+
+	if !condition {
+		return p.handleHostResult(hwc, result.NewSkipped()), false, nil
+	}
+
+	inputs, diags := p.evaluateInputs(hwc)
+	if diags.HasErrors() {
+		hwc.MarkFailed(hwc.host)
+		r := result.NewFailure(diags, diags.Error())
+		return p.handleHostResult(hwc, r), false, diags
+	}
+
+	hwc.host.StartProcedure(inputs)
+
+	return cty.NilVal, true, nil
+}
+
+func (p *Procedure) evaluateInputs(hwc *HostWorkflowContext) (map[string]cty.Value, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+	inputs := make(map[string]cty.Value, len(p.inputs))
+
+	for name, input := range p.inputs {
+		attr, exists := p.common.input[name]
+		if !exists {
+			if input.required {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Missing procedure input",
+					Detail:   fmt.Sprintf("The input %q is required by the procedure %q.", name, p.source),
+				})
+				continue
+			}
+
+			inputs[name] = input.defaultValue
+			continue
+		}
+
+		value, moreDiags := attr.Expr.Value(hwc.evalContext)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		value, err := convert.Convert(value, input.inputType)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid procedure input",
+				Detail:   fmt.Sprintf("The input %q is not a valid %s: %s", name, input.inputType.FriendlyName(), err),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+
+		inputs[name] = value
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return inputs, diags
+}
+
+// endOnHost evaluates the outputs of the procedure for the host and ends its step context.
+//
+// The result of the procedure is stored in the calling step context.
+func (p *Procedure) endOnHost(hwc *HostWorkflowContext) (cty.Value, error) {
+	failed := hwc.IsFailed(hwc.host)
+
+	changed := false
+	steps, _ := hwc.host.GetCurrentContextSteps()
+	for _, output := range steps {
+		if outputChanged(output) {
+			changed = true
+			break
+		}
+	}
+
+	outputs := make(map[string]cty.Value, len(p.outputs))
+	err := hwc.LoadEvalContext()
+	if err == nil && !failed {
+		for name, output := range p.outputs {
+			value, diags := output.value.Expr.Value(hwc.evalContext)
+			if diags.HasErrors() {
+				err = errors.Join(err, diags)
+				continue
+			}
+
+			outputs[name] = value
+		}
+	}
+
+	err = errors.Join(err, hwc.host.EndProcedure())
+
+	var r *result.Result
+	switch {
+	case err != nil:
+		hwc.MarkFailed(hwc.host)
+		r = result.NewFailure(err, err.Error())
+	case failed:
+		// The failing step has already reported its error, so it is not returned again.
+		r = result.NewFailure(errors.New("one or more steps in the procedure failed"), "")
+	case changed:
+		r = result.NewChanged(cty.ObjectVal(outputs))
+	default:
+		r = result.NewNotChanged(cty.ObjectVal(outputs))
+	}
+
+	r.Changed = changed
+
+	return p.handleHostResult(hwc, r), err
+}
+
+func (p *Procedure) handleHostResult(hwc *HostWorkflowContext, r *result.Result) cty.Value {
+	hwc.ui.PrintHostResult(hwc.host.Name(), r)
+	output := formatResultOutput(r)
+	hwc.host.StoreStepOutput(p.common.id, output)
+
+	return output
+}
+
+// outputChanged checks if a stored step output reports a change.
+//
+// Outputs of looped steps and nested procedures are checked recursively.
+func outputChanged(value cty.Value) bool {
+	if value.IsNull() || !value.IsWhollyKnown() {
+		return false
+	}
+
+	valueType := value.Type()
+	switch {
+	case valueType.IsObjectType() && valueType.HasAttribute("changed") && valueType.HasAttribute("failed"):
+		changed := value.GetAttr("changed")
+		return changed.Type().Equals(cty.Bool) && !changed.IsNull() && changed.True()
+
+	case valueType.IsObjectType() || valueType.IsTupleType():
+		it := value.ElementIterator()
+		for it.Next() {
+			_, elem := it.Element()
+			if outputChanged(elem) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ProcedureBuilder is used to build a Procedure instance during parsing.
+type ProcedureBuilder struct {
+	common   *StepCommonConfig
+	escalate *StepEscalateConfig
+	source   string
+
+	inputs  map[string]*ProcedureInput
+	outputs map[string]*ProcedureOutput
+
+	steps []StepBuilder
+}
+
+// WithCommon sets the common configuration for the procedure.
+func (p *ProcedureBuilder) WithCommon(common *StepCommonConfig) *ProcedureBuilder {
+	p.common = common
+	return p
+}
+
+// WithEscalate sets the escalation configuration for the procedure.
+func (p *ProcedureBuilder) WithEscalate(escalate *StepEscalateConfig) *ProcedureBuilder {
+	p.escalate = escalate
+	return p
+}
+
+// WithSource sets the path of the procedure file.
+func (p *ProcedureBuilder) WithSource(source string) *ProcedureBuilder {
+	p.source = source
+	return p
+}
+
+// AddInput adds an input declared by the procedure file.
+func (p *ProcedureBuilder) AddInput(input *ProcedureInput) *ProcedureBuilder {
+	p.inputs[input.name] = input
+	return p
+}
+
+// AddOutput adds an output declared by the procedure file.
+func (p *ProcedureBuilder) AddOutput(output *ProcedureOutput) *ProcedureBuilder {
+	p.outputs[output.name] = output
+	return p
+}
+
+// AddStep adds a StepBuilder to the ProcedureBuilder.
+//
+// The common and escalation configuration of the procedure are passed to the step when the procedure is added to its
+// parent.
+func (p *ProcedureBuilder) AddStep(sb StepBuilder) *ProcedureBuilder {
+	p.steps = append(p.steps, sb)
+	return p
+}
+
+// WithProcessCommon implements StepBuilder.
+func (p *ProcedureBuilder) WithProcessCommon(common *StepCommonConfig) StepBuilder {
+	p.common.Combine(common)
+	for _, sb := range p.steps {
+		sb.WithProcessCommon(p.common)
+	}
+
+	return p
+}
+
+// WithProcessEscalate implements StepBuilder.
+func (p *ProcedureBuilder) WithProcessEscalate(escalate *StepEscalateConfig) StepBuilder {
+	if p.escalate == nil {
+		p.escalate = escalate
+	} else {
+		p.escalate.Combine(escalate)
+	}
+
+	for _, sb := range p.steps {
+		sb.WithProcessEscalate(p.escalate)
+	}
+
+	return p
+}
+
+// AllTargets implements StepBuilder.
+//
+// Steps within the procedure only run on the procedure's targets, so those are the only targets returned.
+func (p *ProcedureBuilder) AllTargets() []*inventory.Host {
+	targets := slices.Clone(p.common.targets)
+	return targets
+}
+
+// notifications implements handlerNotifier.
+//
+// Steps within the procedure notify the handlers of the process that contains the procedure.
+func (p *ProcedureBuilder) notifications() []*handlerNotification {
+	notifications := []*handlerNotification{}
+	for _, sb := range p.steps {
+		if notifier, ok := sb.(handlerNotifier); ok {
+			notifications = append(notifications, notifier.notifications()...)
+		}
+	}
+
+	return notifications
+}
+
+// filterTags implements taggedStepBuilder.
+//
+// The procedure is selected if any of its steps are selected.
+func (p *ProcedureBuilder) filterTags(filter *TagFilter) bool {
+	p.steps = filterTaggedSteps(p.steps, filter)
+	return len(p.steps) > 0
+}
+
+// allTags implements taggedStepBuilder.
+func (p *ProcedureBuilder) allTags() []string {
+	tags := set.NewSet(p.common.tags...)
+	collectTags(tags, p.steps)
+	return tags.Items()
+}
+
+// Build implements StepBuilder.
+func (p *ProcedureBuilder) Build() (Step, error) {
+	if p.common == nil {
+		return nil, errors.New("Procedure failed to build: common configuration is missing")
+	}
+
+	if p.common.id == "" {
+		return nil, errors.New("Procedure failed to build: id is missing")
+	}
+
+	if p.common.name == "" {
+		return nil, errors.New("Procedure failed to build: name is missing")
+	}
+
+	if p.common.targets == nil {
+		return nil, errors.New("Procedure failed to build: targets are missing")
+	}
+
+	if p.source == "" {
+		return nil, errors.New("Procedure failed to build: source is missing")
+	}
+
+	steps := make([]Step, 0, len(p.steps))
+	var err error
+	for _, sb := range p.steps {
+		step, stepErr := sb.Build()
+		if stepErr != nil || err != nil {
+			err = errors.Join(err, stepErr)
+			continue
+		}
+
+		steps = append(steps, step)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Procedure{
+		common:   p.common,
+		escalate: p.escalate,
+		source:   p.source,
+		inputs:   p.inputs,
+		outputs:  p.outputs,
+		steps:    steps,
+	}, nil
+}
+
+// NewProcedureBuilder creates a new instance of ProcedureBuilder.
+func NewProcedureBuilder() *ProcedureBuilder {
+	return &ProcedureBuilder{
+		inputs:  map[string]*ProcedureInput{},
+		outputs: map[string]*ProcedureOutput{},
+		steps:   []StepBuilder{},
+	}
+}
@@ -5,9 +5,15 @@ package workflow
 
 import (
 	"errors"
+	"fmt"
+	"maps"
 	"slices"
+	"strings"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/hclutil"
 	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/result"
 	"github.com/trippsoft/forge/pkg/set"
 	"github.com/trippsoft/forge/pkg/ui"
 	"github.com/zclconf/go-cty/cty"
@@ -18,9 +24,16 @@ import (
 // This represents a parsed process block from a workflow file.
 type Process struct {
 	name         string
+	condition    *hcl.Attribute
 	discoverInfo bool
 	allTargets   []*inventory.Host
 	steps        []Step
+	handlers     *FlushHandlers
+	locals       map[string]*hcl.Attribute
+	tags         []string
+
+	serial            []*BatchSize
+	maxFailPercentage *float64
 }
 
 // Name returns the name of the process.
@@ -28,6 +41,13 @@ func (p *Process) Name() string {
 	return p.name
 }
 
+// Condition returns the condition attribute of the process.
+//
+// This is used primarily for testing purposes.
+func (p *Process) Condition() *hcl.Attribute {
+	return p.condition
+}
+
 // DiscoverInfo indicates whether the process is set to discover information.
 func (p *Process) DiscoverInfo() bool {
 	return p.discoverInfo
@@ -42,6 +62,21 @@ func (p *Process) AllTargets() []*inventory.Host {
 	return allTargets
 }
 
+// Serial returns a clone of the batch sizes used to run the process in rolling batches.
+//
+// This is used primarily for testing purposes.
+func (p *Process) Serial() []*BatchSize {
+	serial := slices.Clone(p.serial)
+	return serial
+}
+
+// MaxFailPercentage returns the percentage of failed hosts in a batch that stops the process, if any.
+//
+// This is used primarily for testing purposes.
+func (p *Process) MaxFailPercentage() *float64 {
+	return p.maxFailPercentage
+}
+
 // Steps returns a clone of the slice of all steps in the process.
 //
 // This is done to prevent external modification of the internal state.
@@ -51,25 +86,256 @@ func (p *Process) Steps() []Step {
 	return steps
 }
 
+// Handlers returns a clone of the slice of all handlers in the process.
+//
+// This is used primarily for testing purposes.
+func (p *Process) Handlers() []Step {
+	return p.handlers.Handlers()
+}
+
+// Locals returns a clone of the locals defined by the process.
+//
+// This is used primarily for testing purposes.
+func (p *Process) Locals() map[string]*hcl.Attribute {
+	return maps.Clone(p.locals)
+}
+
+// Tags returns the sorted tags of the steps selected to run in the process.
+//
+// This is used to list the tags of a workflow.
+func (p *Process) Tags() []string {
+	return slices.Clone(p.tags)
+}
+
 // Run executes the process using the provided WorkflowContext.
 func (p *Process) Run(wc *WorkflowContext) (map[string]map[string]cty.Value, error) {
 	wc.ui.PrintHeader(ui.HeaderLevel1, "PROCESS - ", p.name)
+	wc.clearNotifications()
+
+	wc = wc.withLocals(p.locals).withDiscoverInfo(p.discoverInfo)
 
-	err := p.discoverInfoForTargets(wc)
+	// Unless the condition refers to host info, it is evaluated first so info is only discovered for hosts that meet it.
+	targets := p.allTargets
+	var conditionMet *set.Set[*inventory.Host]
+	var err error
+	if p.condition != nil && !refersToVariable(p.condition, wc.locals, "info") {
+		conditionMet, err = p.evaluateCondition(wc)
+		targets = slices.DeleteFunc(slices.Clone(targets), func(host *inventory.Host) bool {
+			return !conditionMet.Contains(host)
+		})
+	}
+
+	err = errors.Join(err, p.discoverInfoForTargets(wc, targets))
+
+	if p.condition != nil && conditionMet == nil {
+		var conditionErr error
+		conditionMet, conditionErr = p.evaluateCondition(wc)
+		err = errors.Join(err, conditionErr)
+	}
 
 	outputs := make(map[string]map[string]cty.Value)
+	if conditionMet != nil {
+		if conditionMet.IsEmpty() {
+			return outputs, err
+		}
 
-	for _, step := range p.steps {
-		output, stepErr := step.Run(wc)
-		outputs[step.ID()] = output
-		err = errors.Join(err, stepErr)
+		wc = wc.withHostFilter(conditionMet)
+	}
+
+	if len(p.serial) == 0 {
+		batchErr := p.runBatch(wc, p.activeTargets(wc), outputs)
+		return outputs, errors.Join(err, batchErr)
+	}
+
+	batches := p.batches(p.activeTargets(wc))
+	for i, batch := range batches {
+		batchName := fmt.Sprintf("%d of %d (%d hosts)", i+1, len(batches), len(batch))
+		wc.ui.PrintHeader(ui.HeaderLevel1, "BATCH - ", batchName)
+
+		batchErr := p.runBatch(wc.withHostFilter(set.NewSet(batch...)), batch, outputs)
+		err = errors.Join(err, batchErr)
+		if errors.Is(batchErr, errMaxFailPercentageExceeded) || errors.Is(batchErr, ErrStopped) {
+			break
+		}
 	}
 
 	return outputs, err
 }
 
-func (p *Process) discoverInfoForTargets(wc *WorkflowContext) error {
-	if !p.discoverInfo {
+var errMaxFailPercentageExceeded = errors.New("max_fail_percentage exceeded")
+
+// runBatch runs every step of the process on a batch of hosts and merges the outputs into the provided map.
+//
+// Notified handlers run at each flush_handlers step and once more after the last step of the batch.
+// If max_fail_percentage is set, the batch stops after the first step that leaves too many of its hosts failed.
+// If the workflow is stopped, the batch stops before the next step or handler flush.
+func (p *Process) runBatch(
+	wc *WorkflowContext,
+	batch []*inventory.Host,
+	outputs map[string]map[string]cty.Value,
+) error {
+
+	var err error
+	for _, step := range p.steps {
+		stopErr := wc.stopped()
+		if stopErr != nil {
+			return errors.Join(err, stopErr)
+		}
+
+		err = errors.Join(err, runStep(wc, step, outputs))
+		if p.maxFailPercentageExceeded(wc, batch) {
+			return errors.Join(err, errMaxFailPercentageExceeded)
+		}
+	}
+
+	stopErr := wc.stopped()
+	if stopErr != nil {
+		return errors.Join(err, stopErr)
+	}
+
+	handlerOutputs, handlerErr := p.handlers.runSteps(wc)
+	mergeOutputs(outputs, handlerOutputs)
+
+	err = errors.Join(err, handlerErr)
+	if p.maxFailPercentageExceeded(wc, batch) {
+		return errors.Join(err, errMaxFailPercentageExceeded)
+	}
+
+	return err
+}
+
+// maxFailPercentageExceeded checks if the percentage of failed hosts in the batch exceeds max_fail_percentage.
+func (p *Process) maxFailPercentageExceeded(wc *WorkflowContext, batch []*inventory.Host) bool {
+	if p.maxFailPercentage == nil || len(batch) == 0 {
+		return false
+	}
+
+	failed := 0
+	for _, host := range batch {
+		if wc.IsFailed(host) {
+			failed++
+		}
+	}
+
+	failedPercentage := float64(failed) * 100 / float64(len(batch))
+	if failedPercentage <= *p.maxFailPercentage {
+		return false
+	}
+
+	wc.ui.PrintError(fmt.Sprintf(
+		"%d of %d hosts in the batch failed (%.1f%%), exceeding the max_fail_percentage of %g%%. "+
+			"Stopping process.\n",
+		failed,
+		len(batch),
+		failedPercentage,
+		*p.maxFailPercentage,
+	))
+
+	return true
+}
+
+// runStep runs a step and merges its outputs, and the outputs of any steps it runs, into the provided map.
+func runStep(wc *WorkflowContext, step Step, outputs map[string]map[string]cty.Value) error {
+	if group, ok := step.(stepGroup); ok {
+		groupOutputs, err := group.runSteps(wc)
+		mergeOutputs(outputs, groupOutputs)
+		return err
+	}
+
+	output, err := step.Run(wc)
+	mergeOutputs(outputs, map[string]map[string]cty.Value{step.ID(): output})
+	return err
+}
+
+// mergeOutputs merges step outputs by step ID and host name into the destination map.
+func mergeOutputs(dst, src map[string]map[string]cty.Value) {
+	for id, output := range src {
+		if dst[id] == nil {
+			dst[id] = make(map[string]cty.Value, len(output))
+		}
+
+		for host, value := range output {
+			dst[id][host] = value
+		}
+	}
+}
+
+// activeTargets returns the targets of the process that have not failed, sorted by name.
+func (p *Process) activeTargets(wc *WorkflowContext) []*inventory.Host {
+	targets := make([]*inventory.Host, 0, len(p.allTargets))
+	for _, target := range p.allTargets {
+		if wc.IsActive(target) {
+			targets = append(targets, target)
+		}
+	}
+
+	slices.SortFunc(targets, func(a, b *inventory.Host) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return targets
+}
+
+// batches splits the targets into batches according to the serial batch sizes.
+//
+// The last batch size is repeated until every target is in a batch.
+func (p *Process) batches(targets []*inventory.Host) [][]*inventory.Host {
+	batches := [][]*inventory.Host{}
+	for i := 0; len(targets) > 0; i++ {
+		size := p.serial[min(i, len(p.serial)-1)].resolve(len(p.allTargets))
+		size = min(size, len(targets))
+
+		batches = append(batches, targets[:size])
+		targets = targets[size:]
+	}
+
+	return batches
+}
+
+// evaluateCondition evaluates the condition of the process on each active target and returns the targets that meet it.
+//
+// Targets that do not meet the condition are reported as skipped for the whole process. Targets whose condition cannot
+// be evaluated are marked as failed.
+func (p *Process) evaluateCondition(wc *WorkflowContext) (*set.Set[*inventory.Host], error) {
+	wc.ui.PrintHeader(ui.HeaderLevel2, "Evaluating Process Condition", "")
+
+	wc.LoadHostVars()
+
+	conditionMet := set.NewSet[*inventory.Host]()
+	var err error
+	for _, host := range p.activeTargets(wc) {
+		hwc := NewHostWorkflowContext(wc, host)
+		e := hwc.LoadEvalContext()
+		if e != nil {
+			wc.ui.PrintHostResult(host.Name(), result.NewFailure(e, "failed to load evaluation context"))
+			wc.MarkFailed(host)
+			err = errors.Join(err, e)
+			continue
+		}
+
+		condition, diags := hclutil.ConvertHCLAttributeToBool(p.condition, hwc.evalContext)
+		if diags.HasErrors() {
+			wc.ui.PrintHostResult(host.Name(), result.NewFailure(diags, diags.Error()))
+			wc.MarkFailed(host)
+			err = errors.Join(err, diags)
+			continue
+		}
+
+		if !condition {
+			wc.ui.PrintHostResult(host.Name(), result.NewSkipped())
+			continue
+		}
+
+		conditionMet.Add(host)
+		wc.ui.PrintHostResult(host.Name(), result.NewNotChanged(cty.EmptyObjectVal))
+	}
+
+	return conditionMet, err
+}
+
+// discoverInfoForTargets discovers the info of the targets, if the process is set to discover information.
+func (p *Process) discoverInfoForTargets(wc *WorkflowContext, targets []*inventory.Host) error {
+	if !p.discoverInfo || len(targets) == 0 {
 		return nil
 	}
 
@@ -77,9 +343,11 @@ func (p *Process) discoverInfoForTargets(wc *WorkflowContext) error {
 
 	errChannel := make(chan error)
 	var err error
-	for _, target := range p.allTargets {
+	for _, target := range targets {
 		go func(host *inventory.Host) {
-			r := host.Info().Populate(host.Transport())
+			wc.acquireFork()
+			r := wc.populateInfo(host)
+			wc.releaseFork()
 
 			var e error
 			if r.Error != nil {
@@ -91,7 +359,7 @@ func (p *Process) discoverInfoForTargets(wc *WorkflowContext) error {
 		}(target)
 	}
 
-	for range p.allTargets {
+	for range targets {
 		e := <-errChannel
 		err = errors.Join(err, e)
 	}
@@ -99,13 +367,62 @@ func (p *Process) discoverInfoForTargets(wc *WorkflowContext) error {
 	return err
 }
 
+// BatchSize represents a single batch size of a process's serial attribute.
+type BatchSize struct {
+	value      int
+	percentage bool
+}
+
+// NewBatchSize creates a new BatchSize with a fixed number of hosts.
+func NewBatchSize(count int) *BatchSize {
+	return &BatchSize{value: count}
+}
+
+// NewBatchSizePercentage creates a new BatchSize with a percentage of the process's targets.
+func NewBatchSizePercentage(percentage int) *BatchSize {
+	return &BatchSize{value: percentage, percentage: true}
+}
+
+// Value returns the number of hosts or the percentage of targets in the batch.
+//
+// This is used primarily for testing purposes.
+func (b *BatchSize) Value() int {
+	return b.value
+}
+
+// Percentage indicates whether the value is a percentage of the process's targets.
+//
+// This is used primarily for testing purposes.
+func (b *BatchSize) Percentage() bool {
+	return b.percentage
+}
+
+// resolve returns the number of hosts in the batch for the given number of targets.
+//
+// Percentages are rounded down, but a batch always contains at least one host.
+func (b *BatchSize) resolve(total int) int {
+	size := b.value
+	if b.percentage {
+		size = total * b.value / 100
+	}
+
+	return max(size, 1)
+}
+
 // ProcessBuilder is used to build a Process instance during parsing.
 type ProcessBuilder struct {
 	common       *StepCommonConfig
 	escalate     *StepEscalateConfig
 	discoverInfo bool
+	locals       map[string]*hcl.Attribute
+	tagFilter    *TagFilter
 
-	steps []StepBuilder
+	serial            []*BatchSize
+	maxFailPercentage *float64
+
+	steps       []StepBuilder
+	handlers    []*SingleStepBuilder
+	flushPoints []int
 }
 
 // WithCommon sets the common configuration for the process.
@@ -126,6 +443,32 @@ func (pb *ProcessBuilder) WithDiscoverInfo(discoverInfo bool) *ProcessBuilder {
 	return pb
 }
 
+// WithSerial sets the batch sizes used to run the process in rolling batches.
+func (pb *ProcessBuilder) WithSerial(serial ...*BatchSize) *ProcessBuilder {
+	pb.serial = serial
+	return pb
+}
+
+// WithMaxFailPercentage sets the percentage of failed hosts in a batch that stops the process.
+func (pb *ProcessBuilder) WithMaxFailPercentage(maxFailPercentage float64) *ProcessBuilder {
+	pb.maxFailPercentage = &maxFailPercentage
+	return pb
+}
+
+// WithLocals sets the locals defined by the process.
+func (pb *ProcessBuilder) WithLocals(locals map[string]*hcl.Attribute) *ProcessBuilder {
+	pb.locals = locals
+	return pb
+}
+
+// WithTagFilter sets the filter that selects the steps of the process by their tags.
+//
+// Without a filter, every step runs except the steps tagged with TagNever.
+func (pb *ProcessBuilder) WithTagFilter(filter *TagFilter) *ProcessBuilder {
+	pb.tagFilter = filter
+	return pb
+}
+
 // AddStep adds a StepBuilder to the ProcessBuilder.
 func (pb *ProcessBuilder) AddStep(sb StepBuilder) *ProcessBuilder {
 	pb.steps = append(pb.steps, sb)
@@ -133,12 +476,56 @@ func (pb *ProcessBuilder) AddStep(sb StepBuilder) *ProcessBuilder {
 	return pb
 }
 
+// AddHandler adds a handler to the ProcessBuilder.
+func (pb *ProcessBuilder) AddHandler(sb *SingleStepBuilder) *ProcessBuilder {
+	pb.handlers = append(pb.handlers, sb.AsHandler())
+	sb.WithProcessCommon(pb.common).WithProcessEscalate(pb.escalate)
+	return pb
+}
+
+// AddFlushHandlers adds a point after the steps added so far at which notified handlers run.
+func (pb *ProcessBuilder) AddFlushHandlers() *ProcessBuilder {
+	pb.flushPoints = append(pb.flushPoints, len(pb.steps))
+	return pb
+}
+
 // Build constructs and returns the Process instance.
 func (pb *ProcessBuilder) Build() (*Process, error) {
-	steps := make([]Step, 0, len(pb.steps))
+	handlers := &FlushHandlers{handlers: make([]Step, 0, len(pb.handlers))}
 	allTargetsSet := set.NewSet[*inventory.Host]()
 	var err error
-	for _, sb := range pb.steps {
+	for _, sb := range pb.handlers {
+		handler, handlerErr := sb.Build()
+		if handlerErr != nil || err != nil {
+			err = errors.Join(err, handlerErr)
+			continue
+		}
+
+		handlers.handlers = append(handlers.handlers, handler)
+		for _, target := range sb.AllTargets() {
+			allTargetsSet.Add(target)
+		}
+	}
+
+	steps := make([]Step, 0, len(pb.steps)+len(pb.flushPoints))
+	tags := set.NewSet[string]()
+	flushPoints := slices.Clone(pb.flushPoints)
+	for i, sb := range pb.steps {
+		for len(flushPoints) > 0 && flushPoints[0] == i {
+			steps = append(steps, handlers)
+			flushPoints = flushPoints[1:]
+		}
+
+		if tagged, ok := sb.(taggedStepBuilder); ok {
+			if !tagged.filterTags(pb.tagFilter) {
+				continue
+			}
+
+			for _, tag := range tagged.allTags() {
+				tags.Add(tag)
+			}
+		}
+
 		step, stepErr := sb.Build()
 		if stepErr != nil || err != nil {
 			err = errors.Join(err, stepErr)
@@ -151,15 +538,28 @@ func (pb *ProcessBuilder) Build() (*Process, error) {
 		}
 	}
 
+	for range flushPoints {
+		steps = append(steps, handlers)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
+	sortedTags := tags.Items()
+	slices.Sort(sortedTags)
+
 	return &Process{
-		name:         pb.common.name,
-		discoverInfo: pb.discoverInfo,
-		allTargets:   allTargetsSet.Items(),
-		steps:        steps,
+		name:              pb.common.name,
+		condition:         pb.common.condition,
+		discoverInfo:      pb.discoverInfo,
+		allTargets:        allTargetsSet.Items(),
+		steps:             steps,
+		handlers:          handlers,
+		locals:            pb.locals,
+		tags:              sortedTags,
+		serial:            pb.serial,
+		maxFailPercentage: pb.maxFailPercentage,
 	}, nil
 }
 
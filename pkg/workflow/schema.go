@@ -8,10 +8,60 @@ import "github.com/hashicorp/hcl/v2"
 var (
 	workflowBodySchema = &hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "variable",
+				LabelNames: []string{"name"},
+			},
+			{
+				Type:       "locals",
+				LabelNames: []string{},
+			},
 			{
 				Type:       "process",
 				LabelNames: []string{},
 			},
+			{
+				Type:       "output",
+				LabelNames: []string{"name"},
+			},
+		},
+	}
+	variableBlockSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "validation",
+				LabelNames: []string{},
+			},
+		},
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "type",
+				Required: false,
+			},
+			{
+				Name:     "default",
+				Required: false,
+			},
+			{
+				Name:     "description",
+				Required: false,
+			},
+			{
+				Name:     "sensitive",
+				Required: false,
+			},
+		},
+	}
+	variableValidationBlockSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "condition",
+				Required: true,
+			},
+			{
+				Name:     "error_message",
+				Required: true,
+			},
 		},
 	}
 	processBlockSchema = &hcl.BodySchema{
@@ -24,6 +74,22 @@ var (
 				Type:       "procedure",
 				LabelNames: []string{"id"},
 			},
+			{
+				Type:       "block",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "handler",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "flush_handlers",
+				LabelNames: []string{},
+			},
+			{
+				Type:       "locals",
+				LabelNames: []string{},
+			},
 			{
 				Type:       "escalate",
 				LabelNames: []string{},
@@ -46,10 +112,26 @@ var (
 				Name:     "what_if",
 				Required: false,
 			},
+			{
+				Name:     "tags",
+				Required: false,
+			},
+			{
+				Name:     "condition",
+				Required: false,
+			},
 			{
 				Name:     "discover_info",
 				Required: false,
 			},
+			{
+				Name:     "serial",
+				Required: false,
+			},
+			{
+				Name:     "max_fail_percentage",
+				Required: false,
+			},
 		},
 	}
 	stepBlockSchema = &hcl.BodySchema{
@@ -70,6 +152,10 @@ var (
 				Type:       "output",
 				LabelNames: []string{},
 			},
+			{
+				Type:       "retry",
+				LabelNames: []string{},
+			},
 		},
 		Attributes: []hcl.AttributeSchema{
 			{
@@ -88,6 +174,30 @@ var (
 				Name:     "run_once",
 				Required: false,
 			},
+			{
+				Name:     "run_once_host",
+				Required: false,
+			},
+			{
+				Name:     "async",
+				Required: false,
+			},
+			{
+				Name:     "poll",
+				Required: false,
+			},
+			{
+				Name:     "delegate_to",
+				Required: false,
+			},
+			{
+				Name:     "throttle",
+				Required: false,
+			},
+			{
+				Name:     "notify",
+				Required: false,
+			},
 			{
 				Name:     "targets",
 				Required: false,
@@ -100,6 +210,190 @@ var (
 				Name:     "what_if",
 				Required: false,
 			},
+			{
+				Name:     "tags",
+				Required: false,
+			},
+		},
+	}
+	blockBlockSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "step",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "procedure",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "block",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "rescue",
+				LabelNames: []string{},
+			},
+			{
+				Type:       "always",
+				LabelNames: []string{},
+			},
+			{
+				Type:       "escalate",
+				LabelNames: []string{},
+			},
+		},
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "name",
+				Required: true,
+			},
+			{
+				Name:     "condition",
+				Required: false,
+			},
+			{
+				Name:     "targets",
+				Required: false,
+			},
+			{
+				Name:     "exec_timeout",
+				Required: false,
+			},
+			{
+				Name:     "what_if",
+				Required: false,
+			},
+			{
+				Name:     "tags",
+				Required: false,
+			},
+		},
+	}
+	blockSectionSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "step",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "procedure",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "block",
+				LabelNames: []string{"id"},
+			},
+		},
+	}
+	procedureBlockSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "escalate",
+				LabelNames: []string{},
+			},
+			{
+				Type:       "input",
+				LabelNames: []string{},
+			},
+		},
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "name",
+				Required: true,
+			},
+			{
+				Name:     "source",
+				Required: true,
+			},
+			{
+				Name:     "condition",
+				Required: false,
+			},
+			{
+				Name:     "targets",
+				Required: false,
+			},
+			{
+				Name:     "exec_timeout",
+				Required: false,
+			},
+			{
+				Name:     "what_if",
+				Required: false,
+			},
+			{
+				Name:     "tags",
+				Required: false,
+			},
+		},
+	}
+	procedureFileSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{
+				Type:       "input",
+				LabelNames: []string{"name"},
+			},
+			{
+				Type:       "step",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "procedure",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "block",
+				LabelNames: []string{"id"},
+			},
+			{
+				Type:       "output",
+				LabelNames: []string{"name"},
+			},
+		},
+	}
+	procedureInputBlockSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "type",
+				Required: false,
+			},
+			{
+				Name:     "default",
+				Required: false,
+			},
+			{
+				Name:     "description",
+				Required: false,
+			},
+		},
+	}
+	workflowOutputBlockSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "value",
+				Required: true,
+			},
+			{
+				Name:     "description",
+				Required: false,
+			},
+			{
+				Name:     "sensitive",
+				Required: false,
+			},
+		},
+	}
+	procedureOutputBlockSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "value",
+				Required: true,
+			},
+			{
+				Name:     "description",
+				Required: false,
+			},
 		},
 	}
 	escalateBlockSchema = &hcl.BodySchema{
@@ -150,4 +444,24 @@ var (
 			},
 		},
 	}
+	retryBlockSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{
+				Name:     "attempts",
+				Required: true,
+			},
+			{
+				Name:     "delay",
+				Required: false,
+			},
+			{
+				Name:     "backoff",
+				Required: false,
+			},
+			{
+				Name:     "until",
+				Required: false,
+			},
+		},
+	}
 )
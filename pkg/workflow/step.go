@@ -5,10 +5,14 @@ package workflow
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/trippsoft/forge/pkg/hclutil"
@@ -18,14 +22,29 @@ import (
 	"github.com/trippsoft/forge/pkg/transport"
 	"github.com/trippsoft/forge/pkg/ui"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
 )
 
+// ErrExecTimeout is the error of a step whose module did not complete within its exec_timeout, or whose async job
+// did not finish within its async duration.
+var ErrExecTimeout = errors.New("module execution timed out")
+
+// defaultAsyncPoll is the interval at which the job of an async step is polled if the step has no poll attribute.
+const defaultAsyncPoll = 15 * time.Second
+
 // Step abstracts a single Step or a procedure in a process.
 type Step interface {
 	ID() string                                            // ID returns the identifier of the step.
 	Run(wc *WorkflowContext) (map[string]cty.Value, error) // Run executes the step using the provided WorkflowContext.
 }
 
+// stepGroup is implemented by steps that run other steps of the same step context.
+//
+// The outputs of every step that runs are returned by step ID, so they can be reported with the process outputs.
+type stepGroup interface {
+	runSteps(wc *WorkflowContext) (map[string]map[string]cty.Value, error)
+}
+
 // StepCommonConfig holds common configuration attributes for steps and procedures.
 type StepCommonConfig struct {
 	id   string
@@ -40,6 +59,8 @@ type StepCommonConfig struct {
 	execTimeout *hcl.Attribute
 	whatIf      *hcl.Attribute
 
+	tags []string
+
 	input map[string]*hcl.Attribute
 }
 
@@ -92,6 +113,13 @@ func (s *StepCommonConfig) WhatIf() *hcl.Attribute {
 	return s.whatIf
 }
 
+// Tags returns the tags of the step, including the tags inherited from its parents.
+//
+// This is used primarily for testing purposes.
+func (s *StepCommonConfig) Tags() []string {
+	return s.tags
+}
+
 // Input returns the input attributes of the step.
 //
 // This is used primarily for testing purposes.
@@ -116,6 +144,12 @@ func (s *StepCommonConfig) Combine(other *StepCommonConfig) {
 	if s.whatIf == nil {
 		s.whatIf = other.whatIf
 	}
+
+	for _, tag := range other.tags {
+		if !slices.Contains(s.tags, tag) {
+			s.tags = append(s.tags, tag)
+		}
+	}
 }
 
 // StepLoopConfig holds configuration for step looping.
@@ -240,11 +274,64 @@ func (s *StepOutputConfig) FailedCondition() *hcl.Attribute {
 	return s.failedCondition
 }
 
+// StepRetryConfig holds configuration for retrying a step.
+//
+// This represents the retry block within a step block.
+type StepRetryConfig struct {
+	attempts *hcl.Attribute
+	delay    *hcl.Attribute
+	backoff  *hcl.Attribute
+	until    *hcl.Attribute
+}
+
+// Attempts returns the HCL attribute representing the maximum number of attempts.
+//
+// This is used primarily for testing purposes.
+func (s *StepRetryConfig) Attempts() *hcl.Attribute {
+	return s.attempts
+}
+
+// Delay returns the HCL attribute representing the delay between attempts.
+//
+// This is used primarily for testing purposes.
+func (s *StepRetryConfig) Delay() *hcl.Attribute {
+	return s.delay
+}
+
+// Backoff returns the HCL attribute representing the factor the delay is multiplied by after each attempt.
+//
+// This is used primarily for testing purposes.
+func (s *StepRetryConfig) Backoff() *hcl.Attribute {
+	return s.backoff
+}
+
+// Until returns the HCL attribute representing the condition that stops retrying.
+//
+// This is used primarily for testing purposes.
+func (s *StepRetryConfig) Until() *hcl.Attribute {
+	return s.until
+}
+
 // SingleStep represents a single step within a process.
 type SingleStep struct {
 	common   *StepCommonConfig
 	escalate *StepEscalateConfig
 	output   *StepOutputConfig
+	retry    *StepRetryConfig
+
+	async *hcl.Attribute
+	poll  *hcl.Attribute
+
+	delegateTo *hcl.Attribute
+
+	runOnce     bool
+	runOnceHost *hcl.Attribute
+
+	throttle      int
+	throttleSlots chan struct{}
+
+	notify  []string
+	handler bool
 
 	module module.Module
 }
@@ -275,6 +362,69 @@ func (s *SingleStep) Output() *StepOutputConfig {
 	return s.output
 }
 
+// Retry returns the retry configuration of the step.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) Retry() *StepRetryConfig {
+	return s.retry
+}
+
+// Async returns the attribute of the maximum duration of the step's module when it runs detached on the host.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) Async() *hcl.Attribute {
+	return s.async
+}
+
+// Poll returns the attribute of the interval at which the job of an async step is polled.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) Poll() *hcl.Attribute {
+	return s.poll
+}
+
+// DelegateTo returns the attribute of the host that runs the step's module on behalf of each target.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) DelegateTo() *hcl.Attribute {
+	return s.delegateTo
+}
+
+// RunOnce indicates whether the step runs on a single target and shares its output with the other targets.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) RunOnce() bool {
+	return s.runOnce
+}
+
+// RunOnceHost returns the HCL attribute representing the host that runs a run_once step.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) RunOnceHost() *hcl.Attribute {
+	return s.runOnceHost
+}
+
+// Throttle returns the maximum number of hosts that run the step concurrently, or 0 if there is no limit.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) Throttle() int {
+	return s.throttle
+}
+
+// Notify returns the IDs of the handlers notified when the step reports a change.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) Notify() []string {
+	return slices.Clone(s.notify)
+}
+
+// Handler indicates whether the step is a handler.
+//
+// This is used primarily for testing purposes.
+func (s *SingleStep) Handler() bool {
+	return s.handler
+}
+
 // Module returns the module associated with the step.
 //
 // This is used primarily for testing purposes.
@@ -284,10 +434,35 @@ func (s *SingleStep) Module() module.Module {
 
 // Run implements Step.
 func (s *SingleStep) Run(wc *WorkflowContext) (map[string]cty.Value, error) {
-	wc.ui.PrintHeader(ui.HeaderLevel2, "STEP - ", s.common.name)
+	if s.handler {
+		wc.ui.PrintHeader(ui.HeaderLevel2, "HANDLER - ", s.common.name)
+	} else {
+		wc.ui.PrintHeader(ui.HeaderLevel2, "STEP - ", s.common.name)
+	}
 
 	wc.LoadHostVars()
 
+	var outputs map[string]cty.Value
+	var err error
+	if s.runOnce {
+		outputs, err = s.runOnceOnTargets(wc)
+	} else {
+		outputs, err = s.runOnTargets(wc)
+	}
+
+	if len(s.notify) > 0 {
+		for _, host := range s.common.targets {
+			output, exists := outputs[host.Name()]
+			if exists && outputChanged(output) {
+				wc.notifyHandlers(host, s.notify...)
+			}
+		}
+	}
+
+	return outputs, err
+}
+
+func (s *SingleStep) runOnTargets(wc *WorkflowContext) (map[string]cty.Value, error) {
 	var err error
 	mutex := sync.Mutex{}
 	errChannel := make(chan error)
@@ -295,7 +470,7 @@ func (s *SingleStep) Run(wc *WorkflowContext) (map[string]cty.Value, error) {
 
 	for _, host := range s.common.targets {
 		go func(h *inventory.Host) {
-			if wc.IsFailed(h) {
+			if !wc.IsActive(h) {
 				errChannel <- nil
 				return
 			}
@@ -318,6 +493,82 @@ func (s *SingleStep) Run(wc *WorkflowContext) (map[string]cty.Value, error) {
 	return outputs, err
 }
 
+// runOnceOnTargets runs the step on a single active target and stores its output for every active target.
+//
+// The output is available to each target as steps.<id>, and to every host as hostvars.<target>.steps.<id>.
+// The step runs on the first active target by name, unless run_once_host selects another one.
+// If the step fails, every active target is marked as failed.
+func (s *SingleStep) runOnceOnTargets(wc *WorkflowContext) (map[string]cty.Value, error) {
+	targets := make([]*inventory.Host, 0, len(s.common.targets))
+	for _, host := range s.common.targets {
+		if wc.IsActive(host) {
+			targets = append(targets, host)
+		}
+	}
+
+	outputs := make(map[string]cty.Value, len(targets))
+	if len(targets) == 0 {
+		return outputs, nil
+	}
+
+	slices.SortFunc(targets, func(a, b *inventory.Host) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	runner, err := s.selectRunOnceHost(wc, targets)
+	if err != nil {
+		r := result.NewFailure(err, err.Error())
+		for _, host := range targets {
+			outputs[host.Name()] = s.handleHostResult(NewHostWorkflowContext(wc, host), r)
+			wc.MarkFailed(host)
+		}
+
+		return outputs, err
+	}
+
+	output, err := s.runOnHost(NewHostWorkflowContext(wc, runner))
+	failed := wc.IsFailed(runner)
+
+	for _, host := range targets {
+		outputs[host.Name()] = output
+		if host == runner {
+			continue
+		}
+
+		host.StoreStepOutput(s.common.id, output)
+		if failed {
+			wc.MarkFailed(host)
+		}
+	}
+
+	return outputs, err
+}
+
+func (s *SingleStep) selectRunOnceHost(wc *WorkflowContext, targets []*inventory.Host) (*inventory.Host, error) {
+	if s.runOnceHost == nil {
+		return targets[0], nil
+	}
+
+	hwc := NewHostWorkflowContext(wc, targets[0])
+	err := hwc.LoadEvalContext()
+	if err != nil {
+		return nil, err
+	}
+
+	name, diags := hclutil.ConvertHCLAttributeToString(s.runOnceHost, hwc.evalContext)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, host := range targets {
+		if host.Name() == name {
+			return host, nil
+		}
+	}
+
+	return nil, fmt.Errorf("run_once_host %q is not an active target of the step", name)
+}
+
 func (s *SingleStep) runOnHost(hwc *HostWorkflowContext) (cty.Value, error) {
 	err := hwc.LoadEvalContext()
 	if err != nil {
@@ -412,7 +663,7 @@ func (s *SingleStep) runOnHost(hwc *HostWorkflowContext) (cty.Value, error) {
 	return output, err
 }
 
-func (s *SingleStep) formatResultOutput(result *result.Result) cty.Value {
+func formatResultOutput(result *result.Result) cty.Value {
 	outputMap := map[string]cty.Value{
 		"failed":  cty.BoolVal(result.Failed),
 		"skipped": cty.BoolVal(result.Skipped),
@@ -439,7 +690,7 @@ func (s *SingleStep) handleHostResult(hwc *HostWorkflowContext, r *result.Result
 	}
 
 	hwc.ui.PrintHostResult(hwc.host.Name(), r)
-	output := s.formatResultOutput(r)
+	output := formatResultOutput(r)
 	hwc.host.StoreStepOutput(s.common.id, output)
 
 	return output
@@ -456,7 +707,7 @@ func (s *SingleStep) handleHostIterationResult(
 	}
 
 	hwc.ui.PrintIterationResult(hwc.host.Name(), iteration.label, r)
-	return s.formatResultOutput(r)
+	return formatResultOutput(r)
 }
 
 func (s *SingleStep) getStepIterator(hwc *HostWorkflowContext) (StepIterator, error) {
@@ -555,7 +806,21 @@ func (s *SingleStep) runHostIteration(hwc *HostWorkflowContext, iteration *StepI
 		}
 	}
 
-	escalation, err := s.getEscalation(hwc)
+	delegate, err := s.getDelegateHost(hwc)
+	if err != nil {
+		result := result.NewFailure(err, err.Error())
+		return s.handleHostIterationResult(hwc, iteration, result), err
+	}
+
+	if delegate != hwc.host {
+		err = hwc.discoverDelegateInfo(delegate)
+		if err != nil {
+			result := result.NewFailure(err, err.Error())
+			return s.handleHostIterationResult(hwc, iteration, result), err
+		}
+	}
+
+	escalation, err := s.getEscalation(hwc, delegate)
 	if err != nil {
 		result := result.NewFailure(err, err.Error())
 		return s.handleHostIterationResult(hwc, iteration, result), err
@@ -605,22 +870,53 @@ func (s *SingleStep) runHostIteration(hwc *HostWorkflowContext, iteration *StepI
 	}
 
 	config := &module.RunConfig{
-		Transport:  hwc.host.Transport(),
-		HostInfo:   hwc.host.Info(),
+		Transport:  delegate.Transport(),
+		HostInfo:   delegate.Info(),
 		Escalation: escalation,
 		WhatIf:     whatIf,
 		Input:      input,
 	}
 
-	runCtx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	attempts, delay, backoff, diags := s.getRetrySettings(hwc)
+	if diags.HasErrors() {
+		result := result.NewFailure(diags, diags.Error())
+		return s.handleHostIterationResult(hwc, iteration, result), diags
+	}
+
+	var result *result.Result
+	attempt := 0
+	for {
+		attempt++
+		result = s.runModule(hwc, config, timeout)
+
+		done := s.retryDone(hwc, result)
+		if done || attempt >= attempts {
+			if !done && result != nil && s.retry != nil && s.retry.until != nil {
+				result.Failed = true
+				result.Error = errors.Join(
+					result.Error,
+					fmt.Errorf("until condition was not met after %d attempts", attempt),
+				)
+			}
+
+			break
+		}
+
+		hwc.ui.PrintAttemptResult(hwc.host.Name(), iteration.label, attempt, attempts, result)
+
+		if !hwc.sleep(delay) {
+			break
+		}
+
+		delay = time.Duration(float64(delay) * backoff)
+	}
 
-	result := s.module.Run(runCtx, config)
 	if result == nil || s.output == nil {
-		return s.handleHostIterationResult(hwc, iteration, result), nil
+		s.setRuntimeVars(hwc, result)
+		return s.withAttempts(s.handleHostIterationResult(hwc, iteration, result), attempt), resultError(result)
 	}
 
-	output := s.formatResultOutput(result)
+	output := s.withAttempts(formatResultOutput(result), attempt)
 
 	hwc.evalContext.Variables["result"] = output
 	defer delete(hwc.evalContext.Variables, "result")
@@ -655,16 +951,357 @@ func (s *SingleStep) runHostIteration(hwc *HostWorkflowContext, iteration *StepI
 		}
 	}
 
+	s.setRuntimeVars(hwc, result)
 	s.handleHostIterationResult(hwc, iteration, result)
 
 	if continueOnFail {
 		return output, nil
 	}
 
-	return output, result.Error
+	return output, resultError(result)
+}
+
+// resultError returns the error of a failed result, or nil if the result did not fail.
+//
+// A result that failed without an error, such as one failed by a failed_when condition, returns a generic error.
+func resultError(r *result.Result) error {
+	if r == nil {
+		return errors.New("no result returned from module")
+	}
+
+	if !r.Failed {
+		return nil
+	}
+
+	if r.Error != nil {
+		return r.Error
+	}
+
+	return errors.New("the step failed")
+}
+
+// runModule runs the module of the step on the host once.
+//
+// If the module fails after its exec_timeout elapses, the result is a failure wrapping ErrExecTimeout.
+func (s *SingleStep) runModule(
+	hwc *HostWorkflowContext,
+	config *module.RunConfig,
+	timeout time.Duration,
+) *result.Result {
+
+	if s.async != nil {
+		return s.runAsyncModule(hwc, config, timeout)
+	}
+
+	return s.runSession(hwc, timeout, func(ctx context.Context) *result.Result {
+		return s.module.Run(ctx, config)
+	})
+}
+
+// runSession runs a session with the module of the step on the host.
+//
+// The session holds a throttle slot and a fork while it runs and is cancelled when the exec_timeout elapses.
+func (s *SingleStep) runSession(
+	hwc *HostWorkflowContext,
+	timeout time.Duration,
+	run func(ctx context.Context) *result.Result,
+) *result.Result {
+
+	if s.throttleSlots != nil {
+		s.throttleSlots <- struct{}{}
+	}
+
+	hwc.acquireFork()
+
+	runCtx, cancel := context.WithTimeoutCause(
+		hwc.ctx,
+		timeout,
+		fmt.Errorf("%w: the module did not complete within %s", ErrExecTimeout, timeout),
+	)
+	r := run(runCtx)
+	cause := context.Cause(runCtx)
+	cancel()
+
+	hwc.releaseFork()
+
+	if s.throttleSlots != nil {
+		<-s.throttleSlots
+	}
+
+	if r == nil {
+		r = result.NewFailure(errors.New("no result returned from module"), "")
+	}
+
+	if r.Failed && errors.Is(cause, ErrExecTimeout) && !errors.Is(r.Error, ErrExecTimeout) {
+		detail := r.ErrorDetail
+		if detail == "" && r.Error != nil {
+			detail = r.Error.Error()
+		}
+
+		r = result.NewFailure(cause, detail)
+	}
+
+	return r
 }
 
-func (s *SingleStep) getEscalation(hwc *HostWorkflowContext) (*transport.Escalation, error) {
+// runAsyncModule starts the module of the step detached on the host and polls its job until it finishes.
+//
+// Each session that starts or polls the job is limited by the exec_timeout, while the job is limited by the async
+// duration. If poll is zero, the job is not polled and the step reports that it started.
+func (s *SingleStep) runAsyncModule(
+	hwc *HostWorkflowContext,
+	config *module.RunConfig,
+	timeout time.Duration,
+) *result.Result {
+
+	asyncModule, ok := s.module.(module.AsyncModule)
+	if !ok {
+		return result.NewFailure(fmt.Errorf("the module %q cannot run asynchronously", s.module.ID().ModuleName()), "")
+	}
+
+	asyncTimeout, poll, diags := s.getAsyncSettings(hwc)
+	if diags.HasErrors() {
+		return result.NewFailure(diags, diags.Error())
+	}
+
+	var status *module.AsyncStatus
+	runAsyncSession := func(run func(ctx context.Context) (*module.AsyncStatus, error)) *result.Result {
+		return s.runSession(hwc, timeout, func(ctx context.Context) *result.Result {
+			var err error
+			status, err = run(ctx)
+			if err != nil {
+				return result.NewFailure(err, "")
+			}
+
+			return result.NewNotChanged(status.Output())
+		})
+	}
+
+	r := runAsyncSession(func(ctx context.Context) (*module.AsyncStatus, error) {
+		return asyncModule.StartAsync(ctx, config, rand.Text(), asyncTimeout)
+	})
+	if r.Failed || poll == 0 {
+		return r
+	}
+
+	deadline := time.Now().Add(asyncTimeout + poll)
+	for !status.Finished {
+		if time.Now().After(deadline) {
+			err := fmt.Errorf("%w: the async job %q did not finish within %s", ErrExecTimeout, status.ID, asyncTimeout)
+			return result.NewFailure(err, "")
+		}
+
+		timer := time.NewTimer(poll)
+		select {
+		case <-timer.C:
+		case <-hwc.ctx.Done():
+			timer.Stop()
+			return result.NewFailure(context.Cause(hwc.ctx), "")
+		}
+
+		jobFile := status.JobFile
+		r = runAsyncSession(func(ctx context.Context) (*module.AsyncStatus, error) {
+			return asyncModule.PollAsync(ctx, config, jobFile)
+		})
+		if r.Failed {
+			return r
+		}
+	}
+
+	return status.Result
+}
+
+// getAsyncSettings evaluates the async duration and poll interval of the step for the host.
+//
+// Without a poll attribute, the job is polled every defaultAsyncPoll.
+func (s *SingleStep) getAsyncSettings(hwc *HostWorkflowContext) (time.Duration, time.Duration, hcl.Diagnostics) {
+	asyncTimeout, diags := hclutil.ConvertHCLAttributeToDuration(s.async, hwc.evalContext)
+	if diags.HasErrors() {
+		return 0, 0, diags
+	}
+
+	if asyncTimeout <= 0 {
+		return 0, 0, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid async",
+			Detail:   "The 'async' attribute must be a positive duration.",
+			Subject:  s.async.Expr.Range().Ptr(),
+		})
+	}
+
+	if s.poll == nil {
+		return asyncTimeout, defaultAsyncPoll, diags
+	}
+
+	poll, moreDiags := s.getPollInterval(hwc)
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return 0, 0, diags
+	}
+
+	if poll < 0 {
+		return 0, 0, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid poll",
+			Detail:   "The 'poll' attribute must not be negative.",
+			Subject:  s.poll.Expr.Range().Ptr(),
+		})
+	}
+
+	return asyncTimeout, poll, diags
+}
+
+// getPollInterval evaluates the poll attribute of the step, which is a duration string or a number of seconds.
+func (s *SingleStep) getPollInterval(hwc *HostWorkflowContext) (time.Duration, hcl.Diagnostics) {
+	value, diags := s.poll.Expr.Value(hwc.evalContext)
+	if diags.HasErrors() {
+		return 0, diags
+	}
+
+	if !value.Type().Equals(cty.Number) || value.IsNull() || !value.IsKnown() {
+		return hclutil.ConvertHCLAttributeToDuration(s.poll, hwc.evalContext)
+	}
+
+	seconds, _ := value.AsBigFloat().Float64()
+	return time.Duration(seconds * float64(time.Second)), diags
+}
+
+// getRetrySettings evaluates the retry configuration of the step for the host.
+//
+// Without a retry block, the step is attempted once.
+func (s *SingleStep) getRetrySettings(hwc *HostWorkflowContext) (int, time.Duration, float64, hcl.Diagnostics) {
+	if s.retry == nil {
+		return 1, 0, 1, nil
+	}
+
+	attempts, diags := hclutil.ConvertHCLAttributeToUint16(s.retry.attempts, hwc.evalContext)
+	if diags.HasErrors() {
+		return 0, 0, 0, diags
+	}
+
+	if attempts < 1 {
+		return 0, 0, 0, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid attempts",
+			Detail:   "The 'attempts' attribute must be at least 1.",
+			Subject:  s.retry.attempts.Expr.Range().Ptr(),
+		})
+	}
+
+	var delay time.Duration
+	if s.retry.delay != nil {
+		var moreDiags hcl.Diagnostics
+		delay, moreDiags = hclutil.ConvertHCLAttributeToDuration(s.retry.delay, hwc.evalContext)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return 0, 0, 0, diags
+		}
+	}
+
+	backoff := 1.0
+	if s.retry.backoff != nil {
+		value, moreDiags := s.retry.backoff.Expr.Value(hwc.evalContext)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			return 0, 0, 0, diags
+		}
+
+		err := gocty.FromCtyValue(value, &backoff)
+		if err != nil || backoff < 1 {
+			return 0, 0, 0, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid backoff",
+				Detail:   "The 'backoff' attribute must be a number greater than or equal to 1.",
+				Subject:  s.retry.backoff.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	return int(attempts), delay, backoff, diags
+}
+
+// retryDone checks if the result of an attempt means the step should not be retried.
+//
+// If the retry block has an until condition, it is evaluated against the result.
+// Otherwise, the step is retried while it fails.
+func (s *SingleStep) retryDone(hwc *HostWorkflowContext, r *result.Result) bool {
+	if r == nil {
+		return false
+	}
+
+	if s.retry == nil || s.retry.until == nil {
+		return !r.Failed
+	}
+
+	hwc.evalContext.Variables["result"] = formatResultOutput(r)
+	defer delete(hwc.evalContext.Variables, "result")
+
+	done, diags := hclutil.ConvertHCLAttributeToBool(s.retry.until, hwc.evalContext)
+	if diags.HasErrors() {
+		r.Failed = true
+		r.Error = errors.Join(r.Error, diags)
+		return true
+	}
+
+	return done
+}
+
+// withAttempts adds the number of attempts made to the output if the step has a retry block.
+func (s *SingleStep) withAttempts(output cty.Value, attempts int) cty.Value {
+	if s.retry == nil || output.IsNull() || !output.Type().IsObjectType() {
+		return output
+	}
+
+	outputMap := output.AsValueMap()
+	outputMap["attempts"] = cty.NumberIntVal(int64(attempts))
+
+	return cty.ObjectVal(outputMap)
+}
+
+// setRuntimeVars sets the runtime variables of the host from the result of a module that sets them.
+//
+// In debug mode, the variables are added to the messages of the result, marked as runtime values.
+func (s *SingleStep) setRuntimeVars(hwc *HostWorkflowContext, r *result.Result) {
+	varsModule, ok := s.module.(module.RuntimeVarsModule)
+	if !ok || r == nil || r.Failed || r.Skipped {
+		return
+	}
+
+	vars := varsModule.RuntimeVars(r.Output)
+	if len(vars) == 0 {
+		return
+	}
+
+	hwc.host.SetRuntimeVars(vars)
+
+	if !hwc.debug {
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		value := hclutil.FormatCtyValueToIndentedString(vars[name], 0, 4)
+		r.Messages = append(r.Messages, fmt.Sprintf("var.%s = %s (runtime value)", name, value))
+	}
+}
+
+// getDelegateHost returns the host whose transport runs the module of the step for the host of the context.
+//
+// Unless delegate_to names another host, the module runs on the host itself.
+func (s *SingleStep) getDelegateHost(hwc *HostWorkflowContext) (*inventory.Host, error) {
+	if s.delegateTo == nil {
+		return hwc.host, nil
+	}
+
+	name, diags := hclutil.ConvertHCLAttributeToString(s.delegateTo, hwc.evalContext)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return hwc.delegateHost(name)
+}
+
+// getEscalation returns the escalation of the step, using the escalation password of the host that runs the module.
+func (s *SingleStep) getEscalation(hwc *HostWorkflowContext, runner *inventory.Host) (*transport.Escalation, error) {
 	if s.escalate == nil || s.escalate.escalate == nil {
 		return nil, nil // No escalation configured
 	}
@@ -678,7 +1315,7 @@ func (s *SingleStep) getEscalation(hwc *HostWorkflowContext) (*transport.Escalat
 		return nil, nil // No escalation needed
 	}
 
-	password := hwc.host.EscalateConfig().Pass()
+	password := runner.EscalateConfig().Pass()
 	if s.escalate.password != nil {
 		p, diags := hclutil.ConvertHCLAttributeToString(s.escalate.password, hwc.evalContext)
 		if diags.HasErrors() {
@@ -709,6 +1346,21 @@ type SingleStepBuilder struct {
 	common   *StepCommonConfig
 	escalate *StepEscalateConfig
 	output   *StepOutputConfig
+	retry    *StepRetryConfig
+
+	async *hcl.Attribute
+	poll  *hcl.Attribute
+
+	delegateTo *hcl.Attribute
+
+	runOnce     bool
+	runOnceHost *hcl.Attribute
+
+	throttle int
+
+	notify     []string
+	notifyAttr *hcl.Attribute
+	handler    bool
 
 	module module.Module
 }
@@ -731,6 +1383,63 @@ func (s *SingleStepBuilder) WithOutput(output *StepOutputConfig) *SingleStepBuil
 	return s
 }
 
+// WithRetry sets the retry configuration for the single step.
+func (s *SingleStepBuilder) WithRetry(retry *StepRetryConfig) *SingleStepBuilder {
+	s.retry = retry
+	return s
+}
+
+// WithAsync sets the maximum duration of the single step's module when it runs detached on the host.
+func (s *SingleStepBuilder) WithAsync(async *hcl.Attribute) *SingleStepBuilder {
+	s.async = async
+	return s
+}
+
+// WithPoll sets the interval at which the job of an async single step is polled.
+func (s *SingleStepBuilder) WithPoll(poll *hcl.Attribute) *SingleStepBuilder {
+	s.poll = poll
+	return s
+}
+
+// WithDelegateTo sets the expression that selects the host that runs the single step's module on behalf of each target.
+func (s *SingleStepBuilder) WithDelegateTo(delegateTo *hcl.Attribute) *SingleStepBuilder {
+	s.delegateTo = delegateTo
+	return s
+}
+
+// WithRunOnce sets whether the single step runs on a single target.
+func (s *SingleStepBuilder) WithRunOnce(runOnce bool) *SingleStepBuilder {
+	s.runOnce = runOnce
+	return s
+}
+
+// WithRunOnceHost sets the expression that selects the host that runs a run_once step.
+func (s *SingleStepBuilder) WithRunOnceHost(runOnceHost *hcl.Attribute) *SingleStepBuilder {
+	s.runOnceHost = runOnceHost
+	return s
+}
+
+// WithThrottle sets the maximum number of hosts that run the single step concurrently.
+//
+// A value less than 1 removes the limit.
+func (s *SingleStepBuilder) WithThrottle(throttle int) *SingleStepBuilder {
+	s.throttle = throttle
+	return s
+}
+
+// WithNotify sets the IDs of the handlers notified when the single step reports a change.
+func (s *SingleStepBuilder) WithNotify(attr *hcl.Attribute, handlers ...string) *SingleStepBuilder {
+	s.notifyAttr = attr
+	s.notify = handlers
+	return s
+}
+
+// AsHandler marks the single step as a handler.
+func (s *SingleStepBuilder) AsHandler() *SingleStepBuilder {
+	s.handler = true
+	return s
+}
+
 // WithModule sets the module for the single step.
 func (s *SingleStepBuilder) WithModule(module module.Module) *SingleStepBuilder {
 	s.module = module
@@ -760,6 +1469,29 @@ func (s *SingleStepBuilder) AllTargets() []*inventory.Host {
 	return targets
 }
 
+// notifications implements handlerNotifier.
+func (s *SingleStepBuilder) notifications() []*handlerNotification {
+	notifications := make([]*handlerNotification, 0, len(s.notify))
+	for _, handler := range s.notify {
+		notifications = append(notifications, &handlerNotification{
+			handler: handler,
+			subject: s.notifyAttr.Expr.Range().Ptr(),
+		})
+	}
+
+	return notifications
+}
+
+// filterTags implements taggedStepBuilder.
+func (s *SingleStepBuilder) filterTags(filter *TagFilter) bool {
+	return filter.Selects(s.common.tags)
+}
+
+// allTags implements taggedStepBuilder.
+func (s *SingleStepBuilder) allTags() []string {
+	return s.common.tags
+}
+
 // Build implements StepBuilder.
 func (s *SingleStepBuilder) Build() (Step, error) {
 	if s.common == nil {
@@ -782,11 +1514,26 @@ func (s *SingleStepBuilder) Build() (Step, error) {
 		return nil, errors.New("Step failed to build: module is missing")
 	}
 
+	var throttleSlots chan struct{}
+	if s.throttle > 0 {
+		throttleSlots = make(chan struct{}, s.throttle)
+	}
+
 	return &SingleStep{
-		common:   s.common,
-		escalate: s.escalate,
-		output:   s.output,
-		module:   s.module,
+		common:        s.common,
+		escalate:      s.escalate,
+		output:        s.output,
+		retry:         s.retry,
+		async:         s.async,
+		poll:          s.poll,
+		delegateTo:    s.delegateTo,
+		runOnce:       s.runOnce,
+		runOnceHost:   s.runOnceHost,
+		throttle:      s.throttle,
+		throttleSlots: throttleSlots,
+		notify:        s.notify,
+		handler:       s.handler,
+		module:        s.module,
 	}, nil
 }
 
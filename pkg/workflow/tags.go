@@ -0,0 +1,112 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package workflow
+
+import (
+	"slices"
+
+	"github.com/trippsoft/forge/pkg/set"
+)
+
+const (
+	// TagAlways is the tag of steps that run unless the tag is skipped explicitly.
+	TagAlways = "always"
+	// TagNever is the tag of steps that only run if one of their tags, including inherited tags, is selected explicitly.
+	TagNever = "never"
+	// TagAll selects every step that is not tagged with TagNever.
+	TagAll = "all"
+	// TagTagged selects every step that has at least one tag.
+	TagTagged = "tagged"
+	// TagUntagged selects every step that has no tags.
+	TagUntagged = "untagged"
+)
+
+// TagFilter selects the steps of a workflow that run based on their tags.
+type TagFilter struct {
+	tags     *set.Set[string]
+	skipTags *set.Set[string]
+}
+
+// NewTagFilter creates a new TagFilter that selects steps with any of the tags and skips steps with any of the skip
+// tags.
+//
+// If no tags are provided, every step is selected unless it is skipped or tagged with TagNever.
+func NewTagFilter(tags []string, skipTags []string) *TagFilter {
+	return &TagFilter{
+		tags:     set.NewSet(tags...),
+		skipTags: set.NewSet(skipTags...),
+	}
+}
+
+// Selects checks if a step with the given tags is selected by the filter.
+func (f *TagFilter) Selects(tags []string) bool {
+	if f == nil {
+		return !slices.Contains(tags, TagNever)
+	}
+
+	if matchesTags(f.skipTags, tags) {
+		return false
+	}
+
+	if slices.Contains(tags, TagNever) {
+		return slices.ContainsFunc(tags, f.tags.Contains)
+	}
+
+	if slices.Contains(tags, TagAlways) || f.tags.IsEmpty() {
+		return true
+	}
+
+	return matchesTags(f.tags, tags)
+}
+
+// matchesTags checks if a step with the given tags matches any of the filter tags, including the special filter tags.
+func matchesTags(filter *set.Set[string], tags []string) bool {
+	switch {
+	case filter.Contains(TagAll):
+		return true
+	case filter.Contains(TagTagged) && len(tags) > 0:
+		return true
+	case filter.Contains(TagUntagged) && len(tags) == 0:
+		return true
+	}
+
+	return slices.ContainsFunc(tags, filter.Contains)
+}
+
+// taggedStepBuilder is implemented by step builders that can be selected with tags.
+type taggedStepBuilder interface {
+	// filterTags removes the nested steps not selected by the filter and reports whether the step is selected.
+	filterTags(filter *TagFilter) bool
+
+	// allTags returns the tags of the step and its nested steps.
+	allTags() []string
+}
+
+// filterTaggedSteps returns the step builders selected by the filter.
+func filterTaggedSteps(builders []StepBuilder, filter *TagFilter) []StepBuilder {
+	selected := make([]StepBuilder, 0, len(builders))
+	for _, sb := range builders {
+		if tagged, ok := sb.(taggedStepBuilder); ok && !tagged.filterTags(filter) {
+			continue
+		}
+
+		selected = append(selected, sb)
+	}
+
+	return selected
+}
+
+// collectTags adds the tags of the step builders and their nested steps to the set.
+func collectTags(tags *set.Set[string], builders []StepBuilder) {
+	for _, sb := range builders {
+		tagged, ok := sb.(taggedStepBuilder)
+		if !ok {
+			continue
+		}
+
+		for _, tag := range tagged.allTags() {
+			tags.Add(tag)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package workflow
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/hclfunction"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/set"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// targetsEvalContext returns the evaluation context of the targets attribute.
+//
+// In addition to the standard functions, it provides functions that combine the hosts of targets:
+//   - union(targets...) returns the names of the hosts in any of the targets
+//   - intersect(targets...) returns the names of the hosts in all of the targets
+//   - difference(target, targets...) returns the names of the hosts in the first target but none of the others
+//   - hosts(targets...) returns an object with the name and vars of each host in any of the targets
+//
+// Each target is a host pattern or a list of host patterns.
+// The result of hosts can be filtered with a for expression to select hosts by their variables.
+func (p *Parser) targetsEvalContext() (*hcl.EvalContext, error) {
+	if p.targetsCtx != nil {
+		return p.targetsCtx, nil
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	functions := hclfunction.HCLFunctions(workingDir)
+	functions["union"] = p.targetSetFunction(set.Union[*inventory.Host])
+	functions["intersect"] = p.targetSetFunction(set.Intersection[*inventory.Host])
+	functions["difference"] = p.targetSetFunction(func(sets ...*set.Set[*inventory.Host]) *set.Set[*inventory.Host] {
+		return set.Difference(sets[0], set.Union(sets[1:]...))
+	})
+	functions["hosts"] = p.hostsFunction()
+
+	p.targetsCtx = &hcl.EvalContext{
+		Functions: functions,
+	}
+
+	return p.targetsCtx, nil
+}
+
+// targetSetFunction creates a function that combines the hosts of its targets and returns their sorted names.
+func (p *Parser) targetSetFunction(
+	combine func(sets ...*set.Set[*inventory.Host]) *set.Set[*inventory.Host],
+) function.Function {
+
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "target",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		VarParam: &function.Parameter{
+			Name: "targets",
+			Type: cty.DynamicPseudoType,
+		},
+		Type: function.StaticReturnType(cty.List(cty.String)),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			sets := make([]*set.Set[*inventory.Host], 0, len(args))
+			for _, arg := range args {
+				hosts, err := p.resolveTargetValue(arg)
+				if err != nil {
+					return cty.NilVal, err
+				}
+
+				sets = append(sets, hosts)
+			}
+
+			hosts := sortedHosts(combine(sets...))
+			if len(hosts) == 0 {
+				return cty.ListValEmpty(cty.String), nil
+			}
+
+			names := make([]cty.Value, 0, len(hosts))
+			for _, host := range hosts {
+				names = append(names, cty.StringVal(host.Name()))
+			}
+
+			return cty.ListVal(names), nil
+		},
+	})
+}
+
+// hostsFunction creates a function that returns the name and vars of each host in any of its targets.
+func (p *Parser) hostsFunction() function.Function {
+	return function.New(&function.Spec{
+		VarParam: &function.Parameter{
+			Name: "targets",
+			Type: cty.DynamicPseudoType,
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			hosts := set.NewSet[*inventory.Host]()
+			for _, arg := range args {
+				argHosts, err := p.resolveTargetValue(arg)
+				if err != nil {
+					return cty.NilVal, err
+				}
+
+				hosts = set.Union(hosts, argHosts)
+			}
+
+			values := []cty.Value{}
+			for _, host := range sortedHosts(hosts) {
+				values = append(values, cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal(host.Name()),
+					"vars": cty.ObjectVal(host.Vars()),
+				}))
+			}
+
+			return cty.TupleVal(values), nil
+		},
+	})
+}
+
+// resolveTargetValue resolves a host pattern or a list of host patterns to the hosts they select.
+func (p *Parser) resolveTargetValue(value cty.Value) (*set.Set[*inventory.Host], error) {
+	if value.IsNull() || !value.IsWhollyKnown() {
+		return nil, errors.New("targets must be known and not null")
+	}
+
+	valueType := value.Type()
+	switch {
+	case valueType.Equals(cty.String):
+		hosts, err := p.inventory.ResolvePattern(value.AsString())
+		if err != nil {
+			return nil, err
+		}
+
+		return set.NewSet(hosts...), nil
+
+	case valueType.IsListType() || valueType.IsSetType() || valueType.IsTupleType():
+		hosts := set.NewSet[*inventory.Host]()
+		for _, elem := range value.AsValueSlice() {
+			if !elem.Type().Equals(cty.String) {
+				return nil, errors.New("targets must be a string or a list of strings")
+			}
+
+			elemHosts, err := p.resolveTargetValue(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			hosts = set.Union(hosts, elemHosts)
+		}
+
+		return hosts, nil
+	}
+
+	return nil, errors.New("targets must be a string or a list of strings")
+}
+
+// sortedHosts returns the hosts in the set sorted by name.
+func sortedHosts(hosts *set.Set[*inventory.Host]) []*inventory.Host {
+	items := hosts.Items()
+	slices.SortFunc(items, func(a, b *inventory.Host) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return items
+}
@@ -0,0 +1,338 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/trippsoft/forge/pkg/hclfunction"
+	"github.com/trippsoft/forge/pkg/hclutil"
+	"github.com/trippsoft/forge/pkg/secret"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// VariableEnvPrefix is the prefix of environment variables that assign values to workflow variables.
+const VariableEnvPrefix = "FORGE_VAR_"
+
+// Variable represents an input variable declared by a workflow.
+//
+// This represents a variable block within a workflow file.
+type Variable struct {
+	name         string
+	varType      cty.Type
+	defaultValue cty.Value
+	required     bool
+	description  string
+	sensitive    bool
+	validations  []*VariableValidation
+}
+
+// Name returns the name of the variable.
+//
+// This is used primarily for testing purposes.
+func (v *Variable) Name() string {
+	return v.name
+}
+
+// Type returns the type constraint of the variable.
+//
+// This is used primarily for testing purposes.
+func (v *Variable) Type() cty.Type {
+	return v.varType
+}
+
+// Default returns the default value of the variable.
+//
+// This is used primarily for testing purposes.
+func (v *Variable) Default() cty.Value {
+	return v.defaultValue
+}
+
+// Required indicates whether a value must be assigned to the variable.
+//
+// This is used primarily for testing purposes.
+func (v *Variable) Required() bool {
+	return v.required
+}
+
+// Description returns the description of the variable.
+//
+// This is used primarily for testing purposes.
+func (v *Variable) Description() string {
+	return v.description
+}
+
+// Sensitive indicates whether the value of the variable is filtered from output.
+//
+// This is used primarily for testing purposes.
+func (v *Variable) Sensitive() bool {
+	return v.sensitive
+}
+
+// Validations returns the validation rules of the variable.
+//
+// This is used primarily for testing purposes.
+func (v *Variable) Validations() []*VariableValidation {
+	return v.validations
+}
+
+// parseRawValue converts a value assigned from the command line or an environment variable.
+//
+// Values of primitive types are taken as strings and converted to the variable's type.
+// Values of other types are parsed as HCL expressions.
+func (v *Variable) parseRawValue(raw string, source string) (cty.Value, hcl.Diagnostics) {
+	if v.varType == cty.DynamicPseudoType || v.varType.IsPrimitiveType() {
+		return cty.StringVal(raw), nil
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(raw), source, hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+
+	return expr.Value(nil)
+}
+
+// validate converts the value to the variable's type and checks it against the validation rules.
+func (v *Variable) validate(value cty.Value, workingDir string) (cty.Value, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	value, err := convert.Convert(value, v.varType)
+	if err != nil {
+		return cty.NilVal, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value for variable",
+			Detail:   fmt.Sprintf("The value of variable %q is not a valid %s: %s", v.name, v.varType.FriendlyName(), err),
+		})
+	}
+
+	evalCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"variable": cty.ObjectVal(map[string]cty.Value{v.name: value}),
+		},
+		Functions: hclfunction.HCLFunctions(workingDir),
+	}
+
+	for _, validation := range v.validations {
+		valid, moreDiags := hclutil.ConvertHCLAttributeToBool(validation.condition, evalCtx)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() || valid {
+			continue
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value for variable",
+			Detail:   validation.errorMessage,
+			Subject:  validation.condition.Expr.Range().Ptr(),
+		})
+	}
+
+	return value, diags
+}
+
+// VariableValidation represents a validation rule of a workflow variable.
+//
+// This represents a validation block within a variable block.
+type VariableValidation struct {
+	condition    *hcl.Attribute
+	errorMessage string
+}
+
+// Condition returns the HCL attribute representing the condition the value must meet.
+//
+// This is used primarily for testing purposes.
+func (v *VariableValidation) Condition() *hcl.Attribute {
+	return v.condition
+}
+
+// ErrorMessage returns the message reported when the value does not meet the condition.
+//
+// This is used primarily for testing purposes.
+func (v *VariableValidation) ErrorMessage() string {
+	return v.errorMessage
+}
+
+// ResolveVariables determines the value of every variable declared by the workflow.
+//
+// Values are assigned, in order of increasing precedence, by the default of the variable, environment variables
+// named with VariableEnvPrefix, the variable files, and the raw values in the form name=value.
+// Variable files are parsed as JSON if they have a .json extension, and as HCL otherwise.
+// The values of sensitive variables are registered with the secret filter.
+func (w *Workflow) ResolveVariables(
+	environ []string,
+	varFiles []string,
+	rawValues []string,
+) (map[string]cty.Value, hcl.Diagnostics) {
+
+	diags := hcl.Diagnostics{}
+	values := make(map[string]cty.Value, len(w.variables))
+
+	variables := make(map[string]*Variable, len(w.variables))
+	for _, v := range w.variables {
+		variables[v.name] = v
+	}
+
+	for _, env := range environ {
+		name, raw, found := strings.Cut(env, "=")
+		if !found || !strings.HasPrefix(name, VariableEnvPrefix) {
+			continue
+		}
+
+		v, exists := variables[strings.TrimPrefix(name, VariableEnvPrefix)]
+		if !exists {
+			continue
+		}
+
+		value, moreDiags := v.parseRawValue(raw, name)
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() {
+			values[v.name] = value
+		}
+	}
+
+	parser := hclparse.NewParser()
+	for _, path := range varFiles {
+		fileValues, moreDiags := parseVariableFile(parser, path)
+		diags = diags.Extend(moreDiags)
+
+		for name, value := range fileValues {
+			if _, exists := variables[name]; !exists {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagWarning,
+					Summary:  "Value for undeclared variable",
+					Detail:   fmt.Sprintf("The file %q assigns a value to %q, which is not declared by the workflow.", path, name),
+				})
+				continue
+			}
+
+			values[name] = value
+		}
+	}
+
+	for _, rawValue := range rawValues {
+		name, raw, found := strings.Cut(rawValue, "=")
+		if !found {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid variable assignment",
+				Detail:   fmt.Sprintf("The variable assignment %q must be in the form name=value.", rawValue),
+			})
+			continue
+		}
+
+		v, exists := variables[name]
+		if !exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Undeclared variable",
+				Detail:   fmt.Sprintf("A value was assigned to %q, which is not declared by the workflow.", name),
+			})
+			continue
+		}
+
+		value, moreDiags := v.parseRawValue(raw, fmt.Sprintf("<value for variable.%s>", name))
+		diags = diags.Extend(moreDiags)
+		if !moreDiags.HasErrors() {
+			values[name] = value
+		}
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to get working directory",
+			Detail:   err.Error(),
+		})
+	}
+
+	for _, v := range w.variables {
+		value, assigned := values[v.name]
+		if !assigned {
+			if v.required {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Missing variable value",
+					Detail:   fmt.Sprintf("The variable %q has no default value, so a value must be assigned.", v.name),
+				})
+				continue
+			}
+
+			value = v.defaultValue
+		}
+
+		value, moreDiags := v.validate(value, workingDir)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			delete(values, v.name)
+			continue
+		}
+
+		if v.sensitive {
+			for _, s := range hclutil.GetAllCtyStrings(value) {
+				secret.SecretFilter.AddSecret(s)
+			}
+		}
+
+		values[v.name] = value
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return values, diags
+}
+
+// parseVariableFile parses the values assigned by a variable file.
+func parseVariableFile(parser *hclparse.Parser, path string) (map[string]cty.Value, hcl.Diagnostics) {
+	diags := hcl.Diagnostics{}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read variable file",
+			Detail:   fmt.Sprintf("The variable file %q could not be read: %s", path, err.Error()),
+		})
+	}
+
+	var file *hcl.File
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		file, diags = parser.ParseJSON(content, path)
+	} else {
+		file, diags = parser.ParseHCL(content, path)
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	attrs, moreDiags := file.Body.JustAttributes()
+	diags = diags.Extend(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	values := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		value, moreDiags := attr.Expr.Value(nil)
+		diags = diags.Extend(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		values[name] = value
+	}
+
+	return values, diags
+}
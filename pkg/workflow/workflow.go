@@ -5,8 +5,10 @@ package workflow
 
 import (
 	"errors"
+	"maps"
 	"slices"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -14,16 +16,27 @@ import (
 //
 // This is the parsed representation of a workflow file.
 type Workflow struct {
+	variables []*Variable
+	locals    map[string]*hcl.Attribute
 	processes []*Process
+	outputs   []*Output
 }
 
 // Run executes the workflow using the provided WorkflowContext.
 func (w *Workflow) Run(wc *WorkflowContext) ([]map[string]map[string]cty.Value, error) {
 	wc.inventory.ClearSteps() // Clear any previous step contexts and procedure inputs
+	wc.inventory.ClearRuntimeVars()
+
+	wc = wc.withLocals(w.locals)
 
 	outputs := make([]map[string]map[string]cty.Value, 0, len(w.processes))
 	var err error
 	for _, process := range w.processes {
+		err = wc.stopped()
+		if err != nil {
+			break
+		}
+
 		var output map[string]map[string]cty.Value
 		output, err = process.Run(wc)
 		outputs = append(outputs, output)
@@ -39,6 +52,28 @@ func (w *Workflow) Run(wc *WorkflowContext) ([]map[string]map[string]cty.Value,
 	return outputs, err
 }
 
+// Variables returns a clone of the slice of all variables declared by the workflow.
+//
+// This is used primarily for testing purposes.
+func (w *Workflow) Variables() []*Variable {
+	variables := slices.Clone(w.variables)
+	return variables
+}
+
+// Locals returns a clone of the locals defined at the top level of the workflow.
+//
+// This is used primarily for testing purposes.
+func (w *Workflow) Locals() map[string]*hcl.Attribute {
+	return maps.Clone(w.locals)
+}
+
+// Outputs returns a clone of the slice of all outputs declared by the workflow.
+//
+// This is used primarily for testing purposes.
+func (w *Workflow) Outputs() []*Output {
+	return slices.Clone(w.outputs)
+}
+
 // Processes returns a clone of the slice of all processes in the workflow.
 //
 // This is done to prevent external modification of the internal state.
@@ -50,7 +85,22 @@ func (w *Workflow) Processes() []*Process {
 
 // WorkflowBuilder is used to build a Workflow instance during parsing.
 type WorkflowBuilder struct {
+	variables []*Variable
+	locals    map[string]*hcl.Attribute
 	processes []*ProcessBuilder
+	outputs   []*Output
+}
+
+// AddVariable adds a Variable to the WorkflowBuilder.
+func (wb *WorkflowBuilder) AddVariable(v ...*Variable) *WorkflowBuilder {
+	wb.variables = append(wb.variables, v...)
+	return wb
+}
+
+// WithLocals sets the locals defined at the top level of the workflow.
+func (wb *WorkflowBuilder) WithLocals(locals map[string]*hcl.Attribute) *WorkflowBuilder {
+	wb.locals = locals
+	return wb
 }
 
 // AddProcess adds a ProcessBuilder to the WorkflowBuilder.
@@ -59,6 +109,12 @@ func (wb *WorkflowBuilder) AddProcess(pb ...*ProcessBuilder) *WorkflowBuilder {
 	return wb
 }
 
+// AddOutput adds an Output to the WorkflowBuilder.
+func (wb *WorkflowBuilder) AddOutput(o ...*Output) *WorkflowBuilder {
+	wb.outputs = append(wb.outputs, o...)
+	return wb
+}
+
 // Build constructs and returns the Workflow instance.
 func (wb *WorkflowBuilder) Build() (*Workflow, error) {
 	processes := make([]*Process, 0, len(wb.processes))
@@ -78,13 +134,17 @@ func (wb *WorkflowBuilder) Build() (*Workflow, error) {
 	}
 
 	return &Workflow{
+		variables: wb.variables,
+		locals:    wb.locals,
 		processes: processes,
+		outputs:   wb.outputs,
 	}, nil
 }
 
 // NewWorkflowBuilder creates a new instance of WorkflowBuilder.
 func NewWorkflowBuilder() *WorkflowBuilder {
 	return &WorkflowBuilder{
+		variables: []*Variable{},
 		processes: []*ProcessBuilder{},
 	}
 }
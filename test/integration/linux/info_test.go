@@ -4,6 +4,7 @@
 package linux
 
 import (
+	"context"
 	"testing"
 
 	"github.com/trippsoft/forge/test/integration"
@@ -375,7 +376,7 @@ func TestHostInfo(t *testing.T) {
 			}
 
 			hostInfo := host.Info()
-			result := hostInfo.Populate(host.Transport())
+			result := hostInfo.Populate(context.Background(), host.Transport())
 			if result.Error != nil {
 				t.Fatalf("failed to populate host info via SSH: %v", result.Error)
 			}
@@ -4,6 +4,7 @@
 package windows
 
 import (
+	"context"
 	"testing"
 
 	"github.com/trippsoft/forge/test/integration"
@@ -57,7 +58,7 @@ func TestHostInfo(t *testing.T) {
 			}
 
 			hostInfo := host.Info()
-			r := hostInfo.Populate(host.Transport())
+			r := hostInfo.Populate(context.Background(), host.Transport())
 			if r.Error != nil {
 				t.Fatalf("failed to populate host info via SSH: %v", r.Error)
 			}
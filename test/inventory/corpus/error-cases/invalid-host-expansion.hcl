@@ -0,0 +1,25 @@
+# Host blocks that expand into invalid or conflicting hosts
+host "web[3:1]" {}
+
+host "app[1:a]" {}
+
+host "node[1:2]" {}
+
+host "node2" {}
+
+host "db" {
+    name = "db1"
+}
+
+host "workers" {
+    for_each = 3
+}
+
+host "huge[0:4294967295]" {}
+
+host "grid[1:200]-[1:200]" {}
+
+host "shards" {
+    for_each = ["a", "b"]
+    name     = "shard-${each.key}[1:6000]"
+}
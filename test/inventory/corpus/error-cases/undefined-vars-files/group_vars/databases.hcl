@@ -0,0 +1 @@
+role = "database"
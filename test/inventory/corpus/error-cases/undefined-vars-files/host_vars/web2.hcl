@@ -0,0 +1 @@
+ip = "10.0.1.11"
@@ -0,0 +1,2 @@
+# Vars files for hosts and groups that are not defined
+host "web1" {}
@@ -0,0 +1,30 @@
+# Inventory for structured output and graphs
+group "webservers" {
+    vars {
+        role = "web"
+    }
+}
+
+group "api" {
+    parent = "webservers"
+}
+
+group "databases" {}
+
+host "web1" {
+    groups = ["webservers"]
+
+    escalate {
+        password = "hunter2"
+    }
+}
+
+host "api1" {
+    groups = ["api"]
+
+    vars {
+        port = 8443
+    }
+}
+
+host "bastion" {}
@@ -0,0 +1,70 @@
+# Hosts generated from ranges and for_each
+vars {
+    domain = "example.com"
+}
+
+transport "ssh" {
+    user = "admin"
+    use_known_hosts = false
+}
+
+group "webservers" {
+    vars {
+        role = "web"
+    }
+}
+
+group "caches" {
+    vars {
+        role = "cache"
+    }
+}
+
+host "web[01:03]" {
+    groups = ["webservers"]
+
+    transport "ssh" {
+        host = "10.0.1.1"
+    }
+}
+
+host "cache-[a:b]" {
+    groups = ["caches"]
+
+    transport "ssh" {
+        host = "10.0.3.1"
+    }
+}
+
+host "databases" {
+    for_each = {
+        primary = "10.0.2.10"
+        replica = "10.0.2.11"
+    }
+
+    name   = "db-${each.key}"
+    groups = [each.key]
+
+    vars {
+        ip       = each.value
+        hostname = "db-${each.key}.${var.domain}"
+    }
+
+    transport "ssh" {
+        host = each.value
+    }
+}
+
+group "primary" {}
+
+group "replica" {}
+
+host "workers" {
+    for_each = ["worker1", "worker2"]
+
+    vars {
+        queue = upper(each.value)
+    }
+
+    transport "local" {}
+}
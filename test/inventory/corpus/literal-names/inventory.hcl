@@ -0,0 +1,10 @@
+# Inventory with names that contain pattern separators and glob characters
+group "db:primary" {}
+
+host "web:8080" {}
+
+host "web:9090" {}
+
+host "db[1]" {
+    groups = ["db:primary"]
+}
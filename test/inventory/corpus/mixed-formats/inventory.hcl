@@ -0,0 +1,12 @@
+# Shared settings in the native syntax
+vars {
+    environment = "test"
+    domain      = "example.com"
+}
+
+transport "ssh" {
+    user = "admin"
+    port = 22
+    connection_timeout = "30s"
+    use_known_hosts = false
+}
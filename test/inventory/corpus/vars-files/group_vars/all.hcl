@@ -0,0 +1 @@
+environment = "staging"
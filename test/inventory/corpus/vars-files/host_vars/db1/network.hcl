@@ -0,0 +1,2 @@
+ip   = "10.0.2.10"
+port = 5432
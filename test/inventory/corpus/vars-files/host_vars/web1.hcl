@@ -0,0 +1,2 @@
+ip       = "10.0.1.10"
+hostname = "web1.${var.domain}"
@@ -0,0 +1,43 @@
+# Inventory whose vars are mostly kept in host_vars and group_vars files
+vars {
+    environment = "test"
+    domain      = "example.com"
+}
+
+transport "ssh" {
+    user = "admin"
+    use_known_hosts = false
+}
+
+group "webservers" {
+    vars {
+        role = "web"
+        port = 80
+    }
+}
+
+group "databases" {
+    vars {
+        role = "database"
+    }
+}
+
+host "web1" {
+    groups = ["webservers"]
+
+    vars {
+        ip = "10.0.1.99"
+    }
+
+    transport "ssh" {
+        host = "${var.ip}"
+    }
+}
+
+host "db1" {
+    groups = ["databases"]
+
+    transport "ssh" {
+        host = "${var.ip}"
+    }
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestHostExpansionParsing(t *testing.T) {
+	path := filepath.Join("corpus", "host-expansion")
+
+	files, err := inventory.DiscoverInventoryFiles(path)
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	inventory, diags := inventory.ParseInventoryFiles(files...)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse inventory: %s", diags.Error())
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("Expected no diagnostics, got: %v", diags)
+	}
+
+	if inventory == nil {
+		t.Fatal("Inventory should not be nil for valid configuration")
+	}
+
+	expectedHosts := []expectedHost{}
+	for _, name := range []string{"web01", "web02", "web03"} {
+		expectedHosts = append(expectedHosts, expectedHost{
+			name:          name,
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"domain": cty.StringVal("example.com"),
+				"role":   cty.StringVal("web"),
+			},
+		})
+	}
+
+	for _, name := range []string{"cache-a", "cache-b"} {
+		expectedHosts = append(expectedHosts, expectedHost{
+			name:          name,
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"domain": cty.StringVal("example.com"),
+				"role":   cty.StringVal("cache"),
+			},
+		})
+	}
+
+	expectedHosts = append(expectedHosts,
+		expectedHost{
+			name:          "db-primary",
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"domain":   cty.StringVal("example.com"),
+				"ip":       cty.StringVal("10.0.2.10"),
+				"hostname": cty.StringVal("db-primary.example.com"),
+			},
+		},
+		expectedHost{
+			name:          "db-replica",
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"domain":   cty.StringVal("example.com"),
+				"ip":       cty.StringVal("10.0.2.11"),
+				"hostname": cty.StringVal("db-replica.example.com"),
+			},
+		},
+		expectedHost{
+			name:          "worker1",
+			transportType: "local",
+			vars: map[string]cty.Value{
+				"domain": cty.StringVal("example.com"),
+				"queue":  cty.StringVal("WORKER1"),
+			},
+		},
+		expectedHost{
+			name:          "worker2",
+			transportType: "local",
+			vars: map[string]cty.Value{
+				"domain": cty.StringVal("example.com"),
+				"queue":  cty.StringVal("WORKER2"),
+			},
+		},
+	)
+
+	verifyHosts(t, inventory, expectedHosts)
+
+	expectedGroups := []expectedGroup{
+		{
+			name:  "webservers",
+			hosts: []string{"web01", "web02", "web03"},
+		},
+		{
+			name:  "caches",
+			hosts: []string{"cache-a", "cache-b"},
+		},
+		{
+			name:  "primary",
+			hosts: []string{"db-primary"},
+		},
+		{
+			name:  "replica",
+			hosts: []string{"db-replica"},
+		},
+	}
+
+	verifyGroups(t, inventory, expectedGroups)
+
+	expectedTargets := createExpectedTargets(t, expectedHosts, expectedGroups)
+
+	verifyTargets(t, inventory, expectedTargets)
+}
+
+func TestInvalidHostExpansionParsing(t *testing.T) {
+	path := filepath.Join("corpus", "error-cases", "invalid-host-expansion.hcl")
+
+	files, err := inventory.DiscoverInventoryFiles(path)
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	inventory, diags := inventory.ParseInventoryFiles(files...)
+	if !diags.HasErrors() {
+		t.Fatal("Expected parsing to fail")
+	}
+
+	expected := hcl.Diagnostics{
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid host range",
+			Detail:   `The host name "web[3:1]" is invalid: the range [3:1] starts after it ends.`,
+		},
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid host range",
+			Detail: `The host name "app[1:a]" is invalid: ` +
+				`the range [1:a] must be between two numbers or two letters of the same case.`,
+		},
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Duplicate host name",
+			Detail: "Host 'node2' is defined multiple times in the inventory file. " +
+				"Each host must have a unique name.",
+		},
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unexpected name attribute",
+			Detail:   `The "name" attribute is only supported in host blocks with a "for_each" attribute.`,
+		},
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid for_each value",
+			Detail:   `The "for_each" attribute of a host block must be a map or a list of strings, but got "number".`,
+		},
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid host range",
+			Detail: `The host name "huge[0:4294967295]" is invalid: ` +
+				`the range [0:4294967295] expands into more than 10000 hosts.`,
+		},
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid host range",
+			Detail:   `The host name "grid[1:200]-[1:200]" is invalid: it expands into more than 10000 hosts.`,
+		},
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid host range",
+			Detail:   "The host block expands into more than 10000 hosts.",
+		},
+	}
+
+	verifyDiagnostics(t, expected, diags)
+
+	if inventory != nil {
+		t.Fatal("Inventory should be nil for invalid configuration")
+	}
+}
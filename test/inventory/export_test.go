@@ -0,0 +1,183 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func parseExportInventory(t *testing.T) *inventory.Inventory {
+	t.Helper()
+
+	files, err := inventory.DiscoverInventoryFiles(filepath.Join("corpus", "export"))
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	i, diags := inventory.ParseInventoryFiles(files...)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse inventory: %s", diags.Error())
+	}
+
+	return i
+}
+
+func TestInventoryHostValue(t *testing.T) {
+	i := parseExportInventory(t)
+
+	tests := []struct {
+		host     string
+		expected cty.Value
+	}{
+		{
+			host: "web1",
+			expected: cty.ObjectVal(map[string]cty.Value{
+				"groups":    cty.ListVal([]cty.Value{cty.StringVal("webservers")}),
+				"transport": cty.StringVal("local"),
+				"escalate": cty.ObjectVal(map[string]cty.Value{
+					"password": cty.StringVal("<redacted>"),
+				}),
+				"vars": cty.ObjectVal(map[string]cty.Value{
+					"role": cty.StringVal("web"),
+				}),
+			}),
+		},
+		{
+			host: "api1",
+			expected: cty.ObjectVal(map[string]cty.Value{
+				"groups":    cty.ListVal([]cty.Value{cty.StringVal("api"), cty.StringVal("webservers")}),
+				"transport": cty.StringVal("local"),
+				"escalate":  cty.NullVal(cty.Object(map[string]cty.Type{"password": cty.String})),
+				"vars": cty.ObjectVal(map[string]cty.Value{
+					"role": cty.StringVal("web"),
+					"port": cty.NumberIntVal(8443),
+				}),
+			}),
+		},
+		{
+			host: "bastion",
+			expected: cty.ObjectVal(map[string]cty.Value{
+				"groups":    cty.ListValEmpty(cty.String),
+				"transport": cty.StringVal("local"),
+				"escalate":  cty.NullVal(cty.Object(map[string]cty.Type{"password": cty.String})),
+				"vars":      cty.EmptyObjectVal,
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			value, exists := i.HostValue(tt.host)
+			if !exists {
+				t.Fatalf("Expected host %q to exist", tt.host)
+			}
+
+			if !value.RawEquals(tt.expected) {
+				t.Errorf("Expected host %q to be %#v, got %#v", tt.host, tt.expected, value)
+			}
+		})
+	}
+
+	_, exists := i.HostValue("missing")
+	if exists {
+		t.Error("Expected host \"missing\" not to exist")
+	}
+}
+
+func TestInventoryValue(t *testing.T) {
+	i := parseExportInventory(t)
+
+	value := i.Value()
+	hosts := value.GetAttr("hosts")
+	for _, name := range []string{"web1", "api1", "bastion"} {
+		if !hosts.Type().HasAttribute(name) {
+			t.Errorf("Expected host %q in the inventory value", name)
+		}
+	}
+
+	expectedGroups := map[string]cty.Value{
+		"webservers": cty.ObjectVal(map[string]cty.Value{
+			"parent": cty.NullVal(cty.String),
+			"hosts":  cty.ListVal([]cty.Value{cty.StringVal("api1"), cty.StringVal("web1")}),
+		}),
+		"api": cty.ObjectVal(map[string]cty.Value{
+			"parent": cty.StringVal("webservers"),
+			"hosts":  cty.ListVal([]cty.Value{cty.StringVal("api1")}),
+		}),
+		"databases": cty.ObjectVal(map[string]cty.Value{
+			"parent": cty.NullVal(cty.String),
+			"hosts":  cty.ListValEmpty(cty.String),
+		}),
+	}
+
+	groups := value.GetAttr("groups")
+	if groups.LengthInt() != len(expectedGroups) {
+		t.Errorf("Expected %d groups in the inventory value, got %d", len(expectedGroups), groups.LengthInt())
+	}
+
+	for name, expected := range expectedGroups {
+		if !groups.Type().HasAttribute(name) {
+			t.Errorf("Expected group %q in the inventory value", name)
+			continue
+		}
+
+		if !groups.GetAttr(name).RawEquals(expected) {
+			t.Errorf("Expected group %q to be %#v, got %#v", name, expected, groups.GetAttr(name))
+		}
+	}
+}
+
+func TestInventoryTargetNames(t *testing.T) {
+	i := parseExportInventory(t)
+
+	expected := []string{"all", "api", "api1", "bastion", "web1", "webservers"}
+	actual := i.TargetNames()
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Expected targets %v, got %v", expected, actual)
+	}
+}
+
+func TestInventoryGraph(t *testing.T) {
+	i := parseExportInventory(t)
+
+	expectedTree := `all
+├── databases
+├── webservers
+│   ├── api
+│   │   └── api1
+│   └── web1
+└── bastion
+`
+
+	if tree := i.Tree(); tree != expectedTree {
+		t.Errorf("Expected tree:\n%s\ngot:\n%s", expectedTree, tree)
+	}
+
+	expectedDOT := `digraph inventory {
+    node [shape=box];
+    "all" [shape=ellipse];
+    "api" [shape=ellipse];
+    "databases" [shape=ellipse];
+    "webservers" [shape=ellipse];
+    "api1";
+    "bastion";
+    "web1";
+    "all" -> "databases";
+    "all" -> "webservers";
+    "all" -> "bastion";
+    "api" -> "api1";
+    "webservers" -> "api";
+    "webservers" -> "web1";
+}
+`
+
+	if dot := i.DOT(); dot != expectedDOT {
+		t.Errorf("Expected DOT graph:\n%s\ngot:\n%s", expectedDOT, dot)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestMixedFormatParsing(t *testing.T) {
+	path := filepath.Join("corpus", "mixed-formats")
+
+	files, err := inventory.DiscoverInventoryFiles(path)
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 inventory files, got %d", len(files))
+	}
+
+	inventory, diags := inventory.ParseInventoryFiles(files...)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse inventory: %s", diags.Error())
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("Expected no diagnostics, got: %v", diags)
+	}
+
+	if inventory == nil {
+		t.Fatal("Inventory should not be nil for valid configuration")
+	}
+
+	expectedHosts := []expectedHost{
+		{
+			name:          "web1",
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"environment": cty.StringVal("test"),
+				"domain":      cty.StringVal("example.com"),
+				"role":        cty.StringVal("web"),
+				"port":        cty.NumberIntVal(8080),
+				"ip":          cty.StringVal("10.0.1.10"),
+				"hostname":    cty.StringVal("web1.example.com"),
+			},
+		},
+		{
+			name:          "web2",
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"environment": cty.StringVal("test"),
+				"domain":      cty.StringVal("example.com"),
+				"role":        cty.StringVal("web"),
+				"port":        cty.NumberIntVal(8080),
+				"ip":          cty.StringVal("10.0.1.11"),
+				"hostname":    cty.StringVal("web2.example.com"),
+				"enabled":     cty.True,
+			},
+		},
+		{
+			name:          "db1",
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"environment": cty.StringVal("test"),
+				"domain":      cty.StringVal("example.com"),
+				"role":        cty.StringVal("database"),
+				"port":        cty.NumberIntVal(5432),
+				"ip":          cty.StringVal("10.0.2.10"),
+				"hostname":    cty.StringVal("db1.example.com"),
+				"enabled":     cty.False,
+			},
+		},
+	}
+
+	verifyHosts(t, inventory, expectedHosts)
+
+	expectedGroups := []expectedGroup{
+		{
+			name:  "webservers",
+			hosts: []string{"web1", "web2"},
+		},
+		{
+			name:  "databases",
+			hosts: []string{"db1"},
+		},
+	}
+
+	verifyGroups(t, inventory, expectedGroups)
+
+	expectedTargets := createExpectedTargets(t, expectedHosts, expectedGroups)
+
+	verifyTargets(t, inventory, expectedTargets)
+}
+
+func TestInvalidYAMLParsing(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		expected      hcl.Diagnostics
+		expectedStart hcl.Pos
+	}{
+		{
+			name: "invalid value type",
+			path: filepath.Join("corpus", "error-cases", "invalid-yaml.yaml"),
+			expected: hcl.Diagnostics{
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Incorrect YAML value type",
+					Detail:   "A mapping is required here, giving the body of the vars block.",
+				},
+			},
+			expectedStart: hcl.Pos{Line: 4, Column: 11},
+		},
+		{
+			name: "invalid syntax",
+			path: filepath.Join("corpus", "error-cases", "invalid-yaml-syntax.yml"),
+			expected: hcl.Diagnostics{
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid YAML syntax",
+					Detail:   "found unexpected end of stream",
+				},
+			},
+			expectedStart: hcl.Pos{Line: 4, Column: 1},
+		},
+		{
+			name: "invalid quoted template",
+			path: filepath.Join("corpus", "error-cases", "invalid-yaml-template.yaml"),
+			expected: hcl.Diagnostics{
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid attribute name",
+					Detail:   "An attribute name is required after a dot.",
+				},
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unresolvable variable",
+					Detail:   "The variable 'url' could not be resolved due to missing or circular dependencies.",
+				},
+			},
+			expectedStart: hcl.Pos{Line: 4, Column: 27},
+		},
+		{
+			name: "invalid block template",
+			path: filepath.Join("corpus", "error-cases", "invalid-yaml-block-template.yaml"),
+			expected: hcl.Diagnostics{
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid attribute name",
+					Detail:   "An attribute name is required after a dot.",
+				},
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unresolvable variable",
+					Detail:   "The variable 'motd' could not be resolved due to missing or circular dependencies.",
+				},
+			},
+			expectedStart: hcl.Pos{Line: 5, Column: 26},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := inventory.DiscoverInventoryFiles(tt.path)
+			if err != nil {
+				t.Fatalf("Failed to discover inventory files: %v", err)
+			}
+
+			_, diags := inventory.ParseInventoryFiles(files...)
+			if !diags.HasErrors() {
+				t.Fatal("Expected parsing to fail")
+			}
+
+			verifyDiagnostics(t, tt.expected, diags)
+
+			subject := diags[0].Subject
+			if subject == nil {
+				t.Fatal("Expected diagnostic to have a subject")
+			}
+
+			if subject.Filename != tt.path ||
+				subject.Start.Line != tt.expectedStart.Line ||
+				subject.Start.Column != tt.expectedStart.Column {
+
+				t.Errorf(
+					"Expected diagnostic to start at %s:%d,%d, got %s",
+					tt.path,
+					tt.expectedStart.Line,
+					tt.expectedStart.Column,
+					subject.String(),
+				)
+			}
+		})
+	}
+}
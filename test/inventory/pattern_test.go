@@ -0,0 +1,155 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/trippsoft/forge/pkg/inventory"
+)
+
+func parseSimpleInventory(t *testing.T) *inventory.Inventory {
+	t.Helper()
+
+	files, err := inventory.DiscoverInventoryFiles(filepath.Join("corpus", "simple"))
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	i, diags := inventory.ParseInventoryFiles(files...)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse inventory: %s", diags.Error())
+	}
+
+	return i
+}
+
+func TestResolvePattern(t *testing.T) {
+	i := parseSimpleInventory(t)
+
+	retryFile := filepath.Join("corpus", "pattern-files", "retry.txt")
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "all", expected: []string{"db1", "web1", "web2"}},
+		{pattern: "web1", expected: []string{"web1"}},
+		{pattern: "webservers", expected: []string{"web1", "web2"}},
+		{pattern: "web1,db1", expected: []string{"db1", "web1"}},
+		{pattern: "webservers:databases", expected: []string{"db1", "web1", "web2"}},
+		{pattern: "web*", expected: []string{"web1", "web2"}},
+		{pattern: "~^(web|db)1$", expected: []string{"db1", "web1"}},
+		{pattern: "~web[0-9]{1,2}", expected: []string{"web1", "web2"}},
+		{pattern: "all:!db*", expected: []string{"web1", "web2"}},
+		{pattern: "!webservers", expected: []string{"db1"}},
+		{pattern: "all:&webservers:!web2", expected: []string{"web1"}},
+		{pattern: "&databases", expected: []string{"db1"}},
+		{pattern: "@" + retryFile, expected: []string{"db1", "web2"}},
+		{pattern: "webservers:&@" + retryFile, expected: []string{"web2"}},
+		{pattern: "app*", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			hosts, err := i.ResolvePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("Failed to resolve pattern: %v", err)
+			}
+
+			names := make([]string, 0, len(hosts))
+			for _, host := range hosts {
+				names = append(names, host.Name())
+			}
+
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("Expected hosts %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestResolveLiteralPattern(t *testing.T) {
+	files, err := inventory.DiscoverInventoryFiles(filepath.Join("corpus", "literal-names"))
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	i, diags := inventory.ParseInventoryFiles(files...)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse inventory: %s", diags.Error())
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "web:8080", expected: []string{"web:8080"}},
+		{pattern: "db:primary", expected: []string{"db[1]"}},
+		{pattern: "db[1]", expected: []string{"db[1]"}},
+		{pattern: "db[1],web*", expected: []string{"db[1]", "web:8080", "web:9090"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			hosts, err := i.ResolvePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("Failed to resolve pattern: %v", err)
+			}
+
+			names := make([]string, 0, len(hosts))
+			for _, host := range hosts {
+				names = append(names, host.Name())
+			}
+
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("Expected hosts %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestResolvePatternErrors(t *testing.T) {
+	i := parseSimpleInventory(t)
+
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: "", expected: "the host pattern is empty"},
+		{pattern: "webservers:!", expected: "the host pattern contains an empty term"},
+		{pattern: "appservers", expected: "the target \"appservers\" does not exist in the inventory"},
+		{pattern: "web[1", expected: "the host pattern \"web[1\" has an unclosed bracket"},
+		{pattern: "~web(", expected: "the host pattern \"~web(\" has an unclosed bracket"},
+		{pattern: "~web)", expected: "the host pattern \"~web)\" has an unmatched ')'"},
+		{
+			pattern:  "@" + filepath.Join("corpus", "pattern-files", "missing.txt"),
+			expected: "the host pattern file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := i.ResolvePattern(tt.pattern)
+			if err == nil {
+				t.Fatal("Expected error, got none")
+			}
+
+			if !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Errorf("Expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+
+	_, err := i.ResolvePattern("appservers")
+
+	var notFound *inventory.TargetNotFoundError
+	if !errors.As(err, &notFound) || notFound.Target != "appservers" {
+		t.Errorf("Expected TargetNotFoundError for %q, got %v", "appservers", err)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/plugin"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// pluginModeEnvVar selects what the test binary does when it is run as an inventory plugin.
+const pluginModeEnvVar = "FORGE_TEST_INVENTORY_PLUGIN"
+
+// TestInventoryPluginHelper is not a test, but the inventory plugin run by the plugin tests.
+func TestInventoryPluginHelper(t *testing.T) {
+	mode := os.Getenv(pluginModeEnvVar)
+	if mode == "" {
+		return
+	}
+
+	output := &inventory.PluginInventory{
+		Vars: map[string]*structpb.Value{
+			"source": structpb.NewStringValue("cmdb"),
+		},
+		Groups: map[string]*inventory.PluginGroup{
+			"databases": {
+				Vars: map[string]*structpb.Value{
+					"role": structpb.NewStringValue("database"),
+				},
+			},
+			"api": {
+				Parent: "webservers",
+			},
+		},
+		Hosts: map[string]*inventory.PluginHost{
+			"db1": {
+				Groups: []string{"databases"},
+				Vars: map[string]*structpb.Value{
+					"port": structpb.NewNumberValue(5432),
+				},
+				Transport: &inventory.PluginTransport{
+					Type: "ssh",
+					Config: map[string]*structpb.Value{
+						"host":            structpb.NewStringValue("10.0.2.10"),
+						"port":            structpb.NewNumberValue(2222),
+						"user":            structpb.NewStringValue("admin"),
+						"use_known_hosts": structpb.NewBoolValue(false),
+					},
+				},
+			},
+			"api1": {
+				Groups: []string{"api"},
+			},
+		},
+	}
+
+	switch mode {
+	case "json":
+		content, err := protojson.Marshal(output)
+		if err != nil {
+			os.Exit(2)
+		}
+
+		os.Stdout.Write(content)
+
+	case "duplicate":
+		output.Hosts["web1"] = &inventory.PluginHost{}
+		fallthrough
+
+	case "protobuf":
+		err := plugin.Write(os.Stdout, output)
+		if err != nil {
+			os.Exit(2)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, "cmdb is unreachable")
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+// parsePluginInventory writes an inventory file that combines static hosts with the inventory plugin and parses it.
+func parsePluginInventory(t *testing.T, mode string, format string) (*inventory.Inventory, hcl.Diagnostics) {
+	t.Helper()
+
+	t.Setenv(pluginModeEnvVar, mode)
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to locate the test binary: %v", err)
+	}
+
+	content := fmt.Sprintf(`
+vars {
+  environment = "test"
+}
+
+group "webservers" {
+  vars {
+    role = "web"
+  }
+}
+
+host "web1" {
+  groups = ["webservers"]
+}
+
+plugin "cmdb" {
+  command = %q
+  args = ["-test.run=^TestInventoryPluginHelper$"]
+  format = %q
+}
+`, executable, format)
+
+	path := filepath.Join(t.TempDir(), "inventory.hcl")
+	err = os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write inventory file: %v", err)
+	}
+
+	files, err := inventory.DiscoverInventoryFiles(path)
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	return inventory.ParseInventoryFiles(files...)
+}
+
+func TestInventoryPluginParsing(t *testing.T) {
+	for _, format := range []string{inventory.PluginFormatProtobuf, inventory.PluginFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			i, diags := parsePluginInventory(t, format, format)
+			if diags.HasErrors() {
+				t.Fatalf("Failed to parse inventory: %s", diags.Error())
+			}
+
+			expectedHosts := []expectedHost{
+				{
+					name:          "web1",
+					transportType: "local",
+					vars: map[string]cty.Value{
+						"environment": cty.StringVal("test"),
+						"source":      cty.StringVal("cmdb"),
+						"role":        cty.StringVal("web"),
+					},
+				},
+				{
+					name:          "api1",
+					transportType: "local",
+					vars: map[string]cty.Value{
+						"environment": cty.StringVal("test"),
+						"source":      cty.StringVal("cmdb"),
+						"role":        cty.StringVal("web"),
+					},
+				},
+				{
+					name:          "db1",
+					transportType: "ssh",
+					vars: map[string]cty.Value{
+						"environment": cty.StringVal("test"),
+						"source":      cty.StringVal("cmdb"),
+						"role":        cty.StringVal("database"),
+						"port":        cty.NumberIntVal(5432),
+					},
+				},
+			}
+
+			verifyHosts(t, i, expectedHosts)
+
+			expectedGroups := map[string][]string{
+				"webservers": {"api1", "web1"},
+				"api":        {"api1"},
+				"databases":  {"db1"},
+			}
+
+			for name, expected := range expectedGroups {
+				hosts, exists := i.Group(name)
+				if !exists {
+					t.Errorf("Expected group %q not found in inventory", name)
+					continue
+				}
+
+				names := []string{}
+				for _, host := range hosts {
+					names = append(names, host.Name())
+				}
+
+				slices.Sort(names)
+				if !slices.Equal(names, expected) {
+					t.Errorf("Expected group %q to contain %v, got %v", name, expected, names)
+				}
+			}
+		})
+	}
+}
+
+func TestInventoryPluginErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		expected hcl.Diagnostics
+	}{
+		{
+			name: "duplicate host",
+			mode: "duplicate",
+			expected: hcl.Diagnostics{
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate host name",
+					Detail: "Host 'web1' is defined multiple times in the inventory. " +
+						"Each host must have a unique name.",
+				},
+			},
+		},
+		{
+			name: "failure",
+			mode: "fail",
+			expected: hcl.Diagnostics{
+				&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Inventory plugin failed",
+					Detail:   "The plugin 'cmdb' failed: exit status 1\ncmdb is unreachable",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, diags := parsePluginInventory(t, tt.mode, inventory.PluginFormatProtobuf)
+			if !diags.HasErrors() {
+				t.Fatal("Expected parsing to fail")
+			}
+
+			verifyDiagnostics(t, tt.expected, diags)
+
+			if i != nil {
+				t.Fatal("Inventory should be nil for invalid configuration")
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestVarsFilesParsing(t *testing.T) {
+	path := filepath.Join("corpus", "vars-files")
+
+	files, err := inventory.DiscoverInventoryFiles(path)
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	expectedTargets := map[string][2]string{
+		filepath.Join(path, "inventory.hcl"):                    {"", ""},
+		filepath.Join(path, "group_vars", "all.hcl"):            {"", "all"},
+		filepath.Join(path, "group_vars", "webservers.json"):    {"", "webservers"},
+		filepath.Join(path, "host_vars", "web1.hcl"):            {"web1", ""},
+		filepath.Join(path, "host_vars", "db1", "network.hcl"):  {"db1", ""},
+		filepath.Join(path, "host_vars", "db1", "storage.yaml"): {"db1", ""},
+	}
+
+	if len(files) != len(expectedTargets) {
+		t.Fatalf("Expected %d inventory files, got %d", len(expectedTargets), len(files))
+	}
+
+	for _, file := range files {
+		expected, exists := expectedTargets[file.Path]
+		if !exists {
+			t.Errorf("Unexpected inventory file %q", file.Path)
+			continue
+		}
+
+		if file.HostVars != expected[0] || file.GroupVars != expected[1] {
+			t.Errorf(
+				"Expected inventory file %q to contain vars for host %q and group %q, got %q and %q",
+				file.Path,
+				expected[0],
+				expected[1],
+				file.HostVars,
+				file.GroupVars,
+			)
+		}
+	}
+
+	inventory, diags := inventory.ParseInventoryFiles(files...)
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse inventory: %s", diags.Error())
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("Expected no diagnostics, got: %v", diags)
+	}
+
+	if inventory == nil {
+		t.Fatal("Inventory should not be nil for valid configuration")
+	}
+
+	expectedHosts := []expectedHost{
+		{
+			name:          "web1",
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"environment": cty.StringVal("staging"),
+				"domain":      cty.StringVal("example.com"),
+				"role":        cty.StringVal("web"),
+				"port":        cty.NumberIntVal(8080),
+				"ip":          cty.StringVal("10.0.1.10"),
+				"hostname":    cty.StringVal("web1.example.com"),
+			},
+		},
+		{
+			name:          "db1",
+			transportType: "ssh",
+			vars: map[string]cty.Value{
+				"environment": cty.StringVal("staging"),
+				"domain":      cty.StringVal("example.com"),
+				"role":        cty.StringVal("database"),
+				"port":        cty.NumberIntVal(5433),
+				"ip":          cty.StringVal("10.0.2.10"),
+				"data_dir":    cty.StringVal("/var/lib/postgresql"),
+			},
+		},
+	}
+
+	verifyHosts(t, inventory, expectedHosts)
+}
+
+func TestUndefinedVarsFilesParsing(t *testing.T) {
+	path := filepath.Join("corpus", "error-cases", "undefined-vars-files")
+
+	files, err := inventory.DiscoverInventoryFiles(path)
+	if err != nil {
+		t.Fatalf("Failed to discover inventory files: %v", err)
+	}
+
+	inventory, diags := inventory.ParseInventoryFiles(files...)
+	if !diags.HasErrors() {
+		t.Fatal("Expected parsing to fail")
+	}
+
+	expected := hcl.Diagnostics{
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Vars for undefined host",
+			Detail: fmt.Sprintf(
+				"The vars file %q contains vars for host 'web2', which is not defined in the inventory.",
+				filepath.Join(path, "host_vars", "web2.hcl"),
+			),
+		},
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Vars for undefined group",
+			Detail: fmt.Sprintf(
+				"The vars file %q contains vars for group 'databases', which is not defined in the inventory.",
+				filepath.Join(path, "group_vars", "databases.hcl"),
+			),
+		},
+	}
+
+	verifyDiagnostics(t, expected, diags)
+
+	if inventory != nil {
+		t.Fatal("Inventory should be nil for invalid configuration")
+	}
+}
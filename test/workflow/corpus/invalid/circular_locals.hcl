@@ -0,0 +1,18 @@
+# Locals that reference each other circularly
+locals {
+  base_dir = "/opt/${local.app_dir}"
+}
+
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  locals {
+    app_dir = "${local.base_dir}/myapp"
+  }
+
+  step "shell" {
+    name = "Run Shell"
+    module = "shell"
+  }
+}
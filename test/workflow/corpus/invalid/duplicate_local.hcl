@@ -0,0 +1,18 @@
+# Process local that redefines a workflow local
+locals {
+  app_name = "myapp"
+}
+
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  locals {
+    app_name = "otherapp"
+  }
+
+  step "shell" {
+    name = "Run Shell"
+    module = "shell"
+  }
+}
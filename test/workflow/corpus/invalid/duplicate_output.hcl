@@ -0,0 +1,18 @@
+# Workflow that declares the same output twice
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  step "shell" {
+    name = "Run Shell"
+    module = "shell"
+  }
+}
+
+output "version" {
+  value = steps.shell.host1.output
+}
+
+output "version" {
+  value = steps.shell.host1.output
+}
@@ -0,0 +1,16 @@
+# Step that notifies a handler that is not defined in the process
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  step "config" {
+    name = "Update Config"
+    module = "shell"
+    notify = ["restart", "reload"]
+  }
+
+  handler "restart" {
+    name = "Restart Service"
+    module = "shell"
+  }
+}
@@ -0,0 +1,17 @@
+# Steps with a poll but no async, and an async module that cannot run detached
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  step "poll" {
+    name = "Poll Without Async"
+    module = "shell"
+    poll = "10s"
+  }
+
+  step "async" {
+    name = "Async Without Support"
+    module = "shell"
+    async = "1h"
+  }
+}
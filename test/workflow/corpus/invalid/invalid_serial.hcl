@@ -0,0 +1,11 @@
+# Process with an invalid batch size
+process {
+  name = "Test Process"
+  targets = "host1"
+  serial = [0, "150%"]
+
+  step "restart" {
+    name = "Restart Service"
+    module = "shell"
+  }
+}
@@ -0,0 +1,11 @@
+# Step with tags that are not a list of strings
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  step "shell" {
+    name = "Run Shell"
+    module = "shell"
+    tags = { config = true }
+  }
+}
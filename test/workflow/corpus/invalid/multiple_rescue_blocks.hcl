@@ -0,0 +1,28 @@
+# Block with more than one rescue block
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  block "deploy" {
+    name = "Deploy Application"
+
+    step "install" {
+      name = "Install Release"
+      module = "shell"
+    }
+
+    rescue {
+      step "rollback" {
+        name = "Roll Back Release"
+        module = "shell"
+      }
+    }
+
+    rescue {
+      step "cleanup" {
+        name = "Clean Up"
+        module = "shell"
+      }
+    }
+  }
+}
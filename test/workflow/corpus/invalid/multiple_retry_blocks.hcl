@@ -0,0 +1,18 @@
+# Step with more than one retry block
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  step "wait" {
+    name = "Wait for Service"
+    module = "shell"
+
+    retry {
+      attempts = 3
+    }
+
+    retry {
+      attempts = 5
+    }
+  }
+}
@@ -0,0 +1,10 @@
+# Procedure that includes itself through another procedure
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  procedure "outer" {
+    name = "Outer Procedure"
+    source = "procedures/circular_a.hcl"
+  }
+}
@@ -0,0 +1,14 @@
+# Procedure block that does not provide a required input
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  procedure "install" {
+    name = "Install Package"
+    source = "procedures/install_package.hcl"
+
+    input {
+      version = "1.0.0"
+    }
+  }
+}
@@ -0,0 +1,5 @@
+# Procedure that calls circular_b.hcl
+procedure "inner" {
+  name = "Inner Procedure"
+  source = "circular_b.hcl"
+}
@@ -0,0 +1,5 @@
+# Procedure that calls circular_a.hcl
+procedure "inner" {
+  name = "Inner Procedure"
+  source = "circular_a.hcl"
+}
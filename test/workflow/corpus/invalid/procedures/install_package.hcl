@@ -0,0 +1,9 @@
+# Procedure that requires a package input
+input "package" {
+  type = string
+}
+
+step "install" {
+  name = "Install Package"
+  module = "shell"
+}
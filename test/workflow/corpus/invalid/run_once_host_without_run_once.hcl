@@ -0,0 +1,11 @@
+# Step that selects a run_once host without enabling run_once
+process {
+  name = "Test Process"
+  targets = "host1"
+
+  step "token" {
+    name = "Fetch Release Token"
+    module = "shell"
+    run_once_host = "host1"
+  }
+}
@@ -0,0 +1,31 @@
+# Process with steps whose modules run detached on the host
+process {
+  name = "Async Run"
+  targets = "host1"
+  discover_info = false
+
+  step "wait" {
+    name = "Wait for Job"
+    module = "job"
+    async = "1m"
+    poll = "1ms"
+  }
+
+  step "fire" {
+    name = "Fire and Forget"
+    module = "job"
+    async = "1m"
+    poll = 0
+  }
+
+  step "expire" {
+    name = "Expire Job"
+    module = "stuck"
+    async = "20ms"
+    poll = "5ms"
+
+    output {
+      continue_on_fail = true
+    }
+  }
+}
@@ -0,0 +1,34 @@
+# Process with a block that rescues failed hosts and always re-enables them
+process {
+  name = "Block Run"
+  targets = ["host1", "host2", "host3"]
+  discover_info = false
+
+  block "deploy_app" {
+    name = "Deploy Application"
+
+    step "deploy" {
+      name = "Deploy"
+      module = "deploy"
+    }
+
+    rescue {
+      step "rollback" {
+        name = "Roll Back"
+        module = "rollback"
+      }
+    }
+
+    always {
+      step "enable" {
+        name = "Enable in Load Balancer"
+        module = "enable"
+      }
+    }
+  }
+
+  step "verify" {
+    name = "Verify"
+    module = "verify"
+  }
+}
@@ -0,0 +1,15 @@
+# Process that discovers host info and delegates a step to a host that is not a target
+process {
+  name = "Delegate Info Run"
+  targets = "web1"
+
+  step "report" {
+    name = "Report OS"
+    module = "info"
+    delegate_to = "lb"
+
+    input {
+      os = info.os_id
+    }
+  }
+}
@@ -0,0 +1,45 @@
+# Process with steps delegated to another host on behalf of each target
+process {
+  name = "Delegate Run"
+  targets = ["web1", "web2"]
+  discover_info = false
+
+  step "deregister" {
+    name = "Remove from Load Balancer"
+    module = "deregister"
+    delegate_to = "lb"
+
+    input {
+      address = var.ip
+    }
+  }
+
+  step "dns" {
+    name = "Add DNS Record"
+    module = "deregister"
+    delegate_to = "localhost"
+
+    input {
+      address = var.ip
+    }
+  }
+
+  step "report" {
+    name = "Report"
+    module = "record"
+
+    input {
+      address = steps.deregister.output.address
+    }
+  }
+
+  step "missing" {
+    name = "Delegate to Missing Host"
+    module = "deregister"
+    delegate_to = "db9"
+
+    input {
+      address = var.ip
+    }
+  }
+}
@@ -0,0 +1,16 @@
+# Process with a step whose module does not complete within its exec_timeout
+process {
+  name = "Exec Timeout Run"
+  targets = "host1"
+  discover_info = false
+
+  step "hang" {
+    name = "Hang"
+    module = "hang"
+    exec_timeout = "10ms"
+
+    output {
+      continue_on_fail = true
+    }
+  }
+}
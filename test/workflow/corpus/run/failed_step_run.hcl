@@ -0,0 +1,16 @@
+# Process with a failing step that has no output block
+process {
+  name = "Failed Step Run"
+  targets = ["host1", "host2"]
+  discover_info = false
+
+  step "deploy" {
+    name = "Deploy"
+    module = "deploy"
+  }
+
+  step "verify" {
+    name = "Verify"
+    module = "verify"
+  }
+}
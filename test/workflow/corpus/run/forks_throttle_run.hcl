@@ -0,0 +1,21 @@
+# Process with a looped step limited by forks and a throttled step
+process {
+  name = "Forks and Throttle Run"
+  targets = ["host1", "host2", "host3", "host4", "host5", "host6"]
+  discover_info = false
+
+  step "forked" {
+    name = "Forked Step"
+    module = "forked"
+
+    loop {
+      items = ["a", "b"]
+    }
+  }
+
+  step "throttled" {
+    name = "Throttled Step"
+    module = "throttled"
+    throttle = 2
+  }
+}
@@ -0,0 +1,56 @@
+# Process with handlers that only run on hosts where a notifying step changed
+process {
+  name = "Handler Run"
+  targets = ["host1", "host2", "host3"]
+  discover_info = false
+
+  step "config" {
+    name = "Update Config"
+    module = "changed"
+    targets = "host1"
+    notify = "restart"
+  }
+
+  step "config_again" {
+    name = "Update Config Again"
+    module = "changed"
+    targets = "host1"
+    notify = ["restart"]
+  }
+
+  step "check" {
+    name = "Check Config"
+    module = "unchanged"
+    targets = "host2"
+    notify = "restart"
+  }
+
+  flush_handlers {}
+
+  step "verify" {
+    name = "Verify Restart"
+    module = "record"
+    targets = "host1"
+
+    input {
+      restarted = steps.restart.output.restarted
+    }
+  }
+
+  step "certs" {
+    name = "Update Certificates"
+    module = "changed"
+    targets = "host3"
+    notify = "reload"
+  }
+
+  handler "restart" {
+    name = "Restart Service"
+    module = "restart"
+  }
+
+  handler "reload" {
+    name = "Reload Service"
+    module = "reload"
+  }
+}
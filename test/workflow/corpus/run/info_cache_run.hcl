@@ -0,0 +1,14 @@
+# Process that discovers host info, which is loaded from the host info cache
+process {
+  name = "Info Cache Run"
+  targets = "host1"
+
+  step "report" {
+    name = "Report OS"
+    module = "record"
+
+    input {
+      os = info.os_id
+    }
+  }
+}
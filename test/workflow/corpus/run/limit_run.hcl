@@ -0,0 +1,17 @@
+# Process with target patterns, run with a limit
+process {
+  name = "Limit Run"
+  targets = "all:!host3"
+  discover_info = false
+
+  step "process_targets" {
+    name = "Process Targets"
+    module = "first"
+  }
+
+  step "step_targets" {
+    name = "Step Targets"
+    module = "second"
+    targets = ["host*"]
+  }
+}
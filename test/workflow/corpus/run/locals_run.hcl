@@ -0,0 +1,31 @@
+# Process with steps that read workflow and process locals
+locals {
+  app_name = "myapp"
+  base_dir = "/opt/${local.app_name}"
+}
+
+process {
+  name = "Locals Run"
+  targets = "all"
+  discover_info = false
+
+  locals {
+    release_dir = "${local.base_dir}/releases/${local.version}"
+    config = merge(local.defaults, { port = var.port })
+  }
+
+  locals {
+    version = "1.0.0"
+    defaults = { log_level = "info" }
+  }
+
+  step "fetch" {
+    name = "Fetch Release"
+    module = "record"
+
+    input {
+      path = local.release_dir
+      config = local.config
+    }
+  }
+}
@@ -0,0 +1,13 @@
+# Process that stops after too many hosts in a batch fail
+process {
+  name = "Max Fail Percentage Run"
+  targets = ["host1", "host2", "host3", "host4"]
+  discover_info = false
+  serial = 2
+  max_fail_percentage = 40
+
+  step "deploy" {
+    name = "Deploy"
+    module = "deploy"
+  }
+}
@@ -0,0 +1,17 @@
+# Process with a step whose module returns no result
+process {
+  name = "Nil Result Run"
+  targets = "host1"
+  discover_info = false
+
+  step "empty" {
+    name = "Return Nothing"
+    module = "empty"
+    exec_timeout = "1m"
+  }
+
+  step "verify" {
+    name = "Verify"
+    module = "verify"
+  }
+}
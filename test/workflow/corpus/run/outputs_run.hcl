@@ -0,0 +1,42 @@
+# Workflow whose outputs are evaluated against the step outputs of every process
+variable "environment" {
+  type = string
+  default = "staging"
+}
+
+process {
+  name = "Deploy"
+  targets = ["host1", "host2"]
+  discover_info = false
+
+  step "deploy" {
+    name = "Deploy"
+    module = "deploy"
+  }
+}
+
+process {
+  name = "Verify"
+  targets = ["host1", "host2"]
+  discover_info = false
+
+  step "verify" {
+    name = "Verify"
+    module = "deploy"
+    condition = var.primary
+  }
+}
+
+output "version" {
+  description = "Version deployed to the first host"
+  value = steps.deploy.host1.output.version
+}
+
+output "endpoints" {
+  value = { for name, step in steps.deploy : name => "https://${name}/${variable.environment}" }
+}
+
+output "token" {
+  value = "token-${steps.deploy.host2.output.version}"
+  sensitive = true
+}
@@ -0,0 +1,26 @@
+# Process that runs a procedure and uses its outputs
+process {
+  name = "Procedure Run"
+  targets = ["host1", "host2"]
+  discover_info = false
+
+  procedure "install" {
+    name = "Install Package"
+    source = "../valid/procedures/install_package.hcl"
+    targets = "host1"
+
+    input {
+      package = "nginx"
+    }
+  }
+
+  step "report" {
+    name = "Report Version"
+    module = "record"
+
+    input {
+      version = try(steps.install.output.version, "none")
+      inner_step = try(steps.verify.changed, "hidden")
+    }
+  }
+}
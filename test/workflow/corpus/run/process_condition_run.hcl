@@ -0,0 +1,34 @@
+# Processes that only run on the hosts that meet their condition
+process {
+  name = "Web Servers"
+  targets = ["host1", "host2", "host3"]
+  condition = var.role == "web"
+
+  step "report" {
+    name = "Report OS"
+    module = "record"
+
+    input {
+      os = info.os_id
+    }
+  }
+}
+
+process {
+  name = "Debian Servers"
+  targets = ["host1", "host2"]
+  condition = local.debian
+
+  locals {
+    debian = info.os_id == "debian"
+  }
+
+  step "report" {
+    name = "Report Debian"
+    module = "debian"
+
+    input {
+      os = info.os_id
+    }
+  }
+}
@@ -0,0 +1,41 @@
+# Process with steps that are retried until they succeed or a condition is met
+process {
+  name = "Retry Run"
+  targets = "host1"
+  discover_info = false
+
+  step "wait" {
+    name = "Wait for Service"
+    module = "flaky"
+
+    retry {
+      attempts = 5
+      delay = "1ms"
+      backoff = 2
+    }
+  }
+
+  step "poll" {
+    name = "Poll Status"
+    module = "counter"
+
+    retry {
+      attempts = 5
+      delay = "1ms"
+      until = result.output.runs >= 3
+    }
+  }
+
+  step "exhaust" {
+    name = "Exhaust Attempts"
+    module = "broken"
+
+    retry {
+      attempts = 2
+    }
+
+    output {
+      continue_on_fail = true
+    }
+  }
+}
@@ -0,0 +1,22 @@
+# Process with a step that fails the host when its until condition is never met
+process {
+  name = "Retry Until Run"
+  targets = "host1"
+  discover_info = false
+
+  step "poll" {
+    name = "Poll Status"
+    module = "counter"
+
+    retry {
+      attempts = 2
+      delay = "1ms"
+      until = result.output.runs >= 3
+    }
+  }
+
+  step "deploy" {
+    name = "Deploy"
+    module = "deploy"
+  }
+}
@@ -0,0 +1,30 @@
+# Process with run_once steps whose outputs are shared with every target
+process {
+  name = "Run Once Run"
+  targets = ["host1", "host2", "host3"]
+  discover_info = false
+
+  step "migrate" {
+    name = "Migrate Database"
+    module = "migrate"
+    run_once = true
+  }
+
+  step "token" {
+    name = "Fetch Release Token"
+    module = "token"
+    run_once = true
+    run_once_host = "host3"
+  }
+
+  step "report" {
+    name = "Report"
+    module = "record"
+
+    input {
+      version = steps.migrate.output.version
+      token = steps.token.output.token
+      shared = hostvars.host1.steps.token.output.token
+    }
+  }
+}
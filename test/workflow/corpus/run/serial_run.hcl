@@ -0,0 +1,17 @@
+# Process that runs its steps in rolling batches
+process {
+  name = "Serial Run"
+  targets = ["host1", "host2", "host3", "host4"]
+  discover_info = false
+  serial = [1, "50%"]
+
+  step "stop" {
+    name = "Stop Service"
+    module = "stop"
+  }
+
+  step "start" {
+    name = "Start Service"
+    module = "start"
+  }
+}
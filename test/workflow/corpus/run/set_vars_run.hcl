@@ -0,0 +1,35 @@
+# Processes that set runtime variables in one process and use them in another
+process {
+  name = "Set Vars"
+  targets = ["host1", "host2"]
+  discover_info = false
+
+  step "facts" {
+    name = "Set Release Facts"
+    module = "set_vars"
+
+    input {
+      vars = {
+        release = "release-${var.port}"
+        role = "web"
+      }
+    }
+  }
+}
+
+process {
+  name = "Use Vars"
+  targets = ["host1", "host2"]
+  discover_info = false
+
+  step "report" {
+    name = "Report"
+    module = "record"
+
+    input {
+      release = var.release
+      role = var.role
+      peer = hostvars.host2.release
+    }
+  }
+}
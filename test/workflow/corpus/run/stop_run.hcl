@@ -0,0 +1,27 @@
+# Processes with a step that stops the workflow before the remaining steps run
+process {
+  name = "Stop Run"
+  targets = "host1"
+  discover_info = false
+
+  step "stop" {
+    name = "Stop Workflow"
+    module = "stop"
+  }
+
+  step "skipped" {
+    name = "Skipped Step"
+    module = "second"
+  }
+}
+
+process {
+  name = "Later Process"
+  targets = "host1"
+  discover_info = false
+
+  step "later" {
+    name = "Later Step"
+    module = "second"
+  }
+}
@@ -0,0 +1,46 @@
+# Processes with tagged steps, blocks and special tags
+process {
+  name = "Tagged Process"
+  targets = "host1"
+  discover_info = false
+  tags = ["deploy"]
+
+  step "config" {
+    name = "Update Config"
+    module = "config"
+    tags = ["config"]
+  }
+
+  block "service" {
+    name = "Manage Service"
+    tags = ["service"]
+
+    step "restart" {
+      name = "Restart Service"
+      module = "restart"
+    }
+
+    step "debug" {
+      name = "Debug Service"
+      module = "debug"
+      tags = ["debug", "never"]
+    }
+  }
+
+  step "cleanup" {
+    name = "Clean Up"
+    module = "cleanup"
+    tags = ["always"]
+  }
+}
+
+process {
+  name = "Untagged Process"
+  targets = "host1"
+  discover_info = false
+
+  step "check" {
+    name = "Check Service"
+    module = "check"
+  }
+}
@@ -0,0 +1,44 @@
+# Process with steps that read workflow variables
+variable "version" {
+  type = string
+  description = "The release version to deploy"
+
+  validation {
+    condition = startswith(variable.version, "v")
+    error_message = "The version must start with \"v\"."
+  }
+}
+
+variable "replicas" {
+  type = number
+  default = 1
+}
+
+variable "features" {
+  type = list(string)
+  default = []
+}
+
+variable "api_token" {
+  type = string
+  default = "default-token"
+  sensitive = true
+}
+
+process {
+  name = "Variable Run"
+  targets = "host1"
+  discover_info = false
+
+  step "deploy" {
+    name = "Deploy"
+    module = "record"
+
+    input {
+      version = variable.version
+      replicas = variable.replicas
+      features = variable.features
+      token = variable.api_token
+    }
+  }
+}
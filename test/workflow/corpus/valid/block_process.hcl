@@ -0,0 +1,35 @@
+# Process with a block of steps, rescue steps and always steps
+process {
+  name = "Block Process"
+  targets = ["host1", "host2"]
+
+  block "deploy" {
+    name = "Deploy Application"
+    condition = true
+
+    step "disable" {
+      name = "Disable in Load Balancer"
+      module = "shell"
+    }
+
+    step "install" {
+      name = "Install Release"
+      module = "shell"
+    }
+
+    rescue {
+      step "rollback" {
+        name = "Roll Back Release"
+        module = "shell"
+      }
+    }
+
+    always {
+      step "enable" {
+        name = "Enable in Load Balancer"
+        module = "shell"
+        targets = "host1"
+      }
+    }
+  }
+}
@@ -0,0 +1,30 @@
+# Process with handlers notified by steps that report a change
+process {
+  name = "Handler Process"
+  targets = ["host1", "host2"]
+
+  step "config" {
+    name = "Update Config"
+    module = "shell"
+    notify = "restart"
+  }
+
+  flush_handlers {}
+
+  step "certs" {
+    name = "Update Certificates"
+    module = "shell"
+    notify = ["reload", "restart"]
+  }
+
+  handler "restart" {
+    name = "Restart Service"
+    module = "shell"
+  }
+
+  handler "reload" {
+    name = "Reload Service"
+    module = "shell"
+    targets = "host1"
+  }
+}
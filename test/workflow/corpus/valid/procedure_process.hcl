@@ -0,0 +1,26 @@
+# Process that calls a procedure from another file
+process {
+  name = "Procedure Process"
+  targets = ["host1", "host2"]
+
+  step "prepare" {
+    name = "Prepare Host"
+    module = "shell"
+  }
+
+  procedure "install" {
+    name = "Install Package"
+    source = "procedures/install_package.hcl"
+    targets = ["host1"]
+
+    input {
+      package = "nginx"
+    }
+  }
+
+  step "report" {
+    name = "Report Version"
+    module = "shell"
+    condition = steps.install.output.version == "1.0.0"
+  }
+}
@@ -0,0 +1,31 @@
+# Procedure that installs and verifies a package
+input "package" {
+  type = string
+  description = "The name of the package to install."
+}
+
+input "state" {
+  type = string
+  default = "present"
+}
+
+step "install" {
+  name = "Install Package"
+  module = "package"
+
+  input {
+    name = input.package
+    state = input.state
+  }
+}
+
+step "verify" {
+  name = "Verify Package"
+  module = "shell"
+  condition = steps.install.changed
+}
+
+output "version" {
+  value = steps.install.output.version
+  description = "The installed version of the package."
+}
@@ -0,0 +1,26 @@
+# Process with steps that are retried until they succeed or a condition is met
+process {
+  name = "Retry Process"
+  targets = "host1"
+
+  step "wait" {
+    name = "Wait for Service"
+    module = "shell"
+
+    retry {
+      attempts = 5
+      delay = "2s"
+      backoff = 2
+    }
+  }
+
+  step "poll" {
+    name = "Poll Status"
+    module = "shell"
+
+    retry {
+      attempts = 10
+      until = result.output.ready
+    }
+  }
+}
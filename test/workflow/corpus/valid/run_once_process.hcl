@@ -0,0 +1,18 @@
+# Process with steps that only run on one target
+process {
+  name = "Run Once Process"
+  targets = ["host1", "host2", "host3"]
+
+  step "migrate" {
+    name = "Migrate Database"
+    module = "shell"
+    run_once = true
+  }
+
+  step "token" {
+    name = "Fetch Release Token"
+    module = "shell"
+    run_once = true
+    run_once_host = "host2"
+  }
+}
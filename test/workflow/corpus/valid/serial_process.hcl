@@ -0,0 +1,12 @@
+# Process that runs its steps in rolling batches
+process {
+  name = "Serial Process"
+  targets = ["host1", "host2", "host3", "host4"]
+  serial = [1, "50%"]
+  max_fail_percentage = 25
+
+  step "restart" {
+    name = "Restart Service"
+    module = "shell"
+  }
+}
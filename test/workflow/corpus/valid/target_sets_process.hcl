@@ -0,0 +1,34 @@
+# Process with steps that combine targets with set operations
+process {
+  name = "Target Sets"
+  targets = "all:!db2"
+
+  step "intersect" {
+    name = "Intersect"
+    module = "shell"
+    targets = intersect("webservers", "production")
+  }
+
+  step "difference" {
+    name = "Difference"
+    module = "shell"
+    targets = difference("all", "webservers", "db2")
+  }
+
+  step "union" {
+    name = "Union"
+    module = "shell"
+    targets = union("db1", ["web2"])
+  }
+
+  step "predicate" {
+    name = "Predicate"
+    module = "shell"
+    targets = [for host in hosts("all") : host.name if lookup(host.vars, "role", "") == "db"]
+  }
+
+  step "pattern" {
+    name = "Pattern"
+    module = "shell"
+  }
+}
@@ -0,0 +1,2 @@
+version = "v1.1.0"
+replicas = 3
@@ -73,6 +73,22 @@ func createMockHost(name string) *inventory.Host {
 	return host
 }
 
+func createMockHostWithVars(t *testing.T, name string, vars map[string]cty.Value) *inventory.Host {
+	t.Helper()
+
+	host, err := inventory.NewHostBuilder().
+		WithName(name).
+		WithTransport(transport.NewMockTransport()).
+		WithEscalateConfig(inventory.NewEscalateConfig("")).
+		WithVars(vars).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build host %q: %v", name, err)
+	}
+
+	return host
+}
+
 func createMockInventory(h ...*inventory.Host) *inventory.Inventory {
 	hosts := map[string]*inventory.Host{}
 	groups := map[string][]*inventory.Host{}
@@ -148,6 +164,8 @@ type expectedCommon struct {
 
 	execTimeout bool
 
+	tags []string
+
 	input map[string]struct{}
 }
 
@@ -207,6 +225,10 @@ func (e *expectedCommon) verify(t *testing.T, actual *workflow.StepCommonConfig)
 		}
 	}
 
+	if !slices.Equal(e.tags, actual.Tags()) {
+		t.Errorf("expected step tags %v, got %v", e.tags, actual.Tags())
+	}
+
 	if e.input == nil {
 		e.input = map[string]struct{}{}
 	}
@@ -303,12 +325,50 @@ func (e *expectedOutput) verify(t *testing.T, actual *workflow.StepOutputConfig)
 	}
 }
 
+type expectedRetry struct {
+	delay   bool
+	backoff bool
+	until   bool
+}
+
+func (e *expectedRetry) verify(t *testing.T, actual *workflow.StepRetryConfig) {
+
+	if actual == nil {
+		t.Fatalf("expected step retry config to be non-nil, got nil")
+	}
+
+	if actual.Attempts() == nil {
+		t.Error("expected step retry attempts to be present, got nil")
+	}
+
+	if e.delay != (actual.Delay() != nil) {
+		t.Errorf("expected step retry delay presence to be %t", e.delay)
+	}
+
+	if e.backoff != (actual.Backoff() != nil) {
+		t.Errorf("expected step retry backoff presence to be %t", e.backoff)
+	}
+
+	if e.until != (actual.Until() != nil) {
+		t.Errorf("expected step retry until presence to be %t", e.until)
+	}
+}
+
 type expectedStep struct {
 	common     *expectedCommon
 	escalation *expectedEscalation
 	output     *expectedOutput
+	retry      *expectedRetry
 
 	module *mockModule
+
+	runOnce     bool
+	runOnceHost bool
+	notify      []string
+
+	procedure     *expectedProcedure
+	block         *expectedBlock
+	flushHandlers bool
 }
 
 func (e *expectedStep) verify(t *testing.T, a workflow.Step) {
@@ -317,6 +377,23 @@ func (e *expectedStep) verify(t *testing.T, a workflow.Step) {
 		t.Fatalf("expected step to be non-nil, got nil")
 	}
 
+	if e.flushHandlers {
+		if _, ok := a.(*workflow.FlushHandlers); !ok {
+			t.Fatalf("expected step to be of type *workflow.FlushHandlers")
+		}
+		return
+	}
+
+	if e.procedure != nil {
+		e.procedure.verify(t, a)
+		return
+	}
+
+	if e.block != nil {
+		e.block.verify(t, a)
+		return
+	}
+
 	actual, ok := a.(*workflow.SingleStep)
 	if !ok {
 		t.Fatalf("expected step to be of type *workflow.SingleStep")
@@ -343,14 +420,157 @@ func (e *expectedStep) verify(t *testing.T, a workflow.Step) {
 		t.Fatal("expected output config to be nil, got non-nil")
 	}
 
+	actualRetry := actual.Retry()
+	if e.retry != nil {
+		e.retry.verify(t, actualRetry)
+	} else if actualRetry != nil {
+		t.Fatal("expected retry config to be nil, got non-nil")
+	}
+
 	if e.module != actual.Module() {
 		t.Errorf("expected step module to be %v, got %v", e.module, actual.Module())
 	}
+
+	if e.runOnce != actual.RunOnce() {
+		t.Errorf("expected step run_once to be %t, got %t", e.runOnce, actual.RunOnce())
+	}
+
+	if e.runOnceHost {
+		if actual.RunOnceHost() == nil {
+			t.Errorf("expected step run_once_host to be present, got nil")
+		}
+	} else {
+		if actual.RunOnceHost() != nil {
+			t.Error("expected step run_once_host to be nil, got non-nil")
+		}
+	}
+
+	if !slices.Equal(e.notify, actual.Notify()) {
+		t.Errorf("expected step notify to be %v, got %v", e.notify, actual.Notify())
+	}
+}
+
+type expectedProcedure struct {
+	common     *expectedCommon
+	escalation *expectedEscalation
+
+	inputs  map[string]bool // Maps input names to whether they are required
+	outputs []string
+
+	steps []*expectedStep
+}
+
+func (e *expectedProcedure) verify(t *testing.T, a workflow.Step) {
+
+	actual, ok := a.(*workflow.Procedure)
+	if !ok {
+		t.Fatalf("expected step to be of type *workflow.Procedure")
+	}
+
+	if e.common != nil {
+		e.common.verify(t, actual.Common())
+	} else if actual.Common() != nil {
+		t.Fatal("expected common config to be nil, got non-nil")
+	}
+
+	if e.escalation != nil {
+		e.escalation.verify(t, actual.Escalate())
+	} else if actual.Escalate() != nil {
+		t.Fatal("expected escalation config to be nil, got non-nil")
+	}
+
+	actualInputs := actual.Inputs()
+	if len(e.inputs) != len(actualInputs) {
+		t.Errorf("expected %d procedure inputs, got %d", len(e.inputs), len(actualInputs))
+	}
+
+	for name, required := range e.inputs {
+		input, ok := actualInputs[name]
+		if !ok {
+			t.Errorf("missing procedure input %q", name)
+			continue
+		}
+
+		if required != input.Required() {
+			t.Errorf("expected procedure input %q required to be %t, got %t", name, required, input.Required())
+		}
+	}
+
+	actualOutputs := actual.Outputs()
+	if len(e.outputs) != len(actualOutputs) {
+		t.Errorf("expected %d procedure outputs, got %d", len(e.outputs), len(actualOutputs))
+	}
+
+	for _, name := range e.outputs {
+		if _, ok := actualOutputs[name]; !ok {
+			t.Errorf("missing procedure output %q", name)
+		}
+	}
+
+	actualSteps := actual.Steps()
+	if len(e.steps) != len(actualSteps) {
+		t.Fatalf("expected %d procedure steps, got %d", len(e.steps), len(actualSteps))
+	}
+
+	for i := range e.steps {
+		e.steps[i].verify(t, actualSteps[i])
+	}
+}
+
+type expectedBlock struct {
+	common     *expectedCommon
+	escalation *expectedEscalation
+
+	steps  []*expectedStep
+	rescue []*expectedStep
+	always []*expectedStep
+}
+
+func (e *expectedBlock) verify(t *testing.T, a workflow.Step) {
+
+	actual, ok := a.(*workflow.Block)
+	if !ok {
+		t.Fatalf("expected step to be of type *workflow.Block")
+	}
+
+	e.common.verify(t, actual.Common())
+
+	actualEscalation := actual.Escalate()
+	if e.escalation != nil {
+		e.escalation.verify(t, actualEscalation)
+	} else if actualEscalation != nil {
+		t.Fatal("expected escalation config to be nil, got non-nil")
+	}
+
+	sections := []struct {
+		name     string
+		expected []*expectedStep
+		actual   []workflow.Step
+	}{
+		{name: "block", expected: e.steps, actual: actual.Steps()},
+		{name: "rescue", expected: e.rescue, actual: actual.Rescue()},
+		{name: "always", expected: e.always, actual: actual.Always()},
+	}
+
+	for _, section := range sections {
+		if len(section.expected) != len(section.actual) {
+			t.Fatalf("expected %d %s steps, got %d", len(section.expected), section.name, len(section.actual))
+		}
+
+		for i := range section.expected {
+			section.expected[i].verify(t, section.actual[i])
+		}
+	}
 }
 
 type expectedProcess struct {
 	name  string
 	steps []*expectedStep
+
+	handlers []*expectedStep
+
+	serial            []*workflow.BatchSize
+	maxFailPercentage *float64
 }
 
 func (e *expectedProcess) verify(t *testing.T, actual *workflow.Process) {
@@ -363,6 +583,27 @@ func (e *expectedProcess) verify(t *testing.T, actual *workflow.Process) {
 		t.Errorf("expected process name %q, got %q", e.name, actual.Name())
 	}
 
+	actualSerial := actual.Serial()
+	if len(e.serial) != len(actualSerial) {
+		t.Errorf("expected %d batch sizes, got %d", len(e.serial), len(actualSerial))
+	} else {
+		for i := range e.serial {
+			if e.serial[i].Value() != actualSerial[i].Value() ||
+				e.serial[i].Percentage() != actualSerial[i].Percentage() {
+				t.Errorf("expected batch size %d to be %v, got %v", i, e.serial[i], actualSerial[i])
+			}
+		}
+	}
+
+	switch {
+	case e.maxFailPercentage == nil && actual.MaxFailPercentage() != nil:
+		t.Errorf("expected max_fail_percentage to be nil, got %v", *actual.MaxFailPercentage())
+	case e.maxFailPercentage != nil && actual.MaxFailPercentage() == nil:
+		t.Errorf("expected max_fail_percentage to be %v, got nil", *e.maxFailPercentage)
+	case e.maxFailPercentage != nil && *e.maxFailPercentage != *actual.MaxFailPercentage():
+		t.Errorf("expected max_fail_percentage to be %v, got %v", *e.maxFailPercentage, *actual.MaxFailPercentage())
+	}
+
 	actualSteps := actual.Steps()
 	if len(e.steps) != len(actualSteps) {
 		t.Fatalf("expected %d steps, got %d", len(e.steps), len(actualSteps))
@@ -371,6 +612,20 @@ func (e *expectedProcess) verify(t *testing.T, actual *workflow.Process) {
 	for i := range e.steps {
 		e.steps[i].verify(t, actualSteps[i])
 	}
+
+	actualHandlers := actual.Handlers()
+	if len(e.handlers) != len(actualHandlers) {
+		t.Fatalf("expected %d handlers, got %d", len(e.handlers), len(actualHandlers))
+	}
+
+	for i := range e.handlers {
+		e.handlers[i].verify(t, actualHandlers[i])
+
+		handler, ok := actualHandlers[i].(*workflow.SingleStep)
+		if ok && !handler.Handler() {
+			t.Errorf("expected handler %q to be marked as a handler", handler.ID())
+		}
+	}
 }
 
 type expected struct {
@@ -4,6 +4,7 @@
 package test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -660,3 +661,517 @@ func TestSyntaxErrorMissingQuotes(t *testing.T) {
 
 	expectedDiags.verify(t, diags)
 }
+
+func TestProcedureMissingInput(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "procedure_missing_input.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Unsupported procedure input",
+			detail:   "The procedure \"procedures/install_package.hcl\" does not declare an input named \"version\".",
+		},
+		{
+			severity: hcl.DiagError,
+			summary:  "Missing procedure input",
+			detail:   "The input \"package\" is required by the procedure \"procedures/install_package.hcl\".",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestProcedureCircular(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "procedure_circular.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	circularPath, err := filepath.Abs(filepath.Join("corpus", "invalid", "procedures", "circular_a.hcl"))
+	if err != nil {
+		t.Fatalf("failed to resolve path: %v", err)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Circular procedure reference",
+			detail: fmt.Sprintf(
+				"The procedure file %q includes itself, either directly or through another procedure.",
+				circularPath,
+			),
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestRunOnceHostWithoutRunOnce(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "run_once_host_without_run_once.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Invalid run_once_host",
+			detail:   "The 'run_once_host' attribute can only be used when 'run_once' is true.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestInvalidSerial(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "invalid_serial.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	detail := "Each batch size in the 'serial' attribute must be a positive whole number or " +
+		"a percentage string between \"1%\" and \"100%\"."
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Invalid batch size",
+			detail:   detail,
+		},
+		{
+			severity: hcl.DiagError,
+			summary:  "Invalid batch size",
+			detail:   detail,
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestHandlerNotFound(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "handler_not_found.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Handler not found",
+			detail:   "The handler \"reload\" is not defined in this process.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestMultipleRetryBlocks(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "multiple_retry_blocks.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Duplicate retry block",
+			detail:   "Only one retry block is allowed.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestMultipleRescueBlocks(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "multiple_rescue_blocks.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Duplicate rescue block",
+			detail:   "Only one rescue block is allowed per block.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestCircularLocals(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "circular_locals.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Circular local reference",
+			detail:   "The local \"app_dir\" refers to itself through local.app_dir -> local.base_dir -> local.app_dir.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestDuplicateLocal(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "duplicate_local.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Duplicate local",
+			detail:   "The local \"app_name\" is already defined at the top level of the workflow.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestInvalidTags(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "invalid_tags.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Invalid tags",
+			detail:   "The 'tags' attribute must be a list of strings.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestInvalidAsync(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "invalid_async.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Invalid poll",
+			detail:   "The 'poll' attribute can only be used when 'async' is set.",
+		},
+		{
+			severity: hcl.DiagError,
+			summary:  "Invalid async",
+			detail:   "The module \"shell\" cannot run asynchronously.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
+
+func TestDuplicateOutput(t *testing.T) {
+
+	path := filepath.Join("corpus", "invalid", "duplicate_output.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error, got none")
+	}
+
+	if w != nil {
+		t.Fatalf("expected nil workflow, got: %v", w)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagError,
+			summary:  "Duplicate output",
+			detail:   "The output \"version\" is declared multiple times.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+}
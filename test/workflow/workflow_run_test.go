@@ -0,0 +1,2005 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/hclspec"
+	"github.com/trippsoft/forge/pkg/info"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/module"
+	"github.com/trippsoft/forge/pkg/result"
+	"github.com/trippsoft/forge/pkg/set"
+	"github.com/trippsoft/forge/pkg/transport"
+	"github.com/trippsoft/forge/pkg/ui"
+	"github.com/trippsoft/forge/pkg/workflow"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// recordingModule records the input it receives for each host, identified by the host's transport.
+type recordingModule struct {
+	*mockModule
+
+	mutex  sync.Mutex
+	inputs map[*inventory.Host]map[string]cty.Value
+	runs   map[*inventory.Host]int
+	failOn *set.Set[*inventory.Host]
+}
+
+func newRecordingModule(name string, spec *hclspec.Spec, r *result.Result, hosts ...*inventory.Host) *recordingModule {
+	m := &recordingModule{
+		inputs: map[*inventory.Host]map[string]cty.Value{},
+		runs:   map[*inventory.Host]int{},
+	}
+
+	m.mockModule = newMockModule(name, spec, func(config *module.RunConfig) error {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		for _, host := range hosts {
+			if host.Transport() != config.Transport {
+				continue
+			}
+
+			m.inputs[host] = config.Input
+			m.runs[host]++
+
+			if m.failOn != nil && m.failOn.Contains(host) {
+				return errors.New("module failed on host")
+			}
+		}
+
+		return nil
+	})
+
+	m.Result = r
+
+	return m
+}
+
+// failingOn makes the module fail on the given hosts.
+func (m *recordingModule) failingOn(hosts ...*inventory.Host) *recordingModule {
+	m.failOn = set.NewSet(hosts...)
+	return m
+}
+
+func (m *recordingModule) input(t *testing.T, host *inventory.Host, name string) cty.Value {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	input, ok := m.inputs[host]
+	if !ok {
+		t.Fatalf("module %q did not run on host %q", m.name, host.Name())
+	}
+
+	return input[name]
+}
+
+func (m *recordingModule) ranOn(host *inventory.Host) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, ok := m.inputs[host]
+	return ok
+}
+
+func (m *recordingModule) runCount(host *inventory.Host) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.runs[host]
+}
+
+// flakyModule fails until it has run a number of times, then reports the number of runs as its output.
+type flakyModule struct {
+	*mockModule
+
+	failures int
+
+	mutex sync.Mutex
+	runs  int
+}
+
+func newFlakyModule(name string, failures int) *flakyModule {
+	return &flakyModule{
+		mockModule: newMockModule(name, hclspec.NewSpec(hclspec.Object()), nil),
+		failures:   failures,
+	}
+}
+
+func (m *flakyModule) Run(ctx context.Context, config *module.RunConfig) *result.Result {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.runs++
+	if m.runs <= m.failures {
+		return result.NewFailure(errors.New("service is not ready"), "")
+	}
+
+	return result.NewChanged(cty.ObjectVal(map[string]cty.Value{
+		"runs": cty.NumberIntVal(int64(m.runs)),
+	}))
+}
+
+func parseWorkflowForRun(
+	t *testing.T,
+	path string,
+	i *inventory.Inventory,
+	moduleRegistry *module.Registry,
+) *workflow.Workflow {
+
+	t.Helper()
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workflow file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	return w
+}
+
+func parseAndRunWorkflow(
+	t *testing.T,
+	path string,
+	i *inventory.Inventory,
+	moduleRegistry *module.Registry,
+) ([]map[string]map[string]cty.Value, error) {
+
+	t.Helper()
+
+	w := parseWorkflowForRun(t, path, i, moduleRegistry)
+
+	wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+	if err != nil {
+		t.Fatalf("failed to create workflow context: %v", err)
+	}
+
+	return w.Run(wc)
+}
+
+func TestProcedureRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "procedure_run.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+
+	i := createMockInventory(host1, host2)
+
+	packageModule := newRecordingModule(
+		"package",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("name", hclspec.String),
+			hclspec.RequiredField("state", hclspec.String),
+		)),
+		result.NewChanged(cty.ObjectVal(map[string]cty.Value{
+			"version": cty.StringVal("1.0.0"),
+		})),
+		host1,
+		host2,
+	)
+
+	shellModule := newRecordingModule(
+		"shell",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+	)
+
+	recordModule := newRecordingModule(
+		"record",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("version", hclspec.Raw),
+			hclspec.RequiredField("inner_step", hclspec.Raw),
+		)),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(packageModule)
+	moduleRegistry.Register(shellModule)
+	moduleRegistry.Register(recordModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	if packageModule.ranOn(host2) {
+		t.Error("expected package module not to run on host2")
+	}
+
+	if got := packageModule.input(t, host1, "name"); !got.RawEquals(cty.StringVal("nginx")) {
+		t.Errorf("expected package name input %q, got %#v", "nginx", got)
+	}
+
+	if got := packageModule.input(t, host1, "state"); !got.RawEquals(cty.StringVal("present")) {
+		t.Errorf("expected default package state input %q, got %#v", "present", got)
+	}
+
+	if !shellModule.ranOn(host1) {
+		t.Error("expected verify step to run on host1")
+	}
+
+	if got := recordModule.input(t, host1, "version"); !got.RawEquals(cty.StringVal("1.0.0")) {
+		t.Errorf("expected procedure output version %q on host1, got %#v", "1.0.0", got)
+	}
+
+	if got := recordModule.input(t, host2, "version"); !got.RawEquals(cty.StringVal("none")) {
+		t.Errorf("expected no procedure output on host2, got %#v", got)
+	}
+
+	if got := recordModule.input(t, host1, "inner_step"); !got.RawEquals(cty.StringVal("hidden")) {
+		t.Errorf("expected procedure steps to be scoped to the procedure, got %#v", got)
+	}
+
+	if len(outputs) != 1 {
+		t.Fatalf("expected 1 process output, got %d", len(outputs))
+	}
+
+	procedureOutput, ok := outputs[0]["install"]["host1"]
+	if !ok {
+		t.Fatal("expected procedure output for host1")
+	}
+
+	if !procedureOutput.GetAttr("changed").True() {
+		t.Error("expected procedure to report changed")
+	}
+
+	if procedureOutput.GetAttr("failed").True() {
+		t.Error("expected procedure not to report failed")
+	}
+}
+
+func TestRunOnceRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "run_once_run.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+	host3 := createMockHost("host3")
+
+	i := createMockInventory(host3, host2, host1)
+
+	migrateModule := newRecordingModule(
+		"migrate",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewChanged(cty.ObjectVal(map[string]cty.Value{
+			"version": cty.NumberIntVal(42),
+		})),
+		host1,
+		host2,
+		host3,
+	)
+
+	tokenModule := newRecordingModule(
+		"token",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewNotChanged(cty.ObjectVal(map[string]cty.Value{
+			"token": cty.StringVal("abc123"),
+		})),
+		host1,
+		host2,
+		host3,
+	)
+
+	recordModule := newRecordingModule(
+		"record",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("version", hclspec.Raw),
+			hclspec.RequiredField("token", hclspec.Raw),
+			hclspec.RequiredField("shared", hclspec.Raw),
+		)),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+		host3,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(migrateModule)
+	moduleRegistry.Register(tokenModule)
+	moduleRegistry.Register(recordModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	if !migrateModule.ranOn(host1) || migrateModule.ranOn(host2) || migrateModule.ranOn(host3) {
+		t.Error("expected migrate step to run only on host1")
+	}
+
+	if !tokenModule.ranOn(host3) || tokenModule.ranOn(host1) || tokenModule.ranOn(host2) {
+		t.Error("expected token step to run only on host3")
+	}
+
+	for _, host := range []*inventory.Host{host1, host2, host3} {
+		if got := recordModule.input(t, host, "version"); !got.RawEquals(cty.NumberIntVal(42)) {
+			t.Errorf("expected shared migrate output on %q, got %#v", host.Name(), got)
+		}
+
+		if got := recordModule.input(t, host, "token"); !got.RawEquals(cty.StringVal("abc123")) {
+			t.Errorf("expected shared token output on %q, got %#v", host.Name(), got)
+		}
+
+		if got := recordModule.input(t, host, "shared"); !got.RawEquals(cty.StringVal("abc123")) {
+			t.Errorf("expected shared token output of host1 through hostvars on %q, got %#v", host.Name(), got)
+		}
+
+		if _, ok := outputs[0]["migrate"][host.Name()]; !ok {
+			t.Errorf("expected migrate output for %q", host.Name())
+		}
+	}
+}
+
+func TestSerialRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "serial_run.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+	host3 := createMockHost("host3")
+	host4 := createMockHost("host4")
+
+	hosts := []*inventory.Host{host1, host2, host3, host4}
+
+	i := createMockInventory(hosts...)
+
+	mutex := sync.Mutex{}
+	runs := []string{}
+	record := func(step string) func(config *module.RunConfig) error {
+		return func(config *module.RunConfig) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			for _, host := range hosts {
+				if host.Transport() == config.Transport {
+					runs = append(runs, step+":"+host.Name())
+				}
+			}
+
+			return nil
+		}
+	}
+
+	stopModule := newMockModule("stop", hclspec.NewSpec(hclspec.Object()), record("stop"))
+	stopModule.Result = result.NewChanged(cty.EmptyObjectVal)
+
+	startModule := newMockModule("start", hclspec.NewSpec(hclspec.Object()), record("start"))
+	startModule.Result = result.NewChanged(cty.EmptyObjectVal)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(stopModule)
+	moduleRegistry.Register(startModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	// Batches are [host1], [host2, host3] and [host4]. Within a batch, hosts run in parallel.
+	expectedBatches := [][]string{
+		{"stop:host1"},
+		{"start:host1"},
+		{"stop:host2", "stop:host3"},
+		{"start:host2", "start:host3"},
+		{"stop:host4"},
+		{"start:host4"},
+	}
+
+	if len(runs) != 8 {
+		t.Fatalf("expected 8 module runs, got %d: %v", len(runs), runs)
+	}
+
+	index := 0
+	for _, batch := range expectedBatches {
+		actual := runs[index : index+len(batch)]
+		for _, run := range batch {
+			if !slices.Contains(actual, run) {
+				t.Errorf("expected %q in runs %v, got %v", run, index, actual)
+			}
+		}
+
+		index += len(batch)
+	}
+
+	for _, host := range hosts {
+		if _, ok := outputs[0]["start"][host.Name()]; !ok {
+			t.Errorf("expected start output for %q", host.Name())
+		}
+	}
+}
+
+func TestMaxFailPercentageRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "max_fail_percentage_run.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+	host3 := createMockHost("host3")
+	host4 := createMockHost("host4")
+
+	i := createMockInventory(host1, host2, host3, host4)
+
+	deployModule := newRecordingModule(
+		"deploy",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+		host3,
+		host4,
+	)
+
+	failingValidate := deployModule.validateFunc
+	deployModule.validateFunc = func(config *module.RunConfig) error {
+		failingValidate(config)
+		if config.Transport == host1.Transport() {
+			return errors.New("deployment failed")
+		}
+
+		return nil
+	}
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(deployModule)
+
+	_, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err == nil {
+		t.Fatal("expected workflow to fail, got nil error")
+	}
+
+	if !deployModule.ranOn(host1) || !deployModule.ranOn(host2) {
+		t.Error("expected deploy step to run on the first batch")
+	}
+
+	if deployModule.ranOn(host3) || deployModule.ranOn(host4) {
+		t.Error("expected the rollout to stop before the second batch")
+	}
+}
+
+// concurrencyModule records the maximum number of concurrent runs of the module.
+type concurrencyModule struct {
+	*mockModule
+
+	mutex   sync.Mutex
+	current int
+	maximum int
+	runs    int
+}
+
+func newConcurrencyModule(name string) *concurrencyModule {
+	m := &concurrencyModule{}
+
+	m.mockModule = newMockModule(name, hclspec.NewSpec(hclspec.Object()), func(config *module.RunConfig) error {
+		m.mutex.Lock()
+		m.current++
+		m.runs++
+		m.maximum = max(m.maximum, m.current)
+		m.mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		m.mutex.Lock()
+		m.current--
+		m.mutex.Unlock()
+
+		return nil
+	})
+
+	m.Result = result.NewNotChanged(cty.EmptyObjectVal)
+
+	return m
+}
+
+func TestForksAndThrottleRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "forks_throttle_run.hcl")
+
+	hosts := []*inventory.Host{}
+	for _, name := range []string{"host1", "host2", "host3", "host4", "host5", "host6"} {
+		hosts = append(hosts, createMockHost(name))
+	}
+
+	i := createMockInventory(hosts...)
+
+	forkedModule := newConcurrencyModule("forked")
+	throttledModule := newConcurrencyModule("throttled")
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(forkedModule)
+	moduleRegistry.Register(throttledModule)
+
+	w := parseWorkflowForRun(t, path, i, moduleRegistry)
+
+	steps := w.Processes()[0].Steps()
+	if throttle := steps[1].(*workflow.SingleStep).Throttle(); throttle != 2 {
+		t.Fatalf("expected throttle of 2, got %d", throttle)
+	}
+
+	wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+	if err != nil {
+		t.Fatalf("failed to create workflow context: %v", err)
+	}
+
+	wc.WithForks(3)
+
+	_, err = w.Run(wc)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	// The forked step loops twice on each host, so each iteration must respect the forks limit.
+	if forkedModule.runs != 12 {
+		t.Errorf("expected 12 runs of the forked step, got %d", forkedModule.runs)
+	}
+
+	if forkedModule.maximum > 3 {
+		t.Errorf("expected at most 3 concurrent runs with 3 forks, got %d", forkedModule.maximum)
+	}
+
+	if throttledModule.runs != 6 {
+		t.Errorf("expected 6 runs of the throttled step, got %d", throttledModule.runs)
+	}
+
+	if throttledModule.maximum > 2 {
+		t.Errorf("expected at most 2 concurrent runs with a throttle of 2, got %d", throttledModule.maximum)
+	}
+}
+
+func TestHandlerRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "handler_run.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+	host3 := createMockHost("host3")
+
+	i := createMockInventory(host1, host2, host3)
+
+	changedModule := newRecordingModule(
+		"changed",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+		host3,
+	)
+
+	unchangedModule := newRecordingModule(
+		"unchanged",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+		host3,
+	)
+
+	restartModule := newRecordingModule(
+		"restart",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewChanged(cty.ObjectVal(map[string]cty.Value{
+			"restarted": cty.True,
+		})),
+		host1,
+		host2,
+		host3,
+	)
+
+	reloadModule := newRecordingModule(
+		"reload",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+		host3,
+	)
+
+	recordModule := newRecordingModule(
+		"record",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("restarted", hclspec.Raw),
+		)),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+		host3,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(changedModule)
+	moduleRegistry.Register(unchangedModule)
+	moduleRegistry.Register(restartModule)
+	moduleRegistry.Register(reloadModule)
+	moduleRegistry.Register(recordModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	if restartModule.runCount(host1) != 1 {
+		t.Errorf("expected restart handler to run once on host1, ran %d times", restartModule.runCount(host1))
+	}
+
+	if restartModule.ranOn(host2) || restartModule.ranOn(host3) {
+		t.Error("expected restart handler to run only on host1")
+	}
+
+	if !recordModule.input(t, host1, "restarted").True() {
+		t.Error("expected restart handler to run at the flush_handlers point before the verify step")
+	}
+
+	if reloadModule.runCount(host3) != 1 {
+		t.Errorf("expected reload handler to run once on host3, ran %d times", reloadModule.runCount(host3))
+	}
+
+	if reloadModule.ranOn(host1) || reloadModule.ranOn(host2) {
+		t.Error("expected reload handler to run only on host3")
+	}
+
+	for _, id := range []string{"restart", "reload"} {
+		if _, exists := outputs[0][id]; !exists {
+			t.Errorf("expected outputs for handler %q", id)
+		}
+	}
+}
+
+func TestRetryRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "retry_run.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	flaky := newFlakyModule("flaky", 2)
+	counter := newFlakyModule("counter", 0)
+	broken := newFlakyModule("broken", 100)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(flaky)
+	moduleRegistry.Register(counter)
+	moduleRegistry.Register(broken)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	tests := []struct {
+		step     string
+		attempts int64
+		failed   bool
+	}{
+		{step: "wait", attempts: 3, failed: false},
+		{step: "poll", attempts: 3, failed: false},
+		{step: "exhaust", attempts: 2, failed: true},
+	}
+
+	for _, tt := range tests {
+		output := outputs[0][tt.step]["host1"]
+		if output.IsNull() {
+			t.Errorf("expected output for step %q", tt.step)
+			continue
+		}
+
+		attempts, _ := output.GetAttr("attempts").AsBigFloat().Int64()
+		if attempts != tt.attempts {
+			t.Errorf("expected step %q to make %d attempts, made %d", tt.step, tt.attempts, attempts)
+		}
+
+		if output.GetAttr("failed").True() != tt.failed {
+			t.Errorf("expected step %q failed to be %t", tt.step, tt.failed)
+		}
+	}
+
+	if broken.runs != 2 {
+		t.Errorf("expected broken module to run 2 times, ran %d times", broken.runs)
+	}
+
+	path = filepath.Join("corpus", "run", "retry_until_run.hcl")
+
+	host1 = createMockHost("host1")
+
+	i = createMockInventory(host1)
+
+	counter = newFlakyModule("counter", 0)
+	deploy := newFlakyModule("deploy", 0)
+
+	moduleRegistry = module.NewRegistry()
+	moduleRegistry.Register(counter)
+	moduleRegistry.Register(deploy)
+
+	outputs, err = parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err == nil {
+		t.Fatal("expected error from the unmet until condition, got none")
+	}
+
+	output := outputs[0]["poll"]["host1"]
+	if output.IsNull() {
+		t.Fatal("expected output for step \"poll\"")
+	}
+
+	if !output.GetAttr("failed").True() {
+		t.Error("expected step \"poll\" to fail when its until condition is not met")
+	}
+
+	if counter.runs != 2 {
+		t.Errorf("expected counter module to run 2 times, ran %d times", counter.runs)
+	}
+
+	if deploy.runs != 0 {
+		t.Errorf("expected deploy module to be skipped on the failed host, ran %d times", deploy.runs)
+	}
+}
+
+func TestFailedStepRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "failed_step_run.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+
+	i := createMockInventory(host1, host2)
+
+	newModule := func(name string) *recordingModule {
+		return newRecordingModule(
+			name,
+			hclspec.NewSpec(hclspec.Object()),
+			result.NewChanged(cty.EmptyObjectVal),
+			host1,
+			host2,
+		)
+	}
+
+	deployModule := newModule("deploy").failingOn(host2)
+	verifyModule := newModule("verify")
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(deployModule)
+	moduleRegistry.Register(verifyModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err == nil {
+		t.Fatal("expected error from the failed step on host2, got none")
+	}
+
+	if !outputs[0]["deploy"]["host2"].GetAttr("failed").True() {
+		t.Error("expected the deploy step to fail on host2")
+	}
+
+	if got := verifyModule.runCount(host1); got != 1 {
+		t.Errorf("expected the verify step to run once on host1, ran %d times", got)
+	}
+
+	if got := verifyModule.runCount(host2); got != 0 {
+		t.Errorf("expected the verify step to be skipped on the failed host2, ran %d times", got)
+	}
+}
+
+func TestBlockRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "block_run.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+	host3 := createMockHost("host3")
+
+	i := createMockInventory(host1, host2, host3)
+
+	newModule := func(name string) *recordingModule {
+		return newRecordingModule(
+			name,
+			hclspec.NewSpec(hclspec.Object()),
+			result.NewChanged(cty.EmptyObjectVal),
+			host1,
+			host2,
+			host3,
+		)
+	}
+
+	deployModule := newModule("deploy").failingOn(host2, host3)
+	rollbackModule := newModule("rollback").failingOn(host3)
+	enableModule := newModule("enable")
+	verifyModule := newModule("verify")
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(deployModule)
+	moduleRegistry.Register(rollbackModule)
+	moduleRegistry.Register(enableModule)
+	moduleRegistry.Register(verifyModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err == nil {
+		t.Fatal("expected error from the failed rescue on host3, got none")
+	}
+
+	if rollbackModule.ranOn(host1) || !rollbackModule.ranOn(host2) || !rollbackModule.ranOn(host3) {
+		t.Error("expected rescue steps to run only on host2 and host3")
+	}
+
+	for _, host := range []*inventory.Host{host1, host2, host3} {
+		if !enableModule.ranOn(host) {
+			t.Errorf("expected always steps to run on %q", host.Name())
+		}
+	}
+
+	if !verifyModule.ranOn(host1) || !verifyModule.ranOn(host2) || verifyModule.ranOn(host3) {
+		t.Error("expected steps after the block to run only on host1 and the rescued host2")
+	}
+
+	blockOutputs := outputs[0]["deploy_app"]
+
+	rescued := blockOutputs["host2"].GetAttr("output").GetAttr("rescued")
+	if !rescued.True() {
+		t.Error("expected block to report host2 as rescued")
+	}
+
+	if !blockOutputs["host3"].GetAttr("failed").True() {
+		t.Error("expected block to report host3 as failed")
+	}
+
+	if _, exists := outputs[0]["rollback"]; !exists {
+		t.Error("expected outputs for steps within the block")
+	}
+}
+
+func TestLocalsRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "locals_run.hcl")
+
+	host1 := createMockHostWithVars(t, "host1", map[string]cty.Value{"port": cty.NumberIntVal(8080)})
+	host2 := createMockHostWithVars(t, "host2", map[string]cty.Value{"port": cty.NumberIntVal(9090)})
+
+	i := createMockInventory(host1, host2)
+
+	recordModule := newRecordingModule(
+		"record",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("path", hclspec.String),
+			hclspec.RequiredField("config", hclspec.Raw),
+		)),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(recordModule)
+
+	_, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	tests := []struct {
+		host *inventory.Host
+		port int64
+	}{
+		{host: host1, port: 8080},
+		{host: host2, port: 9090},
+	}
+
+	for _, tt := range tests {
+		path := recordModule.input(t, tt.host, "path")
+		if path.AsString() != "/opt/myapp/releases/1.0.0" {
+			t.Errorf("expected path on %q to be %q, got %q", tt.host.Name(), "/opt/myapp/releases/1.0.0", path.AsString())
+		}
+
+		config := recordModule.input(t, tt.host, "config")
+
+		expected := cty.ObjectVal(map[string]cty.Value{
+			"log_level": cty.StringVal("info"),
+			"port":      cty.NumberIntVal(tt.port),
+		})
+
+		if !config.Equals(expected).True() {
+			t.Errorf("expected config on %q to be %s, got %s", tt.host.Name(), expected.GoString(), config.GoString())
+		}
+	}
+}
+
+func TestTagsRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "tags_run.hcl")
+
+	tests := []struct {
+		name     string
+		tags     []string
+		skipTags []string
+		ran      []string
+		procTags []string
+	}{
+		{
+			name:     "no filter",
+			ran:      []string{"config", "restart", "cleanup", "check"},
+			procTags: []string{"always", "config", "deploy", "service"},
+		},
+		{
+			name:     "tags",
+			tags:     []string{"config"},
+			ran:      []string{"config", "cleanup"},
+			procTags: []string{"always", "config", "deploy"},
+		},
+		{
+			name:     "inherited tags",
+			tags:     []string{"deploy"},
+			ran:      []string{"config", "restart", "debug", "cleanup"},
+			procTags: []string{"always", "config", "debug", "deploy", "never", "service"},
+		},
+		{
+			name:     "never",
+			tags:     []string{"debug"},
+			ran:      []string{"debug", "cleanup"},
+			procTags: []string{"always", "debug", "deploy", "never", "service"},
+		},
+		{
+			name:     "skip tags",
+			skipTags: []string{"service"},
+			ran:      []string{"config", "cleanup", "check"},
+			procTags: []string{"always", "config", "deploy"},
+		},
+		{
+			name:     "skip always",
+			tags:     []string{"config"},
+			skipTags: []string{"always"},
+			ran:      []string{"config"},
+			procTags: []string{"config", "deploy"},
+		},
+		{
+			name:     "untagged",
+			tags:     []string{"untagged"},
+			ran:      []string{"cleanup", "check"},
+			procTags: []string{"always", "deploy"},
+		},
+	}
+
+	moduleNames := []string{"config", "restart", "debug", "cleanup", "check"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host1 := createMockHost("host1")
+
+			i := createMockInventory(host1)
+
+			moduleRegistry := module.NewRegistry()
+			modules := map[string]*recordingModule{}
+			for _, name := range moduleNames {
+				modules[name] = newRecordingModule(
+					name,
+					hclspec.NewSpec(hclspec.Object()),
+					result.NewNotChanged(cty.EmptyObjectVal),
+					host1,
+				)
+
+				moduleRegistry.Register(modules[name])
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read workflow file: %v", err)
+			}
+
+			parser := workflow.NewParser(i, moduleRegistry).WithTagFilter(workflow.NewTagFilter(tt.tags, tt.skipTags))
+
+			w, diags := parser.ParseWorkflowFile(path, content)
+			if diags.HasErrors() {
+				t.Fatalf("failed to parse workflow file: %v", diags)
+			}
+
+			procTags := w.Processes()[0].Tags()
+			if !slices.Equal(procTags, tt.procTags) {
+				t.Errorf("expected process tags %v, got %v", tt.procTags, procTags)
+			}
+
+			wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+			if err != nil {
+				t.Fatalf("failed to create workflow context: %v", err)
+			}
+
+			_, err = w.Run(wc)
+			if err != nil {
+				t.Fatalf("failed to run workflow: %v", err)
+			}
+
+			for _, name := range moduleNames {
+				expected := slices.Contains(tt.ran, name)
+				if modules[name].ranOn(host1) != expected {
+					t.Errorf("expected step using module %q to run: %t", name, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "limit_run.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+	host3 := createMockHost("host3")
+
+	i := createMockInventory(host1, host2, host3)
+
+	newModule := func(name string) *recordingModule {
+		return newRecordingModule(
+			name,
+			hclspec.NewSpec(hclspec.Object()),
+			result.NewNotChanged(cty.EmptyObjectVal),
+			host1,
+			host2,
+			host3,
+		)
+	}
+
+	firstModule := newModule("first")
+	secondModule := newModule("second")
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(firstModule)
+	moduleRegistry.Register(secondModule)
+
+	limit, err := i.ResolvePattern("host1,host3")
+	if err != nil {
+		t.Fatalf("failed to resolve limit: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workflow file: %v", err)
+	}
+
+	parser := workflow.NewParser(i, moduleRegistry).WithLimit(limit)
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	allTargets := w.Processes()[0].AllTargets()
+	if len(allTargets) != 2 || !slices.Contains(allTargets, host1) || !slices.Contains(allTargets, host3) {
+		t.Errorf("expected process targets to be limited to host1 and host3, got %d targets", len(allTargets))
+	}
+
+	wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+	if err != nil {
+		t.Fatalf("failed to create workflow context: %v", err)
+	}
+
+	_, err = w.Run(wc)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	if !firstModule.ranOn(host1) || firstModule.ranOn(host2) || firstModule.ranOn(host3) {
+		t.Error("expected the process targets to be limited to host1")
+	}
+
+	if !secondModule.ranOn(host1) || secondModule.ranOn(host2) || !secondModule.ranOn(host3) {
+		t.Error("expected the step targets to be limited to host1 and host3")
+	}
+}
+
+// stoppingModule calls a function when it runs and reports whether its context was cancelled before it finished.
+type stoppingModule struct {
+	*mockModule
+
+	onRun func()
+	wait  bool
+
+	mutex     sync.Mutex
+	cancelled bool
+}
+
+func (m *stoppingModule) Run(ctx context.Context, config *module.RunConfig) *result.Result {
+	m.onRun()
+
+	if m.wait {
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.cancelled = ctx.Err() != nil
+	if m.cancelled {
+		return result.NewFailure(ctx.Err(), "")
+	}
+
+	return result.NewNotChanged(cty.EmptyObjectVal)
+}
+
+func TestStopRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "stop_run.hcl")
+
+	tests := []struct {
+		name          string
+		cancel        bool
+		wantCancelled bool
+	}{
+		{
+			name:          "stop lets running modules finish",
+			cancel:        false,
+			wantCancelled: false,
+		},
+		{
+			name:          "cancel cancels running modules",
+			cancel:        true,
+			wantCancelled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host1 := createMockHost("host1")
+
+			i := createMockInventory(host1)
+
+			runCtx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+
+			stopCtx, stop := context.WithCancelCause(runCtx)
+			defer stop(nil)
+
+			cause := errors.New("received interrupt")
+
+			stopModule := &stoppingModule{
+				mockModule: newMockModule("stop", hclspec.NewSpec(hclspec.Object()), nil),
+				wait:       tt.cancel,
+				onRun: func() {
+					if tt.cancel {
+						cancel(cause)
+						return
+					}
+
+					stop(cause)
+				},
+			}
+
+			secondModule := newRecordingModule(
+				"second",
+				hclspec.NewSpec(hclspec.Object()),
+				result.NewNotChanged(cty.EmptyObjectVal),
+				host1,
+			)
+
+			moduleRegistry := module.NewRegistry()
+			moduleRegistry.Register(stopModule)
+			moduleRegistry.Register(secondModule)
+
+			w := parseWorkflowForRun(t, path, i, moduleRegistry)
+
+			wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+			if err != nil {
+				t.Fatalf("failed to create workflow context: %v", err)
+			}
+
+			outputs, err := w.Run(wc.WithContext(runCtx).WithStopContext(stopCtx))
+			if !errors.Is(err, workflow.ErrStopped) {
+				t.Fatalf("expected the workflow to be stopped, got %v", err)
+			}
+
+			if !errors.Is(err, cause) {
+				t.Errorf("expected the error to wrap the cause of the stop, got %v", err)
+			}
+
+			if stopModule.cancelled != tt.wantCancelled {
+				t.Errorf("expected the running module to be cancelled: %v, got %v", tt.wantCancelled, stopModule.cancelled)
+			}
+
+			if secondModule.ranOn(host1) {
+				t.Error("expected no step to start after the workflow was stopped")
+			}
+
+			if len(outputs) != 1 {
+				t.Errorf("expected only the stopped process to have outputs, got %d", len(outputs))
+			}
+		})
+	}
+}
+
+func TestExecTimeoutRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "exec_timeout_run.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	hangModule := &stoppingModule{
+		mockModule: newMockModule("hang", hclspec.NewSpec(hclspec.Object()), nil),
+		wait:       true,
+		onRun:      func() {},
+	}
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(hangModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("expected an exec timeout not to stop the workflow, got %v", err)
+	}
+
+	output := outputs[0]["hang"]["host1"]
+	if !output.GetAttr("failed").True() {
+		t.Fatal("expected the step to fail when its exec_timeout elapsed")
+	}
+
+	message := output.GetAttr("error").AsString()
+	if !strings.HasPrefix(message, workflow.ErrExecTimeout.Error()) {
+		t.Errorf("expected the step to fail with an exec timeout, got %q", message)
+	}
+
+	if !hangModule.cancelled {
+		t.Error("expected the module to be cancelled when its exec_timeout elapsed")
+	}
+}
+
+func TestNilResultRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "nil_result_run.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	verify := newFlakyModule("verify", 0)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(newMockModule("empty", hclspec.NewSpec(hclspec.Object()), nil))
+	moduleRegistry.Register(verify)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err == nil || !strings.Contains(err.Error(), "no result returned from module") {
+		t.Fatalf("expected the step without a result to fail the run, got %v", err)
+	}
+
+	output := outputs[0]["empty"]["host1"]
+	if output.IsNull() || !output.GetAttr("failed").True() {
+		t.Error("expected the step without a result to fail")
+	}
+
+	if verify.runs != 0 {
+		t.Errorf("expected the verify module to be skipped on the failed host, ran %d times", verify.runs)
+	}
+}
+
+// asyncJobModule runs jobs that finish after they are polled a number of times, or never if polls is zero.
+type asyncJobModule struct {
+	*mockModule
+
+	polls int
+
+	mutex    sync.Mutex
+	started  int
+	polled   map[string]int
+	timeouts []time.Duration
+}
+
+func newAsyncJobModule(name string, polls int) *asyncJobModule {
+	return &asyncJobModule{
+		mockModule: newMockModule(name, hclspec.NewSpec(hclspec.Object()), nil),
+		polls:      polls,
+		polled:     map[string]int{},
+	}
+}
+
+func (m *asyncJobModule) StartAsync(
+	ctx context.Context,
+	config *module.RunConfig,
+	id string,
+	timeout time.Duration,
+) (*module.AsyncStatus, error) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.started++
+	m.timeouts = append(m.timeouts, timeout)
+
+	return &module.AsyncStatus{
+		ID:      id,
+		JobFile: filepath.Join(os.TempDir(), "forge-async", id+".json"),
+	}, nil
+}
+
+func (m *asyncJobModule) PollAsync(
+	ctx context.Context,
+	config *module.RunConfig,
+	jobFile string,
+) (*module.AsyncStatus, error) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.polled[jobFile]++
+	status := &module.AsyncStatus{
+		ID:      strings.TrimSuffix(filepath.Base(jobFile), ".json"),
+		JobFile: jobFile,
+	}
+
+	if m.polls > 0 && m.polled[jobFile] >= m.polls {
+		status.Finished = true
+		status.Result = result.NewChanged(cty.ObjectVal(map[string]cty.Value{
+			"polls": cty.NumberIntVal(int64(m.polled[jobFile])),
+		}))
+	}
+
+	return status, nil
+}
+
+func (m *asyncJobModule) totalPolls() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	total := 0
+	for _, polls := range m.polled {
+		total += polls
+	}
+
+	return total
+}
+
+func TestAsyncRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "async_run.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	jobModule := newAsyncJobModule("job", 3)
+	stuckModule := newAsyncJobModule("stuck", 0)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(jobModule)
+	moduleRegistry.Register(stuckModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	if jobModule.started != 2 {
+		t.Errorf("expected the job module to start 2 jobs, got %d", jobModule.started)
+	}
+
+	if !slices.Equal(jobModule.timeouts, []time.Duration{time.Minute, time.Minute}) {
+		t.Errorf("expected the jobs to be limited to the async duration, got %v", jobModule.timeouts)
+	}
+
+	if jobModule.totalPolls() != 3 {
+		t.Errorf("expected only the waiting job to be polled 3 times, got %d polls", jobModule.totalPolls())
+	}
+
+	wait := outputs[0]["wait"]["host1"]
+	if !wait.GetAttr("changed").True() {
+		t.Error("expected the waiting step to report the result of its job")
+	}
+
+	polls := wait.GetAttr("output").GetAttr("polls")
+	if !polls.Equals(cty.NumberIntVal(3)).True() {
+		t.Errorf("expected the waiting step to output the job output, got %s", polls.GoString())
+	}
+
+	fire := outputs[0]["fire"]["host1"].GetAttr("output")
+	if !fire.GetAttr("started").True() || fire.GetAttr("finished").True() {
+		t.Errorf("expected the fire and forget step to report a started job, got %s", fire.GoString())
+	}
+
+	if !strings.HasSuffix(fire.GetAttr("job_file").AsString(), fire.GetAttr("job_id").AsString()+".json") {
+		t.Errorf("expected the job file to be named after the job ID, got %s", fire.GoString())
+	}
+
+	expire := outputs[0]["expire"]["host1"]
+	if !expire.GetAttr("failed").True() {
+		t.Fatal("expected the step to fail when its job did not finish within the async duration")
+	}
+
+	message := expire.GetAttr("error").AsString()
+	if !strings.HasPrefix(message, workflow.ErrExecTimeout.Error()) {
+		t.Errorf("expected the step to fail with an exec timeout, got %q", message)
+	}
+}
+
+// delegatedModule records the transport and address of each run and outputs the address.
+type delegatedModule struct {
+	*mockModule
+
+	mutex     sync.Mutex
+	addresses map[transport.Transport][]string
+}
+
+func newDelegatedModule(name string) *delegatedModule {
+	return &delegatedModule{
+		mockModule: newMockModule(name, hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("address", hclspec.String),
+		)), nil),
+		addresses: map[transport.Transport][]string{},
+	}
+}
+
+func (m *delegatedModule) Run(ctx context.Context, config *module.RunConfig) *result.Result {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	address := config.Input["address"]
+	m.addresses[config.Transport] = append(m.addresses[config.Transport], address.AsString())
+
+	return result.NewChanged(cty.ObjectVal(map[string]cty.Value{
+		"address": address,
+	}))
+}
+
+func (m *delegatedModule) addressesOn(t transport.Transport) []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	addresses := slices.Clone(m.addresses[t])
+	slices.Sort(addresses)
+
+	return addresses
+}
+
+func TestDelegateRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "delegate_run.hcl")
+
+	web1 := createMockHostWithVars(t, "web1", map[string]cty.Value{"ip": cty.StringVal("10.0.1.10")})
+	web2 := createMockHostWithVars(t, "web2", map[string]cty.Value{"ip": cty.StringVal("10.0.1.11")})
+	lb := createMockHostWithVars(t, "lb", map[string]cty.Value{"ip": cty.StringVal("10.0.0.1")})
+
+	i := createMockInventory(web1, web2, lb)
+
+	deregisterModule := newDelegatedModule("deregister")
+	recordModule := newRecordingModule(
+		"record",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("address", hclspec.String),
+		)),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		web1,
+		web2,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(deregisterModule)
+	moduleRegistry.Register(recordModule)
+
+	outputs, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err == nil || !strings.Contains(err.Error(), `delegate_to host "db9" is not in the inventory`) {
+		t.Fatalf("expected the step delegated to a missing host to fail the run, got %v", err)
+	}
+
+	expected := []string{"10.0.1.10", "10.0.1.11"}
+	if got := deregisterModule.addressesOn(lb.Transport()); !slices.Equal(got, expected) {
+		t.Errorf("expected the delegated step to run on lb with the address of each target, got %v", got)
+	}
+
+	if got := deregisterModule.addressesOn(transport.LocalTransport); !slices.Equal(got, expected) {
+		t.Errorf("expected the step delegated to localhost to run on the controller, got %v", got)
+	}
+
+	for _, host := range []*inventory.Host{web1, web2} {
+		if len(deregisterModule.addressesOn(host.Transport())) != 0 {
+			t.Errorf("expected the delegated steps not to run on %q", host.Name())
+		}
+
+		if _, ok := outputs[0]["deregister"][host.Name()]; !ok {
+			t.Errorf("expected deregister output for %q", host.Name())
+		}
+
+		address := recordModule.input(t, host, "address")
+		if address.AsString() != host.Vars()["ip"].AsString() {
+			t.Errorf("expected the delegated output of %q to be stored under it, got %q", host.Name(), address.AsString())
+		}
+
+		missing := outputs[0]["missing"][host.Name()]
+		if !missing.GetAttr("failed").True() {
+			t.Errorf("expected the step delegated to a missing host to fail on %q", host.Name())
+		}
+	}
+
+	if _, ok := outputs[0]["deregister"]["lb"]; ok {
+		t.Error("expected no deregister output for the delegate host")
+	}
+}
+
+// hostInfoModule records the OS of the host info it receives on each run.
+type hostInfoModule struct {
+	*mockModule
+
+	mutex sync.Mutex
+	osIDs []string
+}
+
+func newHostInfoModule(name string) *hostInfoModule {
+	return &hostInfoModule{
+		mockModule: newMockModule(name, hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("os", hclspec.String),
+		)), nil),
+	}
+}
+
+func (m *hostInfoModule) Run(ctx context.Context, config *module.RunConfig) *result.Result {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.osIDs = append(m.osIDs, config.HostInfo.Os.Id)
+
+	return result.NewNotChanged(cty.ObjectVal(map[string]cty.Value{
+		"os": config.Input["os"],
+	}))
+}
+
+func TestDelegateInfoRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "delegate_info_run.hcl")
+
+	web1 := createMockHost("web1")
+	lb := createMockHost("lb")
+
+	i := createMockInventory(web1, lb)
+
+	cache := info.NewCache(t.TempDir(), time.Hour)
+	for host, osID := range map[*inventory.Host]string{web1: "debian", lb: "alpine"} {
+		cached := info.NewHostInfo()
+		cached.Os.Id = osID
+
+		err := cache.Store(host.Name(), host.Transport(), cached)
+		if err != nil {
+			t.Fatalf("failed to store host info: %v", err)
+		}
+	}
+
+	infoModule := newHostInfoModule("info")
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(infoModule)
+
+	w := parseWorkflowForRun(t, path, i, moduleRegistry)
+
+	wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+	if err != nil {
+		t.Fatalf("failed to create workflow context: %v", err)
+	}
+
+	outputs, err := w.Run(wc.WithInfoCache(cache))
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	if !slices.Equal(infoModule.osIDs, []string{"alpine"}) {
+		t.Errorf("expected the module to receive the host info of the delegate host, got %v", infoModule.osIDs)
+	}
+
+	if lb.Info().Os.Id != "alpine" {
+		t.Errorf("expected the host info of the delegate host to be loaded from the cache, got %q", lb.Info().Os.Id)
+	}
+
+	osID := outputs[0]["report"]["web1"].GetAttr("output").GetAttr("os")
+	if osID.AsString() != "debian" {
+		t.Errorf("expected the input to be evaluated with the host info of the target, got %q", osID.AsString())
+	}
+}
+
+func TestInfoCacheRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "info_cache_run.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	cache := info.NewCache(t.TempDir(), time.Hour)
+
+	cached := info.NewHostInfo()
+	cached.Os.Id = "debian"
+
+	err := cache.Store(host1.Name(), host1.Transport(), cached)
+	if err != nil {
+		t.Fatalf("failed to store host info: %v", err)
+	}
+
+	recordModule := newRecordingModule(
+		"record",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("os", hclspec.String),
+		)),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(recordModule)
+
+	w := parseWorkflowForRun(t, path, i, moduleRegistry)
+
+	wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+	if err != nil {
+		t.Fatalf("failed to create workflow context: %v", err)
+	}
+
+	_, err = w.Run(wc.WithInfoCache(cache))
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	if got := recordModule.input(t, host1, "os"); got.AsString() != "debian" {
+		t.Errorf("expected the host info to be loaded from the cache, got %q", got.AsString())
+	}
+
+	entry, ok := cache.Load(host1.Name(), host1.Transport())
+	if !ok || entry.HostInfo.Os.Id != "debian" {
+		t.Fatal("expected the cached host info to be loaded")
+	}
+
+	if _, ok := cache.WithRefresh(true).Load(host1.Name(), host1.Transport()); ok {
+		t.Error("expected refreshing the cache to ignore the cached host info")
+	}
+
+	if _, ok := info.NewCache(cache.Path(), 0).Load(host1.Name(), host1.Transport()); ok {
+		t.Error("expected a TTL of 0 to ignore the cached host info")
+	}
+
+	err = cache.Clear()
+	if err != nil {
+		t.Fatalf("failed to clear the cache: %v", err)
+	}
+
+	entry, err = cache.Entry(host1.Name(), host1.Transport())
+	if err != nil || entry != nil {
+		t.Errorf("expected the cleared cache to have no entry, got %v, %v", entry, err)
+	}
+}
+
+func TestSetVarsRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "set_vars_run.hcl")
+
+	host1 := createMockHostWithVars(t, "host1", map[string]cty.Value{
+		"port": cty.NumberIntVal(8080),
+		"role": cty.StringVal("db"),
+	})
+	host2 := createMockHostWithVars(t, "host2", map[string]cty.Value{
+		"port": cty.NumberIntVal(9090),
+		"role": cty.StringVal("db"),
+	})
+
+	i := createMockInventory(host1, host2)
+
+	recordModule := newRecordingModule(
+		"record",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("release", hclspec.String),
+			hclspec.RequiredField("role", hclspec.String),
+			hclspec.RequiredField("peer", hclspec.String),
+		)),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+		host2,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.RegisterBuiltinModules()
+	moduleRegistry.Register(recordModule)
+
+	_, err := parseAndRunWorkflow(t, path, i, moduleRegistry)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	tests := []struct {
+		host    *inventory.Host
+		release string
+	}{
+		{host: host1, release: "release-8080"},
+		{host: host2, release: "release-9090"},
+	}
+
+	for _, tt := range tests {
+		if got := recordModule.input(t, tt.host, "release").AsString(); got != tt.release {
+			t.Errorf("expected runtime var release on %q to be %q, got %q", tt.host.Name(), tt.release, got)
+		}
+
+		if got := recordModule.input(t, tt.host, "role").AsString(); got != "web" {
+			t.Errorf("expected runtime var role on %q to override the host var, got %q", tt.host.Name(), got)
+		}
+
+		if got := recordModule.input(t, tt.host, "peer").AsString(); got != "release-9090" {
+			t.Errorf("expected hostvars on %q to include runtime vars, got %q", tt.host.Name(), got)
+		}
+	}
+
+	if got := host1.Vars()["role"].AsString(); got != "db" {
+		t.Errorf("expected the host vars to be unchanged, got role %q", got)
+	}
+}
+
+func TestOutputsRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "outputs_run.hcl")
+
+	host1 := createMockHostWithVars(t, "host1", map[string]cty.Value{"primary": cty.BoolVal(true)})
+	host2 := createMockHostWithVars(t, "host2", map[string]cty.Value{"primary": cty.BoolVal(false)})
+
+	i := createMockInventory(host1, host2)
+
+	deployModule := newRecordingModule(
+		"deploy",
+		hclspec.NewSpec(hclspec.Object()),
+		result.NewChanged(cty.ObjectVal(map[string]cty.Value{
+			"version": cty.StringVal("1.2.3"),
+		})),
+		host1,
+		host2,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(deployModule)
+
+	w := parseWorkflowForRun(t, path, i, moduleRegistry)
+
+	if got := len(w.Outputs()); got != 3 {
+		t.Fatalf("expected 3 outputs, got %d", got)
+	}
+
+	if got := w.Outputs()[0].Description(); got != "Version deployed to the first host" {
+		t.Errorf("expected description of output %q, got %q", w.Outputs()[0].Name(), got)
+	}
+
+	wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+	if err != nil {
+		t.Fatalf("failed to create workflow context: %v", err)
+	}
+
+	wc.WithVariables(map[string]cty.Value{"environment": cty.StringVal("staging")})
+
+	stepOutputs, err := w.Run(wc)
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	outputs, diags := w.EvaluateOutputs(wc, stepOutputs)
+	if diags.HasErrors() {
+		t.Fatalf("failed to evaluate outputs: %v", diags)
+	}
+
+	expected := map[string]cty.Value{
+		"version": cty.StringVal("1.2.3"),
+		"endpoints": cty.ObjectVal(map[string]cty.Value{
+			"host1": cty.StringVal("https://host1/staging"),
+			"host2": cty.StringVal("https://host2/staging"),
+		}),
+		"token": cty.StringVal("token-1.2.3"),
+	}
+
+	for name, want := range expected {
+		got, exists := outputs[name]
+		if !exists {
+			t.Errorf("expected output %q, got none", name)
+			continue
+		}
+
+		if !got.RawEquals(want) {
+			t.Errorf("expected output %q to be %#v, got %#v", name, want, got)
+		}
+	}
+
+	path = filepath.Join(t.TempDir(), "outputs.json")
+
+	r := w.NewRunResult(stepOutputs, outputs, nil, nil)
+
+	err = r.WriteFile(path)
+	if err != nil {
+		t.Fatalf("failed to write run result: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read run result: %v", err)
+	}
+
+	var got workflow.RunResult
+	err = json.Unmarshal(content, &got)
+	if err != nil {
+		t.Fatalf("failed to parse run result: %v", err)
+	}
+
+	if !got.Succeeded || got.Error != "" {
+		t.Errorf("expected run result to succeed, got succeeded %t with error %q", got.Succeeded, got.Error)
+	}
+
+	if got := string(got.Outputs["version"].Value); got != `"1.2.3"` {
+		t.Errorf("expected output version to be %q, got %q", `"1.2.3"`, got)
+	}
+
+	if !got.Outputs["token"].Sensitive || got.Outputs["version"].Sensitive {
+		t.Errorf("expected only output token to be sensitive")
+	}
+
+	if len(got.Processes) != 2 {
+		t.Fatalf("expected 2 processes, got %d", len(got.Processes))
+	}
+
+	if got.Processes[0].Name != "Deploy" || got.Processes[1].Name != "Verify" {
+		t.Errorf("expected processes Deploy and Verify, got %q and %q", got.Processes[0].Name, got.Processes[1].Name)
+	}
+
+	statusTests := []struct {
+		process int
+		step    string
+		host    string
+		want    workflow.StepStatus
+	}{
+		{process: 0, step: "deploy", host: "host1", want: workflow.StepStatus{Changed: true}},
+		{process: 0, step: "deploy", host: "host2", want: workflow.StepStatus{Changed: true}},
+		{process: 1, step: "verify", host: "host1", want: workflow.StepStatus{Changed: true}},
+		{process: 1, step: "verify", host: "host2", want: workflow.StepStatus{Skipped: true}},
+	}
+
+	for _, tt := range statusTests {
+		status := got.Processes[tt.process].Steps[tt.step][tt.host]
+		if status == nil {
+			t.Errorf("expected status of step %q on %q, got none", tt.step, tt.host)
+			continue
+		}
+
+		if status.Failed != tt.want.Failed || status.Skipped != tt.want.Skipped || status.Changed != tt.want.Changed {
+			t.Errorf("expected status of step %q on %q to be %+v, got %+v", tt.step, tt.host, tt.want, *status)
+		}
+	}
+
+	failedOutputs := []map[string]map[string]cty.Value{
+		{
+			"deploy": {
+				"host1": cty.ObjectVal(map[string]cty.Value{
+					"failed":  cty.True,
+					"skipped": cty.False,
+					"changed": cty.False,
+					"error":   cty.StringVal("token token-1.2.3 was rejected"),
+				}),
+			},
+		},
+	}
+
+	outputDiags := hcl.Diagnostics{
+		&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid output",
+			Detail:   "The output cannot use token-1.2.3.",
+		},
+	}
+
+	unknownOutputs := maps.Clone(outputs)
+	unknownOutputs["version"] = cty.UnknownVal(cty.String)
+
+	r = w.NewRunResult(failedOutputs, unknownOutputs, outputDiags, errors.New("failed to deploy with token-1.2.3"))
+
+	if r.Succeeded || r.Error != "failed to deploy with <redacted>" {
+		t.Errorf("expected the run error to be redacted, got succeeded %t with error %q", r.Succeeded, r.Error)
+	}
+
+	if len(r.OutputErrors) != 2 || strings.Contains(r.OutputErrors[0], "token-1.2.3") {
+		t.Errorf("expected a redacted output error and a serialization error, got %q", r.OutputErrors)
+	}
+
+	if _, exists := r.Outputs["version"]; exists {
+		t.Error("expected the unserializable output to be left out of the run result")
+	}
+
+	if _, exists := r.Outputs["token"]; !exists {
+		t.Error("expected the other outputs to be kept in the run result")
+	}
+
+	status := r.Processes[0].Steps["deploy"]["host1"]
+	if !slices.Equal(status.Errors, []string{"token <redacted> was rejected"}) {
+		t.Errorf("expected the step error to be redacted, got %q", status.Errors)
+	}
+}
+
+func TestProcessConditionRun(t *testing.T) {
+
+	path := filepath.Join("corpus", "run", "process_condition_run.hcl")
+
+	host1 := createMockHostWithVars(t, "host1", map[string]cty.Value{"role": cty.StringVal("web")})
+	host2 := createMockHostWithVars(t, "host2", map[string]cty.Value{"role": cty.StringVal("web")})
+	host3 := createMockHostWithVars(t, "host3", map[string]cty.Value{"role": cty.StringVal("db")})
+
+	i := createMockInventory(host1, host2, host3)
+
+	// Only the hosts that meet the first condition are cached, as the mock transport cannot discover host info.
+	cache := info.NewCache(t.TempDir(), time.Hour)
+	for host, os := range map[*inventory.Host]string{host1: "debian", host2: "ubuntu"} {
+		cached := info.NewHostInfo()
+		cached.Os.Id = os
+
+		err := cache.Store(host.Name(), host.Transport(), cached)
+		if err != nil {
+			t.Fatalf("failed to store host info: %v", err)
+		}
+	}
+
+	spec := hclspec.NewSpec(hclspec.Object(
+		hclspec.RequiredField("os", hclspec.String),
+	))
+
+	recordModule := newRecordingModule("record", spec, result.NewNotChanged(cty.EmptyObjectVal), host1, host2, host3)
+	debianModule := newRecordingModule("debian", spec, result.NewNotChanged(cty.EmptyObjectVal), host1, host2)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(recordModule)
+	moduleRegistry.Register(debianModule)
+
+	w := parseWorkflowForRun(t, path, i, moduleRegistry)
+
+	if w.Processes()[0].Condition() == nil {
+		t.Fatal("expected the process condition to be parsed")
+	}
+
+	wc, err := workflow.NewWorkflowContext(ui.MockUI, i, false)
+	if err != nil {
+		t.Fatalf("failed to create workflow context: %v", err)
+	}
+
+	outputs, err := w.Run(wc.WithInfoCache(cache))
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	tests := []struct {
+		module *recordingModule
+		host   *inventory.Host
+		ran    bool
+	}{
+		{module: recordModule, host: host1, ran: true},
+		{module: recordModule, host: host2, ran: true},
+		{module: recordModule, host: host3, ran: false},
+		{module: debianModule, host: host1, ran: true},
+		{module: debianModule, host: host2, ran: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.module.ranOn(tt.host); got != tt.ran {
+			t.Errorf("expected module %q to have run on %q to be %t, got %t", tt.module.name, tt.host.Name(), tt.ran, got)
+		}
+	}
+
+	if _, exists := outputs[0]["report"][host3.Name()]; exists {
+		t.Error("expected no step output for a host that does not meet the process condition")
+	}
+
+	if got := recordModule.input(t, host2, "os").AsString(); got != "ubuntu" {
+		t.Errorf("expected the host info of a host that meets the condition to be discovered, got %q", got)
+	}
+}
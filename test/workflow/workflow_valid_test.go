@@ -5,14 +5,17 @@ package test
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/trippsoft/forge/pkg/inventory"
 	"github.com/trippsoft/forge/pkg/module"
 	"github.com/trippsoft/forge/pkg/workflow"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestBasicProcess(t *testing.T) {
@@ -608,3 +611,534 @@ func TestMissingTarget(t *testing.T) {
 
 	expectedDiags.verify(t, diags)
 }
+
+func TestProcedureProcess(t *testing.T) {
+
+	path := filepath.Join("corpus", "valid", "procedure_process.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+
+	i := createMockInventory(host1, host2)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+	packageModule := createMockModule("package")
+
+	moduleRegistry.Register(shellModule)
+	moduleRegistry.Register(packageModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workflow file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := &expected{
+		processes: []*expectedProcess{
+			{
+				name: "Procedure Process",
+				steps: []*expectedStep{
+					{
+						common: &expectedCommon{
+							id:      "prepare",
+							name:    "Prepare Host",
+							targets: []*inventory.Host{host1, host2},
+						},
+						module: shellModule,
+					},
+					{
+						procedure: &expectedProcedure{
+							common: &expectedCommon{
+								id:      "install",
+								name:    "Install Package",
+								targets: []*inventory.Host{host1},
+								input: map[string]struct{}{
+									"package": {},
+								},
+							},
+							inputs: map[string]bool{
+								"package": true,
+								"state":   false,
+							},
+							outputs: []string{"version"},
+							steps: []*expectedStep{
+								{
+									common: &expectedCommon{
+										id:      "install",
+										name:    "Install Package",
+										targets: []*inventory.Host{host1},
+										input: map[string]struct{}{
+											"name":  {},
+											"state": {},
+										},
+									},
+									module: packageModule,
+								},
+								{
+									common: &expectedCommon{
+										id:        "verify",
+										name:      "Verify Package",
+										targets:   []*inventory.Host{host1},
+										condition: true,
+									},
+									module: shellModule,
+								},
+							},
+						},
+					},
+					{
+						common: &expectedCommon{
+							id:        "report",
+							name:      "Report Version",
+							targets:   []*inventory.Host{host1, host2},
+							condition: true,
+						},
+						module: shellModule,
+					},
+				},
+			},
+		},
+	}
+
+	expected.verify(t, w)
+}
+
+func TestRunOnceProcess(t *testing.T) {
+
+	path := filepath.Join("corpus", "valid", "run_once_process.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+	host3 := createMockHost("host3")
+
+	i := createMockInventory(host1, host2, host3)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workflow file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := &expected{
+		processes: []*expectedProcess{
+			{
+				name: "Run Once Process",
+				steps: []*expectedStep{
+					{
+						common: &expectedCommon{
+							id:      "migrate",
+							name:    "Migrate Database",
+							targets: []*inventory.Host{host1, host2, host3},
+						},
+						module:  shellModule,
+						runOnce: true,
+					},
+					{
+						common: &expectedCommon{
+							id:      "token",
+							name:    "Fetch Release Token",
+							targets: []*inventory.Host{host1, host2, host3},
+						},
+						module:      shellModule,
+						runOnce:     true,
+						runOnceHost: true,
+					},
+				},
+			},
+		},
+	}
+
+	expected.verify(t, w)
+}
+
+func TestSerialProcess(t *testing.T) {
+
+	path := filepath.Join("corpus", "valid", "serial_process.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+	host3 := createMockHost("host3")
+	host4 := createMockHost("host4")
+
+	i := createMockInventory(host1, host2, host3, host4)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workflow file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+
+	maxFailPercentage := 25.0
+
+	expected := &expected{
+		processes: []*expectedProcess{
+			{
+				name: "Serial Process",
+				serial: []*workflow.BatchSize{
+					workflow.NewBatchSize(1),
+					workflow.NewBatchSizePercentage(50),
+				},
+				maxFailPercentage: &maxFailPercentage,
+				steps: []*expectedStep{
+					{
+						common: &expectedCommon{
+							id:      "restart",
+							name:    "Restart Service",
+							targets: []*inventory.Host{host1, host2, host3, host4},
+						},
+						module: shellModule,
+					},
+				},
+			},
+		},
+	}
+
+	expected.verify(t, w)
+}
+
+func TestHandlerProcess(t *testing.T) {
+
+	path := filepath.Join("corpus", "valid", "handler_process.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+
+	i := createMockInventory(host1, host2)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workflow file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := &expected{
+		processes: []*expectedProcess{
+			{
+				name: "Handler Process",
+				steps: []*expectedStep{
+					{
+						common: &expectedCommon{
+							id:      "config",
+							name:    "Update Config",
+							targets: []*inventory.Host{host1, host2},
+						},
+						module: shellModule,
+						notify: []string{"restart"},
+					},
+					{
+						flushHandlers: true,
+					},
+					{
+						common: &expectedCommon{
+							id:      "certs",
+							name:    "Update Certificates",
+							targets: []*inventory.Host{host1, host2},
+						},
+						module: shellModule,
+						notify: []string{"reload", "restart"},
+					},
+				},
+				handlers: []*expectedStep{
+					{
+						common: &expectedCommon{
+							id:      "restart",
+							name:    "Restart Service",
+							targets: []*inventory.Host{host1, host2},
+						},
+						module: shellModule,
+					},
+					{
+						common: &expectedCommon{
+							id:      "reload",
+							name:    "Reload Service",
+							targets: []*inventory.Host{host1},
+						},
+						module: shellModule,
+					},
+				},
+			},
+		},
+	}
+
+	expected.verify(t, w)
+}
+
+func TestRetryProcess(t *testing.T) {
+
+	path := filepath.Join("corpus", "valid", "retry_process.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workflow file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := &expected{
+		processes: []*expectedProcess{
+			{
+				name: "Retry Process",
+				steps: []*expectedStep{
+					{
+						common: &expectedCommon{
+							id:      "wait",
+							name:    "Wait for Service",
+							targets: []*inventory.Host{host1},
+						},
+						module: shellModule,
+						retry: &expectedRetry{
+							delay:   true,
+							backoff: true,
+						},
+					},
+					{
+						common: &expectedCommon{
+							id:      "poll",
+							name:    "Poll Status",
+							targets: []*inventory.Host{host1},
+						},
+						module: shellModule,
+						retry: &expectedRetry{
+							until: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected.verify(t, w)
+}
+
+func TestBlockProcess(t *testing.T) {
+
+	path := filepath.Join("corpus", "valid", "block_process.hcl")
+
+	host1 := createMockHost("host1")
+	host2 := createMockHost("host2")
+
+	i := createMockInventory(host1, host2)
+
+	moduleRegistry := module.NewRegistry()
+
+	shellModule := createMockModule("shell")
+
+	moduleRegistry.Register(shellModule)
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read workflow file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := &expected{
+		processes: []*expectedProcess{
+			{
+				name: "Block Process",
+				steps: []*expectedStep{
+					{
+						block: &expectedBlock{
+							common: &expectedCommon{
+								id:        "deploy",
+								name:      "Deploy Application",
+								targets:   []*inventory.Host{host1, host2},
+								condition: true,
+							},
+							steps: []*expectedStep{
+								{
+									common: &expectedCommon{
+										id:      "disable",
+										name:    "Disable in Load Balancer",
+										targets: []*inventory.Host{host1, host2},
+									},
+									module: shellModule,
+								},
+								{
+									common: &expectedCommon{
+										id:      "install",
+										name:    "Install Release",
+										targets: []*inventory.Host{host1, host2},
+									},
+									module: shellModule,
+								},
+							},
+							rescue: []*expectedStep{
+								{
+									common: &expectedCommon{
+										id:      "rollback",
+										name:    "Roll Back Release",
+										targets: []*inventory.Host{host1, host2},
+									},
+									module: shellModule,
+								},
+							},
+							always: []*expectedStep{
+								{
+									common: &expectedCommon{
+										id:      "enable",
+										name:    "Enable in Load Balancer",
+										targets: []*inventory.Host{host1},
+									},
+									module: shellModule,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected.verify(t, w)
+}
+
+func TestTargetSetsProcess(t *testing.T) {
+
+	path := filepath.Join("corpus", "valid", "target_sets_process.hcl")
+
+	web1 := createMockHostWithVars(t, "web1", map[string]cty.Value{})
+	web2 := createMockHostWithVars(t, "web2", map[string]cty.Value{})
+	db1 := createMockHostWithVars(t, "db1", map[string]cty.Value{"role": cty.StringVal("db")})
+	db2 := createMockHostWithVars(t, "db2", map[string]cty.Value{"role": cty.StringVal("db")})
+
+	groups := map[string][]*inventory.Host{
+		"webservers": {web1, web2},
+		"production": {web1, db1},
+	}
+
+	mockInventory := createMockInventory(web1, web2, db1, db2)
+
+	targets := mockInventory.Targets()
+	maps.Copy(targets, groups)
+
+	i := inventory.NewInventory(mockInventory.Hosts(), groups, targets)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(createMockModule("shell"))
+
+	parser := workflow.NewParser(i, moduleRegistry)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	w, diags := parser.ParseWorkflowFile(path, content)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse workflow file: %v", diags)
+	}
+
+	expected := map[string][]string{
+		"intersect":  {"web1"},
+		"difference": {"db1"},
+		"union":      {"db1", "web2"},
+		"predicate":  {"db1", "db2"},
+		"pattern":    {"db1", "web1", "web2"},
+	}
+
+	for _, step := range w.Processes()[0].Steps() {
+		singleStep, ok := step.(*workflow.SingleStep)
+		if !ok {
+			t.Fatalf("expected step %q to be a single step", step.ID())
+		}
+
+		names := []string{}
+		for _, host := range singleStep.Common().Targets() {
+			names = append(names, host.Name())
+		}
+
+		slices.Sort(names)
+		if !slices.Equal(names, expected[step.ID()]) {
+			t.Errorf("expected step %q to target %v, got %v", step.ID(), expected[step.ID()], names)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) Forge
+// SPDX-License-Identifier: MPL-2.0
+
+package test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/trippsoft/forge/pkg/hclspec"
+	"github.com/trippsoft/forge/pkg/inventory"
+	"github.com/trippsoft/forge/pkg/module"
+	"github.com/trippsoft/forge/pkg/result"
+	"github.com/trippsoft/forge/pkg/secret"
+	"github.com/trippsoft/forge/pkg/ui"
+	"github.com/trippsoft/forge/pkg/workflow"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// variableRun holds a parsed variable_run.hcl workflow and what it runs against.
+type variableRun struct {
+	workflow     *workflow.Workflow
+	inventory    *inventory.Inventory
+	host         *inventory.Host
+	recordModule *recordingModule
+}
+
+func parseVariableWorkflow(t *testing.T) *variableRun {
+
+	t.Helper()
+
+	path := filepath.Join("corpus", "run", "variable_run.hcl")
+
+	host1 := createMockHost("host1")
+
+	i := createMockInventory(host1)
+
+	recordModule := newRecordingModule(
+		"record",
+		hclspec.NewSpec(hclspec.Object(
+			hclspec.RequiredField("version", hclspec.Raw),
+			hclspec.RequiredField("replicas", hclspec.Raw),
+			hclspec.RequiredField("features", hclspec.Raw),
+			hclspec.RequiredField("token", hclspec.Raw),
+		)),
+		result.NewNotChanged(cty.EmptyObjectVal),
+		host1,
+	)
+
+	moduleRegistry := module.NewRegistry()
+	moduleRegistry.Register(recordModule)
+
+	return &variableRun{
+		workflow:     parseWorkflowForRun(t, path, i, moduleRegistry),
+		inventory:    i,
+		host:         host1,
+		recordModule: recordModule,
+	}
+}
+
+func TestVariableDeclarations(t *testing.T) {
+
+	run := parseVariableWorkflow(t)
+
+	variables := run.workflow.Variables()
+	if len(variables) != 4 {
+		t.Fatalf("expected 4 variables, got %d", len(variables))
+	}
+
+	tests := []struct {
+		name        string
+		varType     cty.Type
+		required    bool
+		sensitive   bool
+		validations int
+	}{
+		{name: "version", varType: cty.String, required: true, validations: 1},
+		{name: "replicas", varType: cty.Number},
+		{name: "features", varType: cty.List(cty.String)},
+		{name: "api_token", varType: cty.String, sensitive: true},
+	}
+
+	for i, tt := range tests {
+		v := variables[i]
+		if v.Name() != tt.name {
+			t.Errorf("expected variable %d to be %q, got %q", i, tt.name, v.Name())
+		}
+
+		if !v.Type().Equals(tt.varType) {
+			t.Errorf("expected variable %q type %s, got %s", tt.name, tt.varType.FriendlyName(), v.Type().FriendlyName())
+		}
+
+		if v.Required() != tt.required {
+			t.Errorf("expected variable %q required to be %t", tt.name, tt.required)
+		}
+
+		if v.Sensitive() != tt.sensitive {
+			t.Errorf("expected variable %q sensitive to be %t", tt.name, tt.sensitive)
+		}
+
+		if len(v.Validations()) != tt.validations {
+			t.Errorf("expected variable %q to have %d validations, got %d", tt.name, tt.validations, len(v.Validations()))
+		}
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+
+	run := parseVariableWorkflow(t)
+
+	environ := []string{
+		"FORGE_VAR_version=v1.0.0",
+		"FORGE_VAR_api_token=secret-token",
+		"FORGE_VAR_undeclared=ignored",
+		"PATH=/usr/bin",
+	}
+
+	varFiles := []string{
+		filepath.Join("corpus", "variables", "release.hcl"),
+		filepath.Join("corpus", "variables", "release.json"),
+	}
+
+	rawValues := []string{
+		"version=v2.0.0",
+	}
+
+	values, diags := run.workflow.ResolveVariables(environ, varFiles, rawValues)
+	if diags.HasErrors() {
+		t.Fatalf("failed to resolve variables: %v", diags)
+	}
+
+	expectedDiags := &expectedDiagnostics{
+		{
+			severity: hcl.DiagWarning,
+			summary:  "Value for undeclared variable",
+			detail: "The file \"" + varFiles[1] + "\" assigns a value to \"unknown\", " +
+				"which is not declared by the workflow.",
+		},
+	}
+
+	expectedDiags.verify(t, diags)
+
+	if !slices.Contains(secret.SecretFilter.Secrets(), "secret-token") {
+		t.Error("expected sensitive variable value to be registered with the secret filter")
+	}
+
+	wc, err := workflow.NewWorkflowContext(ui.MockUI, run.inventory, false)
+	if err != nil {
+		t.Fatalf("failed to create workflow context: %v", err)
+	}
+
+	_, err = run.workflow.Run(wc.WithVariables(values))
+	if err != nil {
+		t.Fatalf("failed to run workflow: %v", err)
+	}
+
+	expected := map[string]cty.Value{
+		"version":  cty.StringVal("v2.0.0"),
+		"replicas": cty.NumberIntVal(5),
+		"features": cty.ListVal([]cty.Value{cty.StringVal("canary")}),
+		"token":    cty.StringVal("secret-token"),
+	}
+
+	for name, value := range expected {
+		actual := run.recordModule.input(t, run.host, name)
+		if !actual.RawEquals(value) {
+			t.Errorf("expected input %q to be %s, got %s", name, value.GoString(), actual.GoString())
+		}
+	}
+}
+
+func TestResolveVariablesInvalid(t *testing.T) {
+
+	run := parseVariableWorkflow(t)
+
+	tests := []struct {
+		name      string
+		rawValues []string
+		expected  expectedDiagnostics
+	}{
+		{
+			name:      "missing required",
+			rawValues: []string{},
+			expected: expectedDiagnostics{
+				{
+					severity: hcl.DiagError,
+					summary:  "Missing variable value",
+					detail:   "The variable \"version\" has no default value, so a value must be assigned.",
+				},
+			},
+		},
+		{
+			name:      "failed validation",
+			rawValues: []string{"version=1.0.0"},
+			expected: expectedDiagnostics{
+				{
+					severity: hcl.DiagError,
+					summary:  "Invalid value for variable",
+					detail:   "The version must start with \"v\".",
+				},
+			},
+		},
+		{
+			name:      "undeclared",
+			rawValues: []string{"version=v1.0.0", "region=us-east-1"},
+			expected: expectedDiagnostics{
+				{
+					severity: hcl.DiagError,
+					summary:  "Undeclared variable",
+					detail:   "A value was assigned to \"region\", which is not declared by the workflow.",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, diags := run.workflow.ResolveVariables(nil, nil, tt.rawValues)
+			if !diags.HasErrors() {
+				t.Fatal("expected error, got none")
+			}
+
+			if values != nil {
+				t.Errorf("expected nil values, got %v", values)
+			}
+
+			tt.expected.verify(t, diags)
+		})
+	}
+}